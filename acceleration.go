@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// accelerationCandles is how many recent candles, the current one
+	// included, the acceleration is measured over.
+	accelerationCandles = 4
+
+	defaultAcceleration = 0.5
+)
+
+// volumeAcceleration returns how fast the candle-over-candle ratio of field
+// is rising across klines, oldest first: the average change in that ratio
+// per candle. Candles following a zero value are left out.
+func volumeAcceleration(klines []BinanceKline, field int) (float64, error) {
+	var ratios []float64
+	for i := 1; i < len(klines); i++ {
+		prev, err := klineFloat(klines[i-1], field)
+		if err != nil {
+			return 0, err
+		}
+		curr, err := klineFloat(klines[i], field)
+		if err != nil {
+			return 0, err
+		}
+		if prev > 0 {
+			ratios = append(ratios, curr/prev)
+		}
+	}
+	if len(ratios) < 2 {
+		return 0, fmt.Errorf("insufficient kline data")
+	}
+	return (ratios[len(ratios)-1] - ratios[0]) / float64(len(ratios)-1), nil
+}
+
+// getVolumeAcceleration measures the acceleration of the symbol's metric
+// over the last accelerationCandles candles, leaving out the open one if
+// closed is set.
+func getVolumeAcceleration(symbol, metric string, closed bool) (float64, error) {
+	klines, err := getBinanceKlines(symbol, candlesNeeded(accelerationCandles, closed))
+	if err != nil {
+		return 0, err
+	}
+	if closed && len(klines) > 0 {
+		klines = klines[:len(klines)-1]
+	}
+	return volumeAcceleration(klines, metricField(metric))
+}
+
+// accelerating reports whether data's acceleration reaches the chat's
+// acceleration threshold.
+func accelerating(cfg ChatConfig, data *VolumeData) bool {
+	return cfg.Acceleration > 0 && data.Acceleration >= cfg.Acceleration
+}
+
+// formatAcceleration renders an acceleration as a signed ratio change per
+// candle, e.g. "+0.85x per candle".
+func formatAcceleration(a float64, precision int) string {
+	sign := ""
+	if a > 0 {
+		sign = "+"
+	}
+	return sign + formatRatio(a, precision) + " per candle"
+}
+
+func handleAccelerationCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+	usage := "Usage: /acceleration on [threshold], /acceleration <threshold>, or /acceleration off"
+	if len(fields) == 0 || len(fields) > 2 {
+		return usage
+	}
+
+	threshold := defaultAcceleration
+	switch {
+	case fields[0] == "off" && len(fields) == 1:
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Acceleration = 0
+		})
+		return "Acceleration alerts disabled."
+	case fields[0] == "on":
+		if len(fields) == 1 {
+			break
+		}
+		fields = fields[1:]
+		fallthrough
+	case len(fields) == 1:
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || value <= 0 {
+			return "Acceleration threshold must be a positive number, e.g. 0.5"
+		}
+		threshold = value
+	default:
+		return usage
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Acceleration = threshold
+	})
+	return fmt.Sprintf("Alerting when the volume ratio rises by %gx or more per candle over the last %d candles, even below the threshold.", threshold, accelerationCandles)
+}
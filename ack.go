@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackAck    = "ack"
+	callbackSnooze = "snooze"
+
+	snoozeDuration = time.Hour
+)
+
+var (
+	snoozedUntil   = make(map[alertKey]time.Time)
+	snoozedUntilMu sync.Mutex
+)
+
+// alertKeyboard returns the Ack and Snooze buttons attached to an alert.
+func alertKeyboard(symbol string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Ack", callbackAck+":"+symbol),
+		tgbotapi.NewInlineKeyboardButtonData("Snooze 1h", callbackSnooze+":"+symbol),
+	))
+}
+
+// isSnoozed reports whether the chat snoozed symbol and the snooze has not
+// expired.
+func isSnoozed(chatID int64, symbol string, now time.Time) bool {
+	snoozedUntilMu.Lock()
+	defer snoozedUntilMu.Unlock()
+	return now.Before(snoozedUntil[alertKey{chatID, symbol}])
+}
+
+// acknowledgeAlert marks the chat's last alert for symbol as seen, which
+// stops it escalating until the symbol alerts anew.
+func acknowledgeAlert(chatID int64, symbol string) {
+	alertRecordsMu.Lock()
+	defer alertRecordsMu.Unlock()
+
+	key := alertKey{chatID, symbol}
+	if record, ok := alertRecords[key]; ok {
+		record.Acked = true
+		alertRecords[key] = record
+	}
+}
+
+func snoozeSymbol(chatID int64, symbol string, until time.Time) {
+	snoozedUntilMu.Lock()
+	snoozedUntil[alertKey{chatID, symbol}] = until
+	snoozedUntilMu.Unlock()
+}
+
+// handleAlertCallback handles a press of an alert's Ack or Snooze button:
+// it records the action, answers the query and replaces the buttons with a
+// note of what was done.
+func handleAlertCallback(b *tgbotapi.BotAPI, chatID int64, query *tgbotapi.CallbackQuery) {
+	action, symbol, ok := strings.Cut(query.Data, ":")
+	if !ok || symbol == "" {
+		return
+	}
+
+	var note string
+	switch action {
+	case callbackAck:
+		acknowledgeAlert(chatID, symbol)
+		note = fmt.Sprintf("✅ Acknowledged by %s", query.From.FirstName)
+	case callbackSnooze:
+		until := time.Now().Add(snoozeDuration)
+		snoozeSymbol(chatID, symbol, until)
+		note = fmt.Sprintf("💤 %s snoozed until %s by %s", symbol, until.Format("15:04"), query.From.FirstName)
+	default:
+		return
+	}
+
+	if _, err := b.Request(tgbotapi.NewCallback(query.ID, note)); err != nil {
+		log.Printf("Error answering callback: %v", err)
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, query.Message.Text+"\n\n"+note)
+	if _, err := request(b, edit); err != nil {
+		log.Printf("Error updating alert message: %v", err)
+	}
+}
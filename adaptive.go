@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// adaptiveCandles is how many recent candle returns the volatility is
+	// measured over.
+	adaptiveCandles = 48
+	// adaptiveVolatilityTTL is how long a symbol's volatility is reused.
+	adaptiveVolatilityTTL = time.Hour
+	// adaptiveReferenceVolatility is the per-candle volatility at which
+	// the chat's threshold applies unchanged, about typical for a large
+	// coin on 1h candles.
+	adaptiveReferenceVolatility = 0.01
+	// The adapted threshold stays within these multiples of the chat's.
+	minAdaptiveFactor = 0.5
+	maxAdaptiveFactor = 2.0
+)
+
+type volatilityEntry struct {
+	Value     float64
+	UpdatedAt time.Time
+}
+
+var (
+	volatilities   = make(map[string]volatilityEntry)
+	volatilitiesMu sync.Mutex
+)
+
+// returnVolatility is the standard deviation of the close-to-close log
+// returns of klines, oldest first.
+func returnVolatility(klines []BinanceKline) (float64, error) {
+	var returns []float64
+	for i := 1; i < len(klines); i++ {
+		prev, err := klineFloat(klines[i-1], 4)
+		if err != nil {
+			return 0, fmt.Errorf("invalid close: %v", err)
+		}
+		curr, err := klineFloat(klines[i], 4)
+		if err != nil {
+			return 0, fmt.Errorf("invalid close: %v", err)
+		}
+		if prev <= 0 || curr <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(curr/prev))
+	}
+	if len(returns) < 2 {
+		return 0, fmt.Errorf("need at least 2 returns, got %d", len(returns))
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(len(returns)-1)), nil
+}
+
+// adaptThreshold scales threshold inversely with volatility relative to
+// adaptiveReferenceVolatility: calm symbols need bigger spikes, volatile
+// ones alert sooner. The factor is clamped, and the result never drops to
+// 1x or below.
+func adaptThreshold(threshold, volatility float64) float64 {
+	factor := maxAdaptiveFactor
+	if volatility > 0 {
+		factor = max(minAdaptiveFactor, min(maxAdaptiveFactor, adaptiveReferenceVolatility/volatility))
+	}
+	return max(threshold*factor, 1.01)
+}
+
+// getVolatility returns the symbol's recent return volatility, cached for
+// adaptiveVolatilityTTL.
+func getVolatility(symbol string, now time.Time) (float64, error) {
+	volatilitiesMu.Lock()
+	entry, ok := volatilities[symbol]
+	volatilitiesMu.Unlock()
+	if ok && now.Sub(entry.UpdatedAt) < adaptiveVolatilityTTL {
+		return entry.Value, nil
+	}
+
+	klines, err := getBinanceKlines(symbol, adaptiveCandles+1)
+	if err != nil {
+		return 0, err
+	}
+	volatility, err := returnVolatility(klines)
+	if err != nil {
+		return 0, err
+	}
+
+	volatilitiesMu.Lock()
+	volatilities[symbol] = volatilityEntry{Value: volatility, UpdatedAt: now}
+	volatilitiesMu.Unlock()
+	return volatility, nil
+}
+
+// effectiveThreshold returns the ratio symbol must exceed in the main scan,
+// and the volatility it was adapted to, zero when the chat doesn't use
+// /adaptive. Symbols whose ratio can't reach even the lowest adapted
+// threshold skip the volatility lookup.
+func effectiveThreshold(cfg ChatConfig, symbol string, ratio float64) (threshold, volatility float64) {
+	threshold = symbolThreshold(cfg, symbol)
+	if !cfg.Adaptive || ratio <= adaptThreshold(threshold, math.Inf(1)) {
+		return threshold, 0
+	}
+
+	volatility, err := getVolatility(symbol, time.Now())
+	if err != nil {
+		log.Printf("Error getting volatility of %s, using the fixed threshold: %v\n", symbol, err)
+		return threshold, 0
+	}
+	return adaptThreshold(threshold, volatility), volatility
+}
+
+func handleAdaptiveCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Adaptive = true
+		})
+		return fmt.Sprintf("Thresholds now adapt to each symbol's volatility over the last %d candles: between %gx and %gx your %gx, higher for calm symbols and lower for volatile ones.",
+			adaptiveCandles, minAdaptiveFactor, maxAdaptiveFactor, cfg.Threshold)
+	case "off":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Adaptive = false
+		})
+		return fmt.Sprintf("Every symbol uses the %gx threshold again.", cfg.Threshold)
+	default:
+		return "Usage: /adaptive on|off"
+	}
+}
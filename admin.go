@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func isAdmin(chatID int64) bool {
+	return currentSettings().AdminChatID != 0 && chatID == currentSettings().AdminChatID
+}
+
+func handleChatInfoCommand(chatID int64) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+
+	configs := allChatConfigs()
+	statuses := make(map[int64]bool)
+	monitoringStatus.Range(func(key, value interface{}) bool {
+		statuses[key.(int64)] = value.(bool)
+		return true
+	})
+
+	chatIDs := make([]int64, 0, len(statuses))
+	for id := range statuses {
+		chatIDs = append(chatIDs, id)
+	}
+	for id := range configs {
+		if _, ok := statuses[id]; !ok {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+	if len(chatIDs) == 0 {
+		return "No chats have used the bot yet."
+	}
+	sort.Slice(chatIDs, func(i, j int) bool { return chatIDs[i] < chatIDs[j] })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d chats:\n", len(chatIDs))
+	for _, id := range chatIDs {
+		status := "stopped"
+		if statuses[id] {
+			status = "running"
+		}
+
+		cfg, ok := configs[id]
+		if !ok {
+			cfg = defaultChatConfig()
+		}
+
+		lastAlert := "never"
+		if t, ok := lastAlertTime(id); ok {
+			lastAlert = t.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(&sb, "%d: %s, threshold %gx, last alert %s\n", id, status, cfg.Threshold, lastAlert)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	alertDirectionUp   = "up"
+	alertDirectionDown = "down"
+)
+
+// Alert is a threshold crossing for one symbol, independent of how it is
+// formatted or where it is delivered.
+type Alert struct {
+	ChatID    int64
+	Symbol    string
+	Data      *VolumeData
+	Threshold float64
+	Time      time.Time
+	Direction string
+	Escalated bool
+	// BaselineLabel names what Data.PrevVolume is, e.g. "Previous Hour".
+	BaselineLabel string
+	// CurrentLabel names what Data.CurrVolume is, e.g. "Current Hour".
+	CurrentLabel string
+	// Interval is the candle interval the alert was computed on.
+	Interval string
+	// Timeframes lists every interval of a merged alert with its ratio,
+	// e.g. "5m 4.10x".
+	Timeframes []string
+	// Metric is the kline field the ratio was computed on.
+	Metric string
+	// MarketRatio is the average ratio across the scan when the chat uses
+	// market-relative alerts, zero otherwise.
+	MarketRatio float64
+	// PreviousRatio is the symbol's ratio in the chat's previous scan, zero
+	// if it wasn't scanned then.
+	PreviousRatio float64
+	// Acceleration is the ratio's rise per candle, zero unless the chat
+	// uses acceleration alerts.
+	Acceleration float64
+	// Priority marks a symbol the chat tagged with /priority.
+	Priority bool
+	// Test marks an alert sent by /testnotifiers.
+	Test bool
+	// Volatility is the symbol's return volatility Threshold was adapted
+	// to, zero unless the chat uses /adaptive.
+	Volatility float64
+	// WashTrading marks an alert the wash-trading heuristic matched.
+	WashTrading bool
+	// Severity is the chat's label for the alert's ratio band, e.g. "🔥".
+	Severity string
+	// Sparkline charts the metric over the last candles, empty unless the
+	// chat enabled /sparkline.
+	Sparkline string
+	// DisplayRate converts the USDT quote volume into DisplayCurrency,
+	// zero when no conversion is shown.
+	DisplayCurrency string
+	DisplayRate     float64
+}
+
+func newAlert(chatID int64, cfg ChatConfig, symbol string, data *VolumeData, escalated bool, now time.Time) Alert {
+	direction := alertDirectionUp
+	if data.Ratio < 1 {
+		direction = alertDirectionDown
+	}
+
+	baseline, current := candleLabels(cfg, klineInterval)
+	switch {
+	case cfg.Daily && dailySupported(cfg.Metric):
+		baseline, current = "Previous Day", "Last 24h"
+	case cfg.MAType != "":
+		baseline = maLabel(cfg)
+	}
+
+	var acceleration float64
+	if cfg.Acceleration > 0 {
+		acceleration = data.Acceleration
+	}
+
+	return Alert{
+		ChatID:        chatID,
+		Symbol:        symbol,
+		Data:          data,
+		Threshold:     symbolThreshold(cfg, symbol),
+		Time:          now,
+		Direction:     direction,
+		Escalated:     escalated,
+		BaselineLabel: baseline,
+		CurrentLabel:  current,
+		Interval:      klineInterval,
+		Metric:        cfg.Metric,
+		Acceleration:  acceleration,
+	}
+}
+
+// formatAlert renders an alert as the plain-text Telegram message, using
+// precision decimal places for volumes and the ratio.
+func formatAlert(a Alert, precision int) string {
+	label := metricLabel(a.Metric)
+	severity := a.Severity
+	if severity == "" {
+		severity = "⚠️"
+	}
+	title := fmt.Sprintf("%s %s Alert", severity, label)
+	if a.Escalated {
+		title = fmt.Sprintf("🚨 Escalating %s Alert", label)
+	}
+	if a.Priority {
+		title = "⭐ " + title
+	}
+	if a.Test {
+		title = "🧪 Test " + title
+	}
+
+	message := fmt.Sprintf("%s for %s\n"+
+		"%s %s: %s\n"+
+		"%s %s: %s\n"+
+		"%s Ratio: %s\n",
+		title,
+		a.Symbol,
+		a.BaselineLabel, label, formatVolume(a.Data.PrevVolume, precision),
+		a.CurrentLabel, label, formatVolume(a.Data.CurrVolume, precision),
+		label, formatRatio(a.Data.Ratio, precision))
+	if a.Data.TypicalPrice > 0 {
+		message += "Typical Price: " + formatPrice(a.Data.TypicalPrice) + "\n"
+	}
+	if a.Volatility > 0 {
+		message += fmt.Sprintf("Adaptive Threshold: %s (volatility %.2f%% per candle)\n", formatRatio(a.Threshold, precision), a.Volatility*100)
+	}
+	if a.Sparkline != "" {
+		message += fmt.Sprintf("Last %d: %s\n", len([]rune(a.Sparkline)), a.Sparkline)
+	}
+	if len(a.Timeframes) > 0 {
+		message += "Timeframes: " + strings.Join(a.Timeframes, ", ") + "\n"
+	}
+	if a.PreviousRatio > 0 {
+		message += "Trend: " + formatTrend(a.Data.Ratio, a.PreviousRatio, precision) + "\n"
+	}
+	if a.Acceleration != 0 {
+		message += "Acceleration: " + formatAcceleration(a.Acceleration, precision) + "\n"
+	}
+	if a.WashTrading {
+		message += "🧼 Possible wash trading: flat price and few new trades for the volume\n"
+	}
+	if a.DisplayRate > 0 {
+		message += fmt.Sprintf("Quote Volume: ≈%s %s (approx.)\n", formatVolume(a.Data.QuoteVolume*a.DisplayRate, precision), a.DisplayCurrency)
+	}
+	if a.MarketRatio > 0 {
+		message += fmt.Sprintf("Market Ratio: %s\n", formatRatio(a.MarketRatio, precision))
+	}
+	return message + "Time: " + a.Time.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFormatAlert() Alert {
+	return Alert{
+		ChatID:        1,
+		Symbol:        "BTCUSDT",
+		Data:          &VolumeData{PrevVolume: 1500, CurrVolume: 6000, Ratio: 4, QuoteVolume: 2e6},
+		Threshold:     3,
+		Time:          time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		BaselineLabel: "Previous Hour",
+		CurrentLabel:  "Current Hour",
+	}
+}
+
+func TestFormatAlert(t *testing.T) {
+	want := "⚠️ Volume Alert for BTCUSDT\n" +
+		"Previous Hour Volume: 1.50K\n" +
+		"Current Hour Volume: 6.00K\n" +
+		"Volume Ratio: 4.00x\n" +
+		"Time: 2026-03-04 05:06:07"
+	if got := formatAlert(testFormatAlert(), 2); got != want {
+		t.Errorf("formatAlert =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatAlertTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(*Alert)
+		want  string
+	}{
+		{"trades metric", func(a *Alert) { a.Metric = metricTrades }, "⚠️ Trades Alert for BTCUSDT"},
+		{"escalated", func(a *Alert) { a.Escalated = true }, "🚨 Escalating Volume Alert for BTCUSDT"},
+		{"priority", func(a *Alert) { a.Priority = true }, "⭐ ⚠️ Volume Alert for BTCUSDT"},
+		{"test", func(a *Alert) { a.Test = true }, "🧪 Test ⚠️ Volume Alert for BTCUSDT"},
+		{"severity", func(a *Alert) { a.Severity = "🔥" }, "🔥 Volume Alert for BTCUSDT"},
+		{"escalated beats severity", func(a *Alert) { a.Severity, a.Escalated = "🔥", true }, "🚨 Escalating Volume Alert for BTCUSDT"},
+		{"everything", func(a *Alert) { a.Escalated, a.Priority, a.Test = true, true, true }, "🧪 Test ⭐ 🚨 Escalating Volume Alert for BTCUSDT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := testFormatAlert()
+			tt.apply(&alert)
+			title, _, _ := strings.Cut(formatAlert(alert, 2), "\n")
+			if title != tt.want {
+				t.Errorf("title = %q, want %q", title, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAlertOptionalLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(*Alert)
+		want  string
+	}{
+		{"typical price", func(a *Alert) { a.Data.TypicalPrice = 0.012345 }, "Typical Price: 0.01235"},
+		{"trend up", func(a *Alert) { a.PreviousRatio = 2.5 }, "Trend: ↑ from 2.50x last cycle"},
+		{"trend down", func(a *Alert) { a.PreviousRatio = 5 }, "Trend: ↓ from 5.00x last cycle"},
+		{"display currency", func(a *Alert) { a.DisplayCurrency, a.DisplayRate = "EUR", 0.9 }, "Quote Volume: ≈1.80M EUR (approx.)"},
+		{"sparkline", func(a *Alert) { a.Sparkline = "▁▂▃▅█" }, "Last 5: ▁▂▃▅█"},
+		{"acceleration", func(a *Alert) { a.Acceleration = 1.25 }, "Acceleration: +1.25x per candle"},
+		{"deceleration", func(a *Alert) { a.Acceleration = -0.5 }, "Acceleration: -0.50x per candle"},
+		{"adaptive threshold", func(a *Alert) { a.Volatility = 0.0125 }, "Adaptive Threshold: 3.00x (volatility 1.25% per candle)"},
+		{"timeframes", func(a *Alert) { a.Timeframes = []string{"5m 4.10x", "1h 3.20x"} }, "Timeframes: 5m 4.10x, 1h 3.20x"},
+		{"market ratio", func(a *Alert) { a.MarketRatio = 1.5 }, "Market Ratio: 1.50x"},
+		{"wash trading", func(a *Alert) { a.WashTrading = true }, "🧼 Possible wash trading"},
+	}
+	base := formatAlert(testFormatAlert(), 2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := testFormatAlert()
+			tt.apply(&alert)
+			got := formatAlert(alert, 2)
+			if !strings.Contains(got, "\n"+tt.want) {
+				t.Errorf("formatAlert =\n%s\nwant a line starting %q", got, tt.want)
+			}
+			if strings.Contains(base, tt.want) {
+				t.Errorf("line %q shows without its field set", tt.want)
+			}
+			if !strings.HasSuffix(got, "\nTime: 2026-03-04 05:06:07") {
+				t.Errorf("formatAlert =\n%s\nwant the time last", got)
+			}
+		})
+	}
+}
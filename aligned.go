@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	scanPeriod = 5 * time.Minute
+
+	// candleCloseBuffer gives Binance a moment to publish the closed
+	// candle before an aligned scan reads it.
+	candleCloseBuffer = 10 * time.Second
+)
+
+// nextCandleClose returns when the candle of interval that is open at now
+// closes. Binance opens candles on multiples of the interval since the
+// Unix epoch.
+func nextCandleClose(now time.Time, interval string) time.Time {
+	d := intervalDuration(interval)
+	if d == 0 {
+		return now
+	}
+	return now.Truncate(d).Add(d)
+}
+
+// scanWait returns how long the monitoring loop sleeps before its next
+// scan: until shortly after the next candle close with aligned scans,
+// otherwise the scan interval of the chat's tier.
+func scanWait(cfg ChatConfig, now time.Time) time.Duration {
+	if !cfg.AlignScans {
+		return chatTier(cfg).ScanInterval
+	}
+	return nextCandleClose(now, klineInterval).Add(candleCloseBuffer).Sub(now)
+}
+
+func handleAlignCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.AlignScans = true
+		})
+		next := nextCandleClose(time.Now(), klineInterval).Add(candleCloseBuffer)
+		return fmt.Sprintf("Scanning once per %s candle, %s after it closes, comparing the closed candle with the one before. Next scan after %s UTC.",
+			klineInterval, candleCloseBuffer, next.UTC().Format("15:04:05"))
+	case "off":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.AlignScans = false
+		})
+		return fmt.Sprintf("Scanning every %s again.", chatTier(cfg).ScanInterval)
+	default:
+		return "Usage: /align on|off"
+	}
+}
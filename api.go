@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The HTTP API controls chats the same way their Telegram commands do. It
+// listens on API_ADDR, is disabled when that is empty, and requires
+// "Authorization: Bearer <API_TOKEN>" on every request.
+//
+//	GET  /api/chats/{id}/status     monitoring state, threshold and the /status text
+//	POST /api/chats/{id}/start      start monitoring, like /monitor
+//	POST /api/chats/{id}/stop       stop monitoring, like /stop
+//	PUT  /api/chats/{id}/threshold  set the threshold from {"threshold": 5}
+//
+// Responses are JSON. Errors are {"error": "..."} with a 4xx status.
+
+// apiStatus is the body of the status endpoint.
+type apiStatus struct {
+	ChatID     int64   `json:"chat_id"`
+	Monitoring bool    `json:"monitoring"`
+	Threshold  float64 `json:"threshold"`
+	Status     string  `json:"status"`
+}
+
+type apiMessage struct {
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+var apiServer *http.Server
+
+// startAPIServer serves the HTTP API in the background if API_ADDR is set.
+func startAPIServer() {
+	s := currentSettings()
+	if s.APIAddr == "" {
+		return
+	}
+	if s.APIToken == "" {
+		log.Printf("API_ADDR is set but API_TOKEN is empty, not starting the HTTP API")
+		return
+	}
+
+	apiServer = &http.Server{
+		Addr:              s.APIAddr,
+		Handler:           apiHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("HTTP API listening on %s", s.APIAddr)
+		if err := apiServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving HTTP API: %v", err)
+		}
+	}()
+}
+
+// stopAPIServer stops accepting API requests, letting running ones finish.
+func stopAPIServer() {
+	if apiServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := apiServer.Shutdown(ctx); err != nil {
+		log.Printf("Error stopping HTTP API: %v", err)
+	}
+}
+
+func apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/chats/{id}/status", apiChat(apiGetStatus))
+	mux.HandleFunc("POST /api/chats/{id}/start", apiChat(apiStart))
+	mux.HandleFunc("POST /api/chats/{id}/stop", apiChat(apiStop))
+	mux.HandleFunc("PUT /api/chats/{id}/threshold", apiChat(apiSetThreshold))
+	return mux
+}
+
+// apiChat authenticates the request and parses the chat ID from the path
+// before calling fn.
+func apiChat(fn func(w http.ResponseWriter, r *http.Request, chatID int64)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(currentSettings().APIToken)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, apiMessage{Error: "invalid or missing token"})
+			return
+		}
+		if shuttingDown() {
+			writeJSON(w, http.StatusServiceUnavailable, apiMessage{Error: "shutting down"})
+			return
+		}
+
+		chatID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, apiMessage{Error: "chat id must be an integer"})
+			return
+		}
+		fn(w, r, chatID)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing API response: %v", err)
+	}
+}
+
+func apiGetStatus(w http.ResponseWriter, r *http.Request, chatID int64) {
+	writeJSON(w, http.StatusOK, apiStatus{
+		ChatID:     chatID,
+		Monitoring: chatIsMonitoring(chatID),
+		Threshold:  getChatConfig(chatID).Threshold,
+		Status:     formatStatus(chatID),
+	})
+}
+
+func apiStart(w http.ResponseWriter, r *http.Request, chatID int64) {
+	if chatIsMonitoring(chatID) {
+		writeJSON(w, http.StatusConflict, apiMessage{Error: "monitoring is already running"})
+		return
+	}
+	goWorker(func() { startMonitoring(chatID) })
+	writeJSON(w, http.StatusAccepted, apiMessage{Message: "monitoring started"})
+}
+
+func apiStop(w http.ResponseWriter, r *http.Request, chatID int64) {
+	if !chatIsMonitoring(chatID) {
+		writeJSON(w, http.StatusConflict, apiMessage{Error: "monitoring is not running"})
+		return
+	}
+	stopMonitoring(chatID)
+	writeJSON(w, http.StatusOK, apiMessage{Message: "monitoring stopped"})
+}
+
+func apiSetThreshold(w http.ResponseWriter, r *http.Request, chatID int64) {
+	var body struct {
+		Threshold float64 `json:"threshold"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiMessage{Error: "body must be {\"threshold\": <number>}"})
+		return
+	}
+	if body.Threshold <= 1 {
+		writeJSON(w, http.StatusBadRequest, apiMessage{Error: "threshold must be greater than 1"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiMessage{Message: handleThresholdCommand(chatID, strconv.FormatFloat(body.Threshold, 'f', -1, 64))})
+}
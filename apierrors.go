@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRecentErrors     = 100
+	defaultErrorsListed = 20
+)
+
+// apiError is one failed upstream request kept for /errors.
+type apiError struct {
+	Source  string
+	Message string
+	Time    time.Time
+}
+
+var (
+	// recentErrors is a ring buffer of the last maxRecentErrors failures;
+	// recentErrorsNext is where the next one goes.
+	recentErrors     []apiError
+	recentErrorsNext int
+	recentErrorsMu   sync.Mutex
+)
+
+// recordAPIError adds a failed request to the recent errors and returns it
+// unchanged, so the FetchError constructors can record as they return.
+func recordAPIError(err *FetchError) *FetchError {
+	recentErrorsMu.Lock()
+	defer recentErrorsMu.Unlock()
+
+	entry := apiError{Source: err.Source, Message: err.Error(), Time: time.Now()}
+	if len(recentErrors) < maxRecentErrors {
+		recentErrors = append(recentErrors, entry)
+	} else {
+		recentErrors[recentErrorsNext] = entry
+	}
+	recentErrorsNext = (recentErrorsNext + 1) % maxRecentErrors
+	return err
+}
+
+// latestAPIErrors returns up to n recent errors, newest first.
+func latestAPIErrors(n int) []apiError {
+	recentErrorsMu.Lock()
+	defer recentErrorsMu.Unlock()
+
+	n = min(n, len(recentErrors))
+	latest := make([]apiError, 0, n)
+	for i := 1; i <= n; i++ {
+		latest = append(latest, recentErrors[(recentErrorsNext-i+maxRecentErrors)%maxRecentErrors])
+	}
+	return latest
+}
+
+func handleErrorsCommand(chatID int64, args string) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+
+	n := defaultErrorsListed
+	if arg := strings.TrimSpace(args); arg != "" {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v < 1 || v > maxRecentErrors {
+			return fmt.Sprintf("Usage: /errors [1-%d]", maxRecentErrors)
+		}
+		n = v
+	}
+
+	errs := latestAPIErrors(n)
+	if len(errs) == 0 {
+		return "No API errors since startup."
+	}
+
+	counts := make(map[string]int)
+	var sources []string
+	for _, e := range errs {
+		if counts[e.Source] == 0 {
+			sources = append(sources, e.Source)
+		}
+		counts[e.Source]++
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Last %d API errors:\n", len(errs))
+	for _, e := range errs {
+		fmt.Fprintf(&sb, "%s %s\n", e.Time.Format("2006-01-02 15:04:05"), e.Message)
+	}
+	sb.WriteString("\nBy source: ")
+	for i, source := range sources {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s %d", source, counts[source])
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var (
+	alertTimes   = make(map[int64][]time.Time)
+	alertTimesMu sync.Mutex
+)
+
+// noteAlertForAutoPause records an alert sent to the chat and reports
+// whether the chat has now sent limit alerts within AutoPauseWindow.
+// A limit of zero disables auto-pause.
+func noteAlertForAutoPause(chatID int64, limit int, now time.Time) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	alertTimesMu.Lock()
+	defer alertTimesMu.Unlock()
+
+	cutoff := now.Add(-currentSettings().AutoPauseWindow)
+	times := alertTimes[chatID]
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	alertTimes[chatID] = kept
+
+	return len(kept) >= limit
+}
+
+func resetAutoPause(chatID int64) {
+	alertTimesMu.Lock()
+	delete(alertTimes, chatID)
+	alertTimesMu.Unlock()
+}
+
+// autoPauseMonitoring stops monitoring for a chat that hit its alert limit.
+func autoPauseMonitoring(chatID int64, limit int) {
+	monitoringStatus.Store(chatID, false)
+	saveMonitoringStatus(chatID)
+	resetAutoPause(chatID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"⏸ Monitoring paused after %d alerts within %s. Use /monitor to resume.", limit, currentSettings().AutoPauseWindow))
+	sendChatMessage(msg)
+}
+
+func handleAutoPauseCommand(chatID int64, args string) string {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	if arg == "" {
+		if limit := getChatConfig(chatID).AutoPause; limit > 0 {
+			return fmt.Sprintf("Monitoring pauses after %d alerts within %s. Use /autopause off to disable.", limit, currentSettings().AutoPauseWindow)
+		}
+		return "Usage: /autopause <alerts>, or /autopause off"
+	}
+
+	limit := 0
+	if arg != "off" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return "Alert count must be a positive number"
+		}
+		limit = n
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.AutoPause = limit
+	})
+	if limit == 0 {
+		return "Auto-pause disabled."
+	}
+	return fmt.Sprintf("Monitoring will pause after %d alerts within %s.", limit, currentSettings().AutoPauseWindow)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// binanceKlinePageSize is the most klines one request returns.
+	binanceKlinePageSize = 1000
+
+	maxBacktestPeriod  = 90 * 24 * time.Hour
+	backtestForward    = 3
+	maxBacktestHitsRow = 20
+)
+
+// backtestHit is a candle whose ratio to the one before exceeded the
+// threshold, with the close-to-close price move backtestForward candles
+// later. HasForward is false when the range ends before then.
+type backtestHit struct {
+	OpenTime   time.Time
+	Ratio      float64
+	ForwardPct float64
+	HasForward bool
+}
+
+// backtestKlines replays the alert rule over closed klines, oldest first:
+// each candle's metric against the previous candle's, reporting the ones
+// above threshold and the price change forward candles after each.
+func backtestKlines(klines []BinanceKline, field int, threshold float64, forward int) []backtestHit {
+	var hits []backtestHit
+	for i := 1; i < len(klines); i++ {
+		prev, err := klineFloat(klines[i-1], field)
+		if err != nil || prev == 0 {
+			continue
+		}
+		curr, err := klineFloat(klines[i], field)
+		if err != nil || curr/prev <= threshold {
+			continue
+		}
+
+		hit := backtestHit{OpenTime: time.UnixMilli(klineOpenTime(klines[i])), Ratio: curr / prev}
+		if j := i + forward; j < len(klines) {
+			from, errFrom := klineFloat(klines[i], 4)
+			to, errTo := klineFloat(klines[j], 4)
+			if errFrom == nil && errTo == nil && from != 0 {
+				hit.ForwardPct = (to - from) / from * 100
+				hit.HasForward = true
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// getBinanceKlinesSince pages through the klines endpoint from start until
+// the most recent candle, which is dropped since it is still open.
+func getBinanceKlinesSince(symbol, interval string, start time.Time) ([]BinanceKline, error) {
+	var all []BinanceKline
+	from := start.UnixMilli()
+	for {
+		url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&startTime=%d&limit=%d",
+			binanceBaseURL, symbol, interval, from, binanceKlinePageSize)
+		page, err := fetchBinanceKlines(symbol, url)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < binanceKlinePageSize {
+			break
+		}
+		from = klineOpenTime(page[len(page)-1]) + 1
+	}
+	if len(all) > 0 {
+		all = all[:len(all)-1]
+	}
+	return all, nil
+}
+
+func handleBacktestCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 3 {
+		return "Usage: /backtest <symbol> <threshold> <period>, e.g. /backtest BTCUSDT 10 7d"
+	}
+
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(fields[1]), "x"), 64)
+	if err != nil || threshold <= 1 {
+		return "Threshold must be a number greater than 1"
+	}
+	period, err := parsePeriod(fields[2])
+	if err != nil || period < intervalDuration(klineInterval)*2 || period > maxBacktestPeriod {
+		return fmt.Sprintf("Period must be at least two %s candles and at most %dd", klineInterval, int(maxBacktestPeriod.Hours()/24))
+	}
+
+	klines, err := getBinanceKlinesSince(symbol, klineInterval, time.Now().Add(-period))
+	if errors.Is(err, ErrSymbolNotFound) {
+		return fmt.Sprintf("Symbol %s was not found on Binance", symbol)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not fetch %s: %v", symbol, err)
+	}
+
+	cfg := getChatConfig(chatID)
+	hits := backtestKlines(klines, metricField(cfg.Metric), threshold, backtestForward)
+	forward := time.Duration(backtestForward) * intervalDuration(klineInterval)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Backtest of %s above %gx over %s (%d %s candles): %d alerts\n",
+		symbol, threshold, fields[2], len(klines), klineInterval, len(hits))
+	if len(hits) == 0 {
+		return strings.TrimRight(sb.String(), "\n")
+	}
+
+	var sum float64
+	var measured, up int
+	for i, hit := range hits {
+		move := "n/a"
+		if hit.HasForward {
+			move = fmt.Sprintf("%+.2f%%", hit.ForwardPct)
+			sum += hit.ForwardPct
+			measured++
+			if hit.ForwardPct > 0 {
+				up++
+			}
+		}
+		if i < maxBacktestHitsRow {
+			fmt.Fprintf(&sb, "%s  %s  %s after %s\n", hit.OpenTime.UTC().Format("2006-01-02 15:04"), formatRatio(hit.Ratio, cfg.Precision), move, forward)
+		}
+	}
+	if len(hits) > maxBacktestHitsRow {
+		fmt.Fprintf(&sb, "... and %d more\n", len(hits)-maxBacktestHitsRow)
+	}
+	if measured > 0 {
+		fmt.Fprintf(&sb, "\nAverage move after %s: %+.2f%%, higher %d of %d times", forward, sum/float64(measured), up, measured)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBacktestKlines(t *testing.T) {
+	klines := []BinanceKline{
+		testKline(0, 100, 100),
+		testKline(1, 110, 600), // 6x, forward window ends at 4
+		testKline(2, 120, 600),
+		testKline(3, 130, 0),
+		testKline(4, 99, 500),  // previous volume zero, skipped
+		testKline(5, 90, 2500), // 5x, forward window ends at 8, past the range
+		testKline(6, 80, 2500),
+		testKline(7, 70, 2500),
+	}
+
+	hits := backtestKlines(klines, metricField(metricVolume), 4, backtestForward)
+	if len(hits) != 2 {
+		t.Fatalf("backtestKlines found %d hits, want 2: %+v", len(hits), hits)
+	}
+
+	first := hits[0]
+	if !first.OpenTime.Equal(time.UnixMilli(3600*1000)) || first.Ratio != 6 {
+		t.Errorf("first hit = %+v, want hour 1 at 6x", first)
+	}
+	if !first.HasForward || math.Abs(first.ForwardPct-(99-110)/110.0*100) > 1e-9 {
+		t.Errorf("first hit forward = %v %g, want the move from hour 1 to hour 4", first.HasForward, first.ForwardPct)
+	}
+
+	second := hits[1]
+	if !second.OpenTime.Equal(time.UnixMilli(5*3600*1000)) || second.Ratio != 5 {
+		t.Errorf("second hit = %+v, want hour 5 at 5x", second)
+	}
+	if second.HasForward {
+		t.Errorf("second hit has a forward move %g beyond the last kline", second.ForwardPct)
+	}
+}
+
+func TestBacktestKlinesForwardAtLastKline(t *testing.T) {
+	klines := []BinanceKline{testKline(0, 100, 10), testKline(1, 100, 100), testKline(2, 100, 100), testKline(3, 100, 100), testKline(4, 150, 100)}
+
+	hits := backtestKlines(klines, metricField(metricVolume), 2, backtestForward)
+	if len(hits) != 1 {
+		t.Fatalf("backtestKlines found %d hits, want 1", len(hits))
+	}
+	if !hits[0].HasForward || hits[0].ForwardPct != 50 {
+		t.Errorf("hit forward = %v %g, want 50%% measured at the last kline", hits[0].HasForward, hits[0].ForwardPct)
+	}
+	if hits := backtestKlines(klines[:4], metricField(metricVolume), 2, backtestForward); len(hits) != 1 || hits[0].HasForward {
+		t.Errorf("with the forward candle cut off, hits = %+v, want one without a forward move", hits)
+	}
+}
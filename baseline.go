@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const baselinesFile = "baselines.json"
+
+// volumeBaseline is a cached reference volume for a symbol. It stays valid
+// while the latest candle is still the one it was computed for.
+type volumeBaseline struct {
+	Value     float64   `json:"value"`
+	OpenTime  int64     `json:"open_time"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var (
+	baselines      = make(map[string]volumeBaseline)
+	baselinesMu    sync.Mutex
+	baselinesDirty bool
+)
+
+func baselineKey(symbol, metric, maType string, window int) string {
+	return fmt.Sprintf("%s:%s:%s:%d", symbol, metric, maType, window)
+}
+
+// lookupBaseline returns the baseline stored under key unless it is older
+// than the configured maximum age.
+func lookupBaseline(key string) (volumeBaseline, bool) {
+	baselinesMu.Lock()
+	defer baselinesMu.Unlock()
+
+	b, ok := baselines[key]
+	if !ok || time.Since(b.UpdatedAt) > currentSettings().BaselineMaxAge {
+		return volumeBaseline{}, false
+	}
+	return b, true
+}
+
+func storeBaseline(key string, b volumeBaseline) {
+	baselinesMu.Lock()
+	baselines[key] = b
+	baselinesDirty = true
+	baselinesMu.Unlock()
+}
+
+// saveBaselines writes the baselines to disk if any changed since the last save.
+func saveBaselines() {
+	baselinesMu.Lock()
+	if !baselinesDirty {
+		baselinesMu.Unlock()
+		return
+	}
+	data, err := json.Marshal(baselines)
+	baselinesDirty = false
+	baselinesMu.Unlock()
+	if err != nil {
+		log.Printf("Error marshaling baselines: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(baselinesFile), data, 0644); err != nil {
+		log.Printf("Error saving baselines: %v", err)
+	}
+}
+
+func loadBaselines() {
+	data, err := os.ReadFile(dataPath(baselinesFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading baselines file: %v", err)
+		}
+		return
+	}
+
+	loaded := make(map[string]volumeBaseline)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("Error unmarshaling baselines: %v", err)
+		return
+	}
+
+	baselinesMu.Lock()
+	baselines = loaded
+	baselinesMu.Unlock()
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxBaskets          = 10
+	maxBasketComponents = 20
+)
+
+var basketNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,20}$`)
+
+// BasketComponent is one symbol of a basket and its weight.
+type BasketComponent struct {
+	Symbol string  `json:"symbol"`
+	Weight float64 `json:"weight"`
+}
+
+// Basket is a named, weighted group of symbols whose aggregate volume
+// ratio alerts like a single symbol's when Monitored is set.
+type Basket struct {
+	Name       string            `json:"name"`
+	Components []BasketComponent `json:"components"`
+	Monitored  bool              `json:"monitored,omitempty"`
+}
+
+func (b Basket) String() string {
+	parts := make([]string, len(b.Components))
+	for i, c := range b.Components {
+		parts[i] = fmt.Sprintf("%s:%g", c.Symbol, c.Weight)
+	}
+	return fmt.Sprintf("%s: %s", b.Name, strings.Join(parts, " "))
+}
+
+func (b Basket) validate() error {
+	if !basketNamePattern.MatchString(b.Name) {
+		return fmt.Errorf("name must be 1 to 20 lower-case letters, digits, - or _")
+	}
+	if len(b.Components) == 0 || len(b.Components) > maxBasketComponents {
+		return fmt.Errorf("a basket holds 1 to %d symbols", maxBasketComponents)
+	}
+	seen := make(map[string]bool)
+	for _, c := range b.Components {
+		if c.Symbol == "" || c.Symbol != strings.ToUpper(c.Symbol) || c.Weight <= 0 {
+			return fmt.Errorf("%s:%g is not an upper-case symbol with a positive weight", c.Symbol, c.Weight)
+		}
+		if seen[c.Symbol] {
+			return fmt.Errorf("%s is listed twice", c.Symbol)
+		}
+		seen[c.Symbol] = true
+	}
+	return nil
+}
+
+// cloneBaskets copies baskets and their components.
+func cloneBaskets(baskets []Basket) []Basket {
+	if baskets == nil {
+		return nil
+	}
+	cloned := make([]Basket, len(baskets))
+	for i, b := range baskets {
+		b.Components = append([]BasketComponent(nil), b.Components...)
+		cloned[i] = b
+	}
+	return cloned
+}
+
+// basketRatio is the weighted average of the components' volume ratios, so
+// each symbol counts by its weight however large its volume is. Components
+// without data are left out of both sums; ok is false if none had any.
+func basketRatio(components []BasketComponent, ratios map[string]float64) (ratio float64, ok bool) {
+	var weighted, total float64
+	for _, c := range components {
+		r, found := ratios[c.Symbol]
+		if !found {
+			continue
+		}
+		weighted += c.Weight * r
+		total += c.Weight
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return weighted / total, true
+}
+
+// basketSent remembers the candle each basket last alerted on so a basket
+// spike is reported once per candle.
+var (
+	basketSent   = make(map[basketKey]time.Time)
+	basketSentMu sync.Mutex
+)
+
+type basketKey struct {
+	ChatID int64
+	Name   string
+}
+
+// checkBaskets alerts on the chat's monitored baskets whose aggregate ratio
+// exceeds the chat's threshold. Component ratios come from the scan's
+// results where possible and are fetched otherwise.
+func checkBaskets(chatID int64, cfg ChatConfig, results []symbolVolume) {
+	ratios := make(map[string]float64, len(results))
+	var openTime time.Time
+	for _, result := range results {
+		ratios[result.Symbol] = result.Data.Ratio
+		openTime = result.Data.OpenTime
+	}
+
+	for _, basket := range cfg.Baskets {
+		if !basket.Monitored {
+			continue
+		}
+		for _, c := range basket.Components {
+			if _, ok := ratios[c.Symbol]; ok {
+				continue
+			}
+			data, err := getBinanceVolume(c.Symbol, cfg.Metric, comparesClosed(cfg))
+			if err != nil {
+				log.Printf("Error getting volume data for basket %s component %s: %v\n", basket.Name, c.Symbol, err)
+				continue
+			}
+			if data != nil {
+				ratios[c.Symbol] = data.Ratio
+				openTime = data.OpenTime
+			}
+		}
+
+		ratio, ok := basketRatio(basket.Components, ratios)
+		if !ok || ratio <= cfg.Threshold {
+			continue
+		}
+
+		key := basketKey{chatID, basket.Name}
+		basketSentMu.Lock()
+		sent := basketSent[key].Equal(openTime)
+		basketSent[key] = openTime
+		basketSentMu.Unlock()
+		if sent {
+			continue
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "🧺 Basket %s %s spike: %s weighted ratio (threshold %gx)\n", basket.Name, strings.ToLower(metricLabel(cfg.Metric)), formatRatio(ratio, cfg.Precision), cfg.Threshold)
+		for _, c := range basket.Components {
+			if r, ok := ratios[c.Symbol]; ok {
+				fmt.Fprintf(&sb, "%s %s (weight %g)\n", c.Symbol, formatRatio(r, cfg.Precision), c.Weight)
+			} else {
+				fmt.Fprintf(&sb, "%s n/a (weight %g)\n", c.Symbol, c.Weight)
+			}
+		}
+		queueMessage(chatID, strings.TrimRight(sb.String(), "\n"), cfg.Silent)
+	}
+}
+
+// parseBasketComponents parses "SYMBOL:weight" arguments, resolving each
+// symbol like the other commands do. A missing weight is 1.
+func parseBasketComponents(chatID int64, args []string) ([]BasketComponent, error) {
+	components := make([]BasketComponent, 0, len(args))
+	for _, arg := range args {
+		name, weightText, hasWeight := strings.Cut(arg, ":")
+		weight := 1.0
+		if hasWeight {
+			var err error
+			if weight, err = strconv.ParseFloat(weightText, 64); err != nil || weight <= 0 {
+				return nil, fmt.Errorf("%s: weight must be a positive number", arg)
+			}
+		}
+		symbol, err := resolveSymbol(chatID, name)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, BasketComponent{Symbol: symbol, Weight: weight})
+	}
+	return components, nil
+}
+
+func findBasket(baskets []Basket, name string) int {
+	for i, b := range baskets {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func handleBasketCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	usage := "Usage: /basket create <name> <symbol>:<weight> ..., /basket monitor|unmonitor|delete <name>, or /basket list"
+
+	action := "list"
+	if len(fields) > 0 {
+		action = strings.ToLower(fields[0])
+	}
+	var name string
+	if len(fields) > 1 {
+		name = strings.ToLower(fields[1])
+	}
+
+	switch action {
+	case "list":
+		baskets := getChatConfig(chatID).Baskets
+		if len(baskets) == 0 {
+			return "No baskets defined. " + usage
+		}
+		var sb strings.Builder
+		sb.WriteString("Baskets:\n")
+		for _, b := range baskets {
+			sb.WriteString(b.String())
+			if b.Monitored {
+				sb.WriteString(" (monitored)")
+			}
+			sb.WriteString("\n")
+		}
+		return strings.TrimRight(sb.String(), "\n")
+
+	case "create":
+		if len(fields) < 3 {
+			return usage
+		}
+		components, err := parseBasketComponents(chatID, fields[2:])
+		if err != nil {
+			return err.Error()
+		}
+		basket := Basket{Name: name, Components: components}
+		if err := basket.validate(); err != nil {
+			return fmt.Sprintf("Basket not saved: %v", err)
+		}
+
+		cfg := getChatConfig(chatID)
+		i := findBasket(cfg.Baskets, name)
+		if i < 0 && len(cfg.Baskets) >= maxBaskets {
+			return fmt.Sprintf("At most %d baskets can be defined", maxBaskets)
+		}
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			if i := findBasket(cfg.Baskets, name); i >= 0 {
+				basket.Monitored = cfg.Baskets[i].Monitored
+				cfg.Baskets[i] = basket
+			} else {
+				cfg.Baskets = append(cfg.Baskets, basket)
+			}
+		})
+		return fmt.Sprintf("Basket saved: %s. Use /basket monitor %s to alert on it.", basket, name)
+
+	case "monitor", "unmonitor", "delete":
+		if len(fields) != 2 {
+			return usage
+		}
+		if findBasket(getChatConfig(chatID).Baskets, name) < 0 {
+			return fmt.Sprintf("No basket named %s.", name)
+		}
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			i := findBasket(cfg.Baskets, name)
+			if i < 0 {
+				return
+			}
+			switch action {
+			case "monitor":
+				cfg.Baskets[i].Monitored = true
+			case "unmonitor":
+				cfg.Baskets[i].Monitored = false
+			default:
+				cfg.Baskets = append(cfg.Baskets[:i], cfg.Baskets[i+1:]...)
+			}
+		})
+		switch action {
+		case "monitor":
+			return fmt.Sprintf("Alerting when basket %s's weighted ratio exceeds the %gx threshold. It is checked each scan while monitoring is running.", name, getChatConfig(chatID).Threshold)
+		case "unmonitor":
+			return fmt.Sprintf("Basket %s is no longer monitored.", name)
+		default:
+			return fmt.Sprintf("Basket %s deleted.", name)
+		}
+	}
+	return usage
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// isBlockedError reports whether Telegram refused a send with 403
+// Forbidden, which it returns once the user blocked the bot or the bot was
+// removed from the group.
+func isBlockedError(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden
+}
+
+// dropSubscription removes a chat the bot can no longer message from the
+// monitored chats. Its settings are kept in case it comes back.
+func dropSubscription(chatID int64, err error) {
+	if _, ok := monitoringStatus.LoadAndDelete(chatID); !ok {
+		return
+	}
+	if err := statusStore.DeleteChat(chatID); err != nil {
+		log.Printf("Error saving monitoring status: %v", err)
+	}
+	log.Printf("Stopped monitoring for chat %d, Telegram refused delivery: %v", chatID, err)
+}
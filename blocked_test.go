@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBlockedChatIsDropped(t *testing.T) {
+	fake := useFakeTelegram(t, http.StatusForbidden)
+	useMemoryStore(t)
+	resetOutbox(t)
+	const chatID int64 = 7001
+	t.Cleanup(func() { monitoringStatus.Delete(chatID) })
+
+	monitoringStatus.Store(chatID, true)
+	saveMonitoringStatus(chatID)
+
+	err := queueMessage(chatID, "📈 Breakout: BTCUSDT", false)
+	if !isBlockedError(err) {
+		t.Fatalf("queueMessage error = %v, want a 403", err)
+	}
+	if chatIsMonitoring(chatID) {
+		t.Error("chat still monitoring after Telegram answered 403")
+	}
+	if chats, _ := statusStore.LoadAll(); len(chats) != 0 {
+		t.Errorf("blocked chat still stored: %v", chats)
+	}
+	if due := claimDueAlerts(testFarFuture()); len(due) != 0 {
+		t.Errorf("blocked alert left in the outbox: %v", due)
+	}
+	if n := len(fake.sent()); n != 1 {
+		t.Errorf("made %d requests, want 1 without retries", n)
+	}
+}
+
+func TestOtherErrorsKeepChat(t *testing.T) {
+	useFakeTelegram(t, http.StatusInternalServerError)
+	useMemoryStore(t)
+	resetOutbox(t)
+	const chatID int64 = 7002
+	t.Cleanup(func() { monitoringStatus.Delete(chatID) })
+
+	monitoringStatus.Store(chatID, true)
+	if err := sendText(chatID, "hello", false); err == nil || isBlockedError(err) {
+		t.Fatalf("sendText error = %v, want a non-403 failure", err)
+	}
+	if !chatIsMonitoring(chatID) {
+		t.Error("chat dropped after a 500")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultBookLimit = 100
+
+// bookLimits are the depth sizes Binance accepts.
+var bookLimits = []int{5, 10, 20, 50, 100, 500, 1000, 5000}
+
+type depthResponse struct {
+	Bids [][]interface{} `json:"bids"`
+	Asks [][]interface{} `json:"asks"`
+}
+
+// bookLevel is one price level of the order book.
+type bookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// orderBook holds bids best-first (highest price) and asks best-first
+// (lowest price), as Binance returns them.
+type orderBook struct {
+	Bids []bookLevel
+	Asks []bookLevel
+}
+
+// parseBookLevels converts Binance's [price, quantity] string pairs,
+// skipping any level that is malformed.
+func parseBookLevels(raw [][]interface{}) []bookLevel {
+	levels := make([]bookLevel, 0, len(raw))
+	for _, entry := range raw {
+		if len(entry) < 2 {
+			continue
+		}
+		priceStr, ok1 := entry[0].(string)
+		qtyStr, ok2 := entry[1].(string)
+		if !ok1 || !ok2 {
+			continue
+		}
+		price, err1 := strconv.ParseFloat(priceStr, 64)
+		qty, err2 := strconv.ParseFloat(qtyStr, 64)
+		if err1 != nil || err2 != nil || price <= 0 || qty < 0 {
+			continue
+		}
+		levels = append(levels, bookLevel{Price: price, Quantity: qty})
+	}
+	return levels
+}
+
+func getOrderBook(symbol string, limit int) (*orderBook, error) {
+	url := fmt.Sprintf("%s/api/v3/depth?symbol=%s&limit=%d", binanceBaseURL, symbol, limit)
+
+	acquireFetchSlot()
+	defer releaseFetchSlot()
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, requestError("binance depth", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, binanceBadRequest("binance depth", resp)
+	}
+	if err := responseError("binance depth", resp); err != nil {
+		return nil, err
+	}
+
+	var depth depthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&depth); err != nil {
+		return nil, decodeError("binance depth", err)
+	}
+
+	return &orderBook{Bids: parseBookLevels(depth.Bids), Asks: parseBookLevels(depth.Asks)}, nil
+}
+
+// bookImbalance returns the quote-asset value resting on each side and the
+// imbalance (bids - asks) / (bids + asks), from -1 (all asks) to 1 (all
+// bids).
+func bookImbalance(book *orderBook) (bidValue, askValue, imbalance float64) {
+	for _, level := range book.Bids {
+		bidValue += level.Price * level.Quantity
+	}
+	for _, level := range book.Asks {
+		askValue += level.Price * level.Quantity
+	}
+	if total := bidValue + askValue; total > 0 {
+		imbalance = (bidValue - askValue) / total
+	}
+	return bidValue, askValue, imbalance
+}
+
+func handleBookCommand(chatID int64, args string) string {
+	usage := "Usage: /book <symbol> [depth]"
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		return usage
+	}
+
+	limit := defaultBookLimit
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || !containsInt(bookLimits, n) {
+			return fmt.Sprintf("Depth must be one of %s", joinInts(bookLimits))
+		}
+		limit = n
+	}
+
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+	precision := getChatConfig(chatID).Precision
+
+	book, err := getOrderBook(symbol, limit)
+	if err != nil {
+		return fmt.Sprintf("Could not fetch the %s order book: %v", symbol, err)
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return fmt.Sprintf("The %s order book is empty", symbol)
+	}
+
+	bestBid, bestAsk := book.Bids[0].Price, book.Asks[0].Price
+	mid := (bestBid + bestAsk) / 2
+	bidValue, askValue, imbalance := bookImbalance(book)
+
+	pressure := "balanced"
+	switch {
+	case imbalance > 0.1:
+		pressure = "buy pressure"
+	case imbalance < -0.1:
+		pressure = "sell pressure"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s order book (top %d levels):\n", symbol, limit)
+	fmt.Fprintf(&sb, "Best bid: %g\n", bestBid)
+	fmt.Fprintf(&sb, "Best ask: %g\n", bestAsk)
+	fmt.Fprintf(&sb, "Spread: %g (%.*f%%)\n", bestAsk-bestBid, precision, (bestAsk-bestBid)/mid*100)
+	fmt.Fprintf(&sb, "Bid value: %s\n", formatVolume(bidValue, precision))
+	fmt.Fprintf(&sb, "Ask value: %s\n", formatVolume(askValue, precision))
+	fmt.Fprintf(&sb, "Imbalance: %+.*f%% (%s)", precision, imbalance*100, pressure)
+	return sb.String()
+}
+
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	minBoostFactor   = 0.1
+	maxBoostFactor   = 10.0
+	maxBoostDuration = 7 * 24 * time.Hour
+)
+
+// boostFactor returns the multiplier applied to the chat's thresholds at
+// now, or 1 when no boost is active.
+func boostFactor(cfg ChatConfig, now time.Time) float64 {
+	if cfg.BoostFactor == 0 || !now.Before(time.Unix(cfg.BoostUntil, 0)) {
+		return 1
+	}
+	return cfg.BoostFactor
+}
+
+// boostedThreshold applies the active boost to threshold. A boosted
+// threshold never drops below 1x, which would alert on every symbol.
+func boostedThreshold(cfg ChatConfig, threshold float64, now time.Time) float64 {
+	return max(threshold*boostFactor(cfg, now), 1)
+}
+
+// expireBoost clears a boost that has run out and tells the chat its
+// thresholds are back to normal. It returns the chat's config afterwards.
+func expireBoost(chatID int64, cfg ChatConfig, now time.Time) ChatConfig {
+	if cfg.BoostFactor == 0 || now.Before(time.Unix(cfg.BoostUntil, 0)) {
+		return cfg
+	}
+
+	cfg = updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.BoostFactor = 0
+		cfg.BoostUntil = 0
+	})
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("⏱ Boost expired. The threshold is back to %gx.", cfg.Threshold))
+	if _, err := sendChatMessage(msg); err != nil {
+		log.Printf("Error sending boost expiry to chat %d: %v", chatID, err)
+	}
+	return cfg
+}
+
+// parseBoostFactor accepts a multiplier written as "0.5" or "0.5x".
+func parseBoostFactor(s string) (float64, error) {
+	factor, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	if err != nil || factor < minBoostFactor || factor > maxBoostFactor || factor == 1 {
+		return 0, fmt.Errorf("factor must be between %g and %g and not 1, e.g. 0.5x", minBoostFactor, maxBoostFactor)
+	}
+	return factor, nil
+}
+
+func handleBoostCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	usage := "Usage: /boost <factor> <duration>, e.g. /boost 0.5x 1h, or /boost off"
+
+	switch {
+	case len(fields) == 0:
+		cfg := getChatConfig(chatID)
+		if factor := boostFactor(cfg, time.Now()); factor != 1 {
+			return fmt.Sprintf("Thresholds are multiplied by %gx until %s (threshold %gx).", factor, time.Unix(cfg.BoostUntil, 0).Format("2006-01-02 15:04"), boostedThreshold(cfg, cfg.Threshold, time.Now()))
+		}
+		return "No boost active. " + usage
+	case len(fields) == 1 && strings.EqualFold(fields[0], "off"):
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.BoostFactor = 0
+			cfg.BoostUntil = 0
+		})
+		return fmt.Sprintf("Boost cancelled. The threshold is back to %gx.", cfg.Threshold)
+	case len(fields) != 2:
+		return usage
+	}
+
+	factor, err := parseBoostFactor(fields[0])
+	if err != nil {
+		return err.Error()
+	}
+	duration, err := time.ParseDuration(fields[1])
+	if err != nil || duration < time.Minute || duration > maxBoostDuration {
+		return fmt.Sprintf("Duration must be between 1m and %s, e.g. 30m or 2h", maxBoostDuration)
+	}
+
+	until := time.Now().Add(duration)
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.BoostFactor = factor
+		cfg.BoostUntil = until.Unix()
+	})
+	return fmt.Sprintf("Thresholds multiplied by %gx for %s: alerting above %gx until %s.", factor, duration, boostedThreshold(cfg, cfg.Threshold, until.Add(-time.Second)), until.Format("15:04"))
+}
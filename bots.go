@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const chatBotsFile = "chat_bots.json"
+
+// bots holds every bot the process runs; bot is the first of them and
+// serves chats not yet bound to one.
+var bots []*tgbotapi.BotAPI
+
+// botChat is a Telegram chat as seen by one bot.
+type botChat struct {
+	Bot  string
+	Chat int64
+}
+
+// chatBinding records the key a bot's chat is stored under.
+type chatBinding struct {
+	Bot  string `json:"bot"`
+	Chat int64  `json:"chat"`
+	Key  int64  `json:"key"`
+}
+
+// syntheticChatKeyBase lies above every Telegram chat ID, which fit in 52
+// bits, so keys allocated from it never collide with a real chat.
+const syntheticChatKeyBase = int64(1) << 53
+
+// chatKeys maps each bot's chat to the key its config, status and alerts
+// are stored under, and keyChats maps the key back. A chat's first bot uses
+// the Telegram chat ID itself. A private chat's ID is the user's ID, the
+// same for every bot, so any other bot the user talks to gets a synthetic
+// key and its own state. Groups stay with the first bot.
+var (
+	chatKeys   = make(map[botChat]int64)
+	keyChats   = make(map[int64]botChat)
+	chatBotsMu sync.Mutex
+)
+
+// botTokens returns the configured bot tokens: TELEGRAM_BOT_TOKEN, which
+// may hold several comma-separated tokens, followed by TELEGRAM_BOT_TOKEN_2,
+// TELEGRAM_BOT_TOKEN_3 and so on.
+func botTokens() []string {
+	var tokens []string
+	for _, token := range strings.Split(os.Getenv("TELEGRAM_BOT_TOKEN"), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	for i := 2; ; i++ {
+		token := strings.TrimSpace(os.Getenv(fmt.Sprintf("TELEGRAM_BOT_TOKEN_%d", i)))
+		if token == "" {
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func botByName(username string) *tgbotapi.BotAPI {
+	for _, b := range bots {
+		if b.Self.UserName == username {
+			return b
+		}
+	}
+	return nil
+}
+
+// botFor returns the bot serving a chat key, or the primary bot.
+func botFor(chatID int64) *tgbotapi.BotAPI {
+	chatBotsMu.Lock()
+	username := keyChats[chatID].Bot
+	chatBotsMu.Unlock()
+
+	if b := botByName(username); b != nil {
+		return b
+	}
+	return bot
+}
+
+// telegramChatID returns the Telegram chat a chat key addresses.
+func telegramChatID(chatID int64) int64 {
+	chatBotsMu.Lock()
+	defer chatBotsMu.Unlock()
+	if bc, ok := keyChats[chatID]; ok {
+		return bc.Chat
+	}
+	return chatID
+}
+
+// claimChat returns the key b serves the Telegram chat under, binding it
+// on first contact, and reports false for a group that belongs to another
+// configured bot.
+func claimChat(chatID int64, b *tgbotapi.BotAPI) (int64, bool) {
+	bc := botChat{b.Self.UserName, chatID}
+
+	chatBotsMu.Lock()
+	if key, ok := chatKeys[bc]; ok {
+		chatBotsMu.Unlock()
+		return key, true
+	}
+	key := chatID
+	if owner, ok := keyChats[chatID]; ok {
+		if botByName(owner.Bot) == nil {
+			// The chat's bot is no longer configured; take its state over.
+			delete(chatKeys, owner)
+		} else if chatID < 0 {
+			chatBotsMu.Unlock()
+			return 0, false
+		} else {
+			key = nextSyntheticChatKey()
+		}
+	}
+	chatKeys[bc] = key
+	keyChats[key] = bc
+	chatBotsMu.Unlock()
+
+	saveChatBots()
+	return key, true
+}
+
+// nextSyntheticChatKey returns an unused synthetic key. chatBotsMu must be
+// held.
+func nextSyntheticChatKey() int64 {
+	key := syntheticChatKeyBase
+	for k := range keyChats {
+		if k >= key {
+			key = k + 1
+		}
+	}
+	return key
+}
+
+// chatOwner returns the username of the bot the Telegram chat was first
+// bound to.
+func chatOwner(chatID int64) string {
+	chatBotsMu.Lock()
+	defer chatBotsMu.Unlock()
+	return keyChats[chatID].Bot
+}
+
+// send sends c through b to the Telegram chat behind the chat key it was
+// built with.
+func send(b *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return b.Send(addressed(c))
+}
+
+// request is send for requests whose result isn't a message.
+func request(b *tgbotapi.BotAPI, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return b.Request(addressed(c))
+}
+
+// addressed replaces the chat key in c with its Telegram chat ID.
+func addressed(c tgbotapi.Chattable) tgbotapi.Chattable {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		m.ChatID = telegramChatID(m.ChatID)
+		return m
+	case tgbotapi.DocumentConfig:
+		m.ChatID = telegramChatID(m.ChatID)
+		return m
+	case tgbotapi.EditMessageTextConfig:
+		m.ChatID = telegramChatID(m.ChatID)
+		return m
+	case tgbotapi.PinChatMessageConfig:
+		m.ChatID = telegramChatID(m.ChatID)
+		return m
+	}
+	return c
+}
+
+func saveChatBots() {
+	chatBotsMu.Lock()
+	bindings := make([]chatBinding, 0, len(chatKeys))
+	for bc, key := range chatKeys {
+		bindings = append(bindings, chatBinding{Bot: bc.Bot, Chat: bc.Chat, Key: key})
+	}
+	chatBotsMu.Unlock()
+
+	data, err := json.Marshal(bindings)
+	if err != nil {
+		log.Printf("Error marshaling chat bots: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(chatBotsFile), data, 0644); err != nil {
+		log.Printf("Error saving chat bots: %v", err)
+	}
+}
+
+// loadChatBots restores the chat bindings. Files written before bots could
+// share a private chat map each chat ID to its bot's username.
+func loadChatBots() {
+	data, err := os.ReadFile(dataPath(chatBotsFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading chat bots file: %v", err)
+		}
+		return
+	}
+
+	var bindings []chatBinding
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		legacy := make(map[int64]string)
+		if legacyErr := json.Unmarshal(data, &legacy); legacyErr != nil {
+			log.Printf("Error unmarshaling chat bots: %v", err)
+			return
+		}
+		for chatID, username := range legacy {
+			bindings = append(bindings, chatBinding{Bot: username, Chat: chatID, Key: chatID})
+		}
+	}
+
+	keys := make(map[botChat]int64, len(bindings))
+	chats := make(map[int64]botChat, len(bindings))
+	for _, binding := range bindings {
+		bc := botChat{binding.Bot, binding.Chat}
+		keys[bc] = binding.Key
+		chats[binding.Key] = bc
+	}
+
+	chatBotsMu.Lock()
+	chatKeys, keyChats = keys, chats
+	chatBotsMu.Unlock()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// useTestBots installs bots with the given usernames and empty bindings.
+func useTestBots(t *testing.T, usernames ...string) []*tgbotapi.BotAPI {
+	t.Helper()
+	oldBots, oldBot := bots, bot
+	chatBotsMu.Lock()
+	oldKeys, oldChats := chatKeys, keyChats
+	chatKeys, keyChats = make(map[botChat]int64), make(map[int64]botChat)
+	chatBotsMu.Unlock()
+	t.Cleanup(func() {
+		bots, bot = oldBots, oldBot
+		chatBotsMu.Lock()
+		chatKeys, keyChats = oldKeys, oldChats
+		chatBotsMu.Unlock()
+		os.Remove(dataPath(chatBotsFile))
+	})
+
+	bots = nil
+	for _, username := range usernames {
+		bots = append(bots, &tgbotapi.BotAPI{Self: tgbotapi.User{UserName: username}})
+	}
+	bot = bots[0]
+	return bots
+}
+
+func TestClaimChatPrivateChatPerBot(t *testing.T) {
+	test := useTestBots(t, "alpha_bot", "beta_bot")
+	alpha, beta := test[0], test[1]
+	const userID int64 = 4242
+
+	alphaKey, ok := claimChat(userID, alpha)
+	if !ok || alphaKey != userID {
+		t.Fatalf("first bot claimChat = %d, %v, want %d, true", alphaKey, ok, userID)
+	}
+	betaKey, ok := claimChat(userID, beta)
+	if !ok {
+		t.Fatal("second bot refused the user's private chat")
+	}
+	if betaKey == alphaKey || betaKey < syntheticChatKeyBase {
+		t.Fatalf("second bot key = %d, want a synthetic key distinct from %d", betaKey, alphaKey)
+	}
+	if again, _ := claimChat(userID, beta); again != betaKey {
+		t.Errorf("second claim returned key %d, want %d", again, betaKey)
+	}
+
+	if got := botFor(alphaKey); got != alpha {
+		t.Errorf("botFor(alpha key) = @%s, want @alpha_bot", got.Self.UserName)
+	}
+	if got := botFor(betaKey); got != beta {
+		t.Errorf("botFor(beta key) = @%s, want @beta_bot", got.Self.UserName)
+	}
+	for _, key := range []int64{alphaKey, betaKey} {
+		if got := telegramChatID(key); got != userID {
+			t.Errorf("telegramChatID(%d) = %d, want %d", key, got, userID)
+		}
+	}
+	msg := addressed(tgbotapi.NewMessage(betaKey, "hi")).(tgbotapi.MessageConfig)
+	if msg.ChatID != userID {
+		t.Errorf("addressed message goes to %d, want %d", msg.ChatID, userID)
+	}
+}
+
+func TestClaimChatIsolatesState(t *testing.T) {
+	test := useTestBots(t, "alpha_bot", "beta_bot")
+	const userID int64 = 4343
+	alphaKey, _ := claimChat(userID, test[0])
+	betaKey, _ := claimChat(userID, test[1])
+	t.Cleanup(func() {
+		chatConfigsMu.Lock()
+		delete(chatConfigs, alphaKey)
+		delete(chatConfigs, betaKey)
+		chatConfigsMu.Unlock()
+	})
+
+	updateChatConfig(betaKey, func(cfg *ChatConfig) { cfg.Threshold = 9 })
+	if got := getChatConfig(alphaKey).Threshold; got == 9 {
+		t.Error("a setting made through the second bot changed the first bot's chat")
+	}
+	if got := getChatConfig(betaKey).Threshold; got != 9 {
+		t.Errorf("second bot's threshold = %g, want 9", got)
+	}
+}
+
+func TestClaimChatGroupStaysWithOneBot(t *testing.T) {
+	test := useTestBots(t, "alpha_bot", "beta_bot")
+	const groupID int64 = -100123
+
+	if key, ok := claimChat(groupID, test[0]); !ok || key != groupID {
+		t.Fatalf("first bot claimChat = %d, %v, want %d, true", key, ok, groupID)
+	}
+	if _, ok := claimChat(groupID, test[1]); ok {
+		t.Error("second bot may serve a group the first bot owns")
+	}
+	if got := chatOwner(groupID); got != "alpha_bot" {
+		t.Errorf("chatOwner = %q, want alpha_bot", got)
+	}
+}
+
+func TestChatBotsRoundTrip(t *testing.T) {
+	test := useTestBots(t, "alpha_bot", "beta_bot")
+	const userID int64 = 4444
+	claimChat(userID, test[0])
+	betaKey, _ := claimChat(userID, test[1])
+
+	chatBotsMu.Lock()
+	chatKeys, keyChats = make(map[botChat]int64), make(map[int64]botChat)
+	chatBotsMu.Unlock()
+	loadChatBots()
+
+	if key, _ := claimChat(userID, test[1]); key != betaKey {
+		t.Errorf("reloaded key = %d, want %d", key, betaKey)
+	}
+}
+
+func TestLoadChatBotsLegacyFormat(t *testing.T) {
+	test := useTestBots(t, "alpha_bot", "beta_bot")
+	if err := os.WriteFile(dataPath(chatBotsFile), []byte(`{"4545":"beta_bot"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadChatBots()
+
+	if key, ok := claimChat(4545, test[1]); !ok || key != 4545 {
+		t.Errorf("legacy binding claimChat = %d, %v, want 4545, true", key, ok)
+	}
+	if got := botFor(4545); got != test[1] {
+		t.Errorf("botFor(4545) = @%s, want @beta_bot", got.Self.UserName)
+	}
+}
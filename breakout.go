@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minBreakoutLookback = 2
+	maxBreakoutLookback = 500
+)
+
+// breakoutSent remembers the candle each symbol last broke out on so a
+// breakout is reported once per candle.
+var (
+	breakoutSent   = make(map[alertKey]time.Time)
+	breakoutSentMu sync.Mutex
+)
+
+// breakoutBand returns the highest high and lowest low of klines.
+func breakoutBand(klines []BinanceKline) (high, low float64, err error) {
+	if len(klines) == 0 {
+		return 0, 0, fmt.Errorf("no klines")
+	}
+	for i, kline := range klines {
+		h, err := klineFloat(kline, 2)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid high: %v", err)
+		}
+		l, err := klineFloat(kline, 3)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid low: %v", err)
+		}
+		if i == 0 || h > high {
+			high = h
+		}
+		if i == 0 || l < low {
+			low = l
+		}
+	}
+	return high, low, nil
+}
+
+// checkBreakouts alerts when a symbol's latest close leaves the high/low
+// band of the previous cfg.BreakoutLookback candles. With BreakoutVolume
+// set, the candle's volume ratio must also exceed the threshold. The latest
+// candle is the one the volume ratio compares: the last closed one unless
+// the chat uses live candles.
+func checkBreakouts(chatID int64, cfg ChatConfig, results []symbolVolume) {
+	if cfg.BreakoutLookback == 0 {
+		return
+	}
+	closed := comparesClosed(cfg)
+
+	for _, result := range results {
+		if cfg.BreakoutVolume && result.Data.Ratio <= symbolThreshold(cfg, result.Symbol) {
+			continue
+		}
+
+		klines, err := getBinanceKlines(result.Symbol, candlesNeeded(cfg.BreakoutLookback+1, closed))
+		if err != nil {
+			log.Printf("Error getting kline data for %s: %v\n", result.Symbol, err)
+			continue
+		}
+		klines = comparedKlines(klines, closed)
+		if len(klines) < cfg.BreakoutLookback+1 {
+			continue
+		}
+
+		latest := klines[len(klines)-1]
+		high, low, err := breakoutBand(klines[:len(klines)-1])
+		if err != nil {
+			log.Printf("Error computing breakout band for %s: %v\n", result.Symbol, err)
+			continue
+		}
+		closePrice, err := klineFloat(latest, 4)
+		if err != nil {
+			log.Printf("Error parsing close price for %s: %v\n", result.Symbol, err)
+			continue
+		}
+
+		var message string
+		switch {
+		case closePrice > high:
+			message = fmt.Sprintf("🚀 %s broke out above its %d-candle high %g (close %g, volume %s)",
+				result.Symbol, cfg.BreakoutLookback, high, closePrice, formatRatio(result.Data.Ratio, cfg.Precision))
+		case closePrice < low:
+			message = fmt.Sprintf("🔻 %s broke down below its %d-candle low %g (close %g, volume %s)",
+				result.Symbol, cfg.BreakoutLookback, low, closePrice, formatRatio(result.Data.Ratio, cfg.Precision))
+		default:
+			continue
+		}
+
+		key := alertKey{chatID, result.Symbol}
+		openTime := time.UnixMilli(klineOpenTime(latest))
+		breakoutSentMu.Lock()
+		sent := breakoutSent[key].Equal(openTime)
+		breakoutSent[key] = openTime
+		breakoutSentMu.Unlock()
+		if sent {
+			continue
+		}
+
+		queueMessage(chatID, message, cfg.Silent)
+	}
+}
+
+func handleBreakoutCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+
+	if len(fields) == 1 && fields[0] == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.BreakoutLookback = 0
+			cfg.BreakoutVolume = false
+		})
+		return "Breakout alerts disabled."
+	}
+
+	if len(fields) == 0 || len(fields) > 2 || (len(fields) == 2 && fields[1] != "volume") {
+		return "Usage: /breakout <candles> [volume], or /breakout off"
+	}
+
+	lookback, err := strconv.Atoi(fields[0])
+	if err != nil || lookback < minBreakoutLookback || lookback > maxBreakoutLookback {
+		return fmt.Sprintf("Lookback must be a number between %d and %d", minBreakoutLookback, maxBreakoutLookback)
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.BreakoutLookback = lookback
+		cfg.BreakoutVolume = len(fields) == 2
+	})
+	if cfg.BreakoutVolume {
+		return fmt.Sprintf("Alerting when the close breaks the %d-candle range with volume above %gx", lookback, cfg.Threshold)
+	}
+	return fmt.Sprintf("Alerting when the close breaks the %d-candle range", lookback)
+}
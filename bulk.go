@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bulkTickerTTL lets chats scanning at the same time share one bulk
+// request.
+const bulkTickerTTL = 30 * time.Second
+
+// ticker24h is one entry of Binance's /api/v3/ticker/24hr, which returns
+// every symbol's rolling 24h statistics when called without a symbol.
+type ticker24h struct {
+	Symbol             string `json:"symbol"`
+	PriceChangePercent string `json:"priceChangePercent"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
+	LastPrice          string `json:"lastPrice"`
+	Volume             string `json:"volume"`
+	QuoteVolume        string `json:"quoteVolume"`
+	OpenTime           int64  `json:"openTime"`
+	Count              int64  `json:"count"`
+}
+
+// prevDayVolume is a symbol's last completed daily candle.
+type prevDayVolume struct {
+	OpenTime time.Time
+	Volume   float64
+	Trades   float64
+}
+
+var (
+	bulkTickers          map[string]ticker24h
+	bulkTickersFetchedAt time.Time
+	bulkTickersMu        sync.Mutex
+
+	prevDayVolumes   = make(map[string]prevDayVolume)
+	prevDayVolumesMu sync.Mutex
+)
+
+// parseBulkTickers decodes a /api/v3/ticker/24hr response into a map keyed
+// by symbol. Entries that don't decode or have no symbol are skipped, so
+// one bad entry only costs its own symbol; those symbols then count as not
+// listed.
+func parseBulkTickers(r io.Reader) (map[string]ticker24h, error) {
+	var list []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	tickers := make(map[string]ticker24h, len(list))
+	for _, raw := range list {
+		var t ticker24h
+		if err := json.Unmarshal(raw, &t); err != nil || t.Symbol == "" {
+			continue
+		}
+		tickers[t.Symbol] = t
+	}
+	return tickers, nil
+}
+
+// getBulkTickers returns the 24h statistics of every Binance symbol from a
+// single request.
+func getBulkTickers() (map[string]ticker24h, error) {
+	bulkTickersMu.Lock()
+	defer bulkTickersMu.Unlock()
+
+	if bulkTickers != nil && time.Since(bulkTickersFetchedAt) < bulkTickerTTL {
+		return bulkTickers, nil
+	}
+
+	acquireFetchSlot()
+	defer releaseFetchSlot()
+
+	resp, err := httpClient.Get(binanceBaseURL + "/api/v3/ticker/24hr")
+	if err != nil {
+		return nil, requestError("binance 24h tickers", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError("binance 24h tickers", resp); err != nil {
+		return nil, err
+	}
+
+	tickers, err := parseBulkTickers(resp.Body)
+	if err != nil {
+		return nil, decodeError("binance 24h tickers", err)
+	}
+
+	bulkTickers = tickers
+	bulkTickersFetchedAt = time.Now()
+	return bulkTickers, nil
+}
+
+// getPrevDayVolume returns the symbol's last completed daily candle. It is
+// fetched once per day per symbol and reused until the next day closes.
+func getPrevDayVolume(symbol string, now time.Time) (prevDayVolume, error) {
+	prevDayVolumesMu.Lock()
+	cached, ok := prevDayVolumes[symbol]
+	prevDayVolumesMu.Unlock()
+	if ok && now.Before(cached.OpenTime.Add(48*time.Hour)) {
+		return cached, nil
+	}
+
+	klines, err := getBinanceKlinesInterval(symbol, "1d", 2)
+	if err != nil {
+		return prevDayVolume{}, err
+	}
+	if len(klines) < 2 {
+		return prevDayVolume{}, fmt.Errorf("insufficient kline data")
+	}
+
+	prev := klines[0]
+	volume, err := klineFloat(prev, 5)
+	if err != nil {
+		return prevDayVolume{}, fmt.Errorf("invalid previous volume: %v", err)
+	}
+	trades, err := klineFloat(prev, 8)
+	if err != nil {
+		return prevDayVolume{}, fmt.Errorf("invalid previous trades: %v", err)
+	}
+
+	day := prevDayVolume{OpenTime: time.UnixMilli(klineOpenTime(prev)), Volume: volume, Trades: trades}
+	prevDayVolumesMu.Lock()
+	prevDayVolumes[symbol] = day
+	prevDayVolumesMu.Unlock()
+	return day, nil
+}
+
+// dailySupported reports whether the daily comparison can use the bulk
+// ticker for metric. Taker buy volume is not part of the ticker.
+func dailySupported(metric string) bool {
+	return metric != metricTakerBuy
+}
+
+// getDailyVolume compares the symbol's rolling 24h volume (or trade
+// count), taken from the bulk ticker, against the previous daily candle.
+func getDailyVolume(symbol, metric string, tickers map[string]ticker24h, now time.Time) (*VolumeData, error) {
+	t, ok := tickers[symbol]
+	if !ok {
+		return nil, &FetchError{Source: "binance 24h tickers", Kind: ErrSymbolNotFound, StatusCode: http.StatusOK}
+	}
+
+	prev, err := getPrevDayVolume(symbol, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var prevValue, currValue float64
+	if metric == metricTrades {
+		prevValue, currValue = prev.Trades, float64(t.Count)
+	} else {
+		prevValue = prev.Volume
+		if currValue, err = strconv.ParseFloat(t.Volume, 64); err != nil {
+			return nil, fmt.Errorf("invalid 24h volume: %v", err)
+		}
+	}
+	if prevValue == 0 {
+		return nil, nil
+	}
+
+	quoteVolume, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+	priceChangePct, _ := strconv.ParseFloat(t.PriceChangePercent, 64)
+	high, _ := strconv.ParseFloat(t.HighPrice, 64)
+	low, _ := strconv.ParseFloat(t.LowPrice, 64)
+	last, _ := strconv.ParseFloat(t.LastPrice, 64)
+
+	return &VolumeData{
+		PrevVolume:     prevValue,
+		CurrVolume:     currValue,
+		Ratio:          currValue / prevValue,
+		OpenTime:       time.UnixMilli(t.OpenTime),
+		QuoteVolume:    quoteVolume,
+		PriceChangePct: priceChangePct,
+		TypicalPrice:   (high + low + last) / 3,
+	}, nil
+}
+
+func handleDailyCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Daily = true
+		})
+		if !dailySupported(cfg.Metric) {
+			return "Daily comparison enabled, but taker buy volume isn't in the 24h ticker, so hourly candles are still used until you switch /metric."
+		}
+		return "Comparing each symbol's rolling 24h volume against the previous day."
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Daily = false
+		})
+		return fmt.Sprintf("Comparing %s candles again.", klineInterval)
+	default:
+		return "Usage: /daily on|off"
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const bulkTickerFixture = `[
+	{"symbol":"BTCUSDT","priceChangePercent":"1.5","highPrice":"66000","lowPrice":"64000","lastPrice":"65000","volume":"12345.6","quoteVolume":"802464000","openTime":1767000000000,"count":987654},
+	{"symbol":"BADUSDT","volume":"1","count":"not a number"},
+	{"priceChangePercent":"2","volume":"5","count":1},
+	{"symbol":"ETHUSDT","priceChangePercent":"-0.3","highPrice":"3100","lowPrice":"2900","lastPrice":"3000","volume":"45678","quoteVolume":"137034000","openTime":1767000000000,"count":123456}
+]`
+
+func TestParseBulkTickers(t *testing.T) {
+	tickers, err := parseBulkTickers(strings.NewReader(bulkTickerFixture))
+	if err != nil {
+		t.Fatalf("parseBulkTickers returned error: %v", err)
+	}
+
+	if len(tickers) != 2 {
+		t.Errorf("parsed %d tickers, want 2 without the malformed and symbol-less entries: %v", len(tickers), tickers)
+	}
+	if _, ok := tickers["BADUSDT"]; ok {
+		t.Error("malformed entry was kept")
+	}
+	if _, ok := tickers[""]; ok {
+		t.Error("entry without a symbol was kept")
+	}
+	btc := tickers["BTCUSDT"]
+	if btc.Volume != "12345.6" || btc.Count != 987654 || btc.OpenTime != 1767000000000 || btc.LastPrice != "65000" {
+		t.Errorf("BTCUSDT = %+v", btc)
+	}
+	if tickers["ETHUSDT"].PriceChangePercent != "-0.3" {
+		t.Errorf("ETHUSDT = %+v", tickers["ETHUSDT"])
+	}
+}
+
+func TestParseBulkTickersNotAList(t *testing.T) {
+	for _, body := range []string{`{"code":-1003,"msg":"Too many requests"}`, `[{"symbol":`, ``} {
+		if _, err := parseBulkTickers(strings.NewReader(body)); err == nil {
+			t.Errorf("parseBulkTickers(%q) returned no error", body)
+		}
+	}
+}
+
+func TestDailyVolumeMissingSymbol(t *testing.T) {
+	tickers, err := parseBulkTickers(strings.NewReader(bulkTickerFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, symbol := range []string{"BADUSDT", "SOLUSDT"} {
+		if _, err := getDailyVolume(symbol, metricVolume, tickers, time.Now()); !errors.Is(err, ErrSymbolNotFound) {
+			t.Errorf("getDailyVolume(%s) error = %v, want ErrSymbolNotFound", symbol, err)
+		}
+	}
+}
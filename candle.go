@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// klineInterval is the candle interval requested from Binance.
+const klineInterval = "1h"
+
+// intervalDuration converts a Binance kline interval such as "15m", "4h" or
+// "1d" to its duration. It returns 0 for intervals it does not understand.
+func intervalDuration(interval string) time.Duration {
+	if len(interval) < 2 {
+		return 0
+	}
+
+	n := 0
+	for _, c := range interval[:len(interval)-1] {
+		if c < '0' || c > '9' {
+			return 0
+		}
+		n = n*10 + int(c-'0')
+	}
+
+	unit := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}[interval[len(interval)-1]]
+	return time.Duration(n) * unit
+}
+
+// candleProgress returns the elapsed fraction of the candle opened at
+// openTime, clamped to [0, 1].
+func candleProgress(openTime time.Time, interval string, now time.Time) float64 {
+	d := intervalDuration(interval)
+	if d == 0 {
+		return 1
+	}
+
+	progress := float64(now.Sub(openTime)) / float64(d)
+	if progress < 0 {
+		return 0
+	}
+	if progress > 1 {
+		return 1
+	}
+	return progress
+}
+
+// klineVolumes returns the base-asset volume of each kline, oldest first.
+func klineVolumes(klines []BinanceKline) []float64 {
+	volumes := make([]float64, 0, len(klines))
+	for _, kline := range klines {
+		volume, _ := strconv.ParseFloat(kline[5].(string), 64)
+		volumes = append(volumes, volume)
+	}
+	return volumes
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+)
+
+const (
+	candleColorAny  = ""
+	candleColorUp   = "up"
+	candleColorDown = "down"
+)
+
+// candleColorMatches reports whether the alerted candle has the color the
+// chat requires: close above open for up, close below open for down.
+func candleColorMatches(color string, data *VolumeData) bool {
+	switch color {
+	case candleColorUp:
+		return data.PriceChangePct > 0
+	case candleColorDown:
+		return data.PriceChangePct < 0
+	default:
+		return true
+	}
+}
+
+func handleCandleColorCommand(chatID int64, args string) string {
+	var color string
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "up", "green":
+		color = candleColorUp
+	case "down", "red":
+		color = candleColorDown
+	case "any":
+		color = candleColorAny
+	default:
+		return "Usage: /candlecolor up|down|any"
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.CandleColor = color
+	})
+	switch color {
+	case candleColorUp:
+		return "Alerting only on green candles (close above open)."
+	case candleColorDown:
+		return "Alerting only on red candles (close below open)."
+	default:
+		return "Alerting on candles of any color."
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Candle modes choose which two candles a ratio compares. Binance returns
+// klines oldest first, and the last one is the candle still in progress.
+//
+//   - closed (the default) compares the last closed candle with the one
+//     before it. Both are final, so the ratio doesn't depend on how far the
+//     open candle has got when the scan runs. Spikes are reported once
+//     their candle has closed.
+//   - live compares the open candle with the last closed one. Spikes are
+//     caught while they happen, but early in a candle its volume is still
+//     small, which is what MIN_CANDLE_PROGRESS_PCT guards against.
+const (
+	candleModeClosed = "closed"
+	candleModeLive   = "live"
+)
+
+// comparesClosed reports whether the chat compares closed candles. Aligned
+// scans run just after a close, when the open candle has barely started,
+// so they always do.
+func comparesClosed(cfg ChatConfig) bool {
+	return cfg.CandleMode != candleModeLive || cfg.AlignScans
+}
+
+// candlesNeeded returns how many klines to fetch for n compared candles,
+// one more when the open candle is dropped.
+func candlesNeeded(n int, closed bool) int {
+	if closed {
+		return n + 1
+	}
+	return n
+}
+
+// comparedKlines drops the open candle from klines, given oldest first,
+// when closed is set, leaving the last closed candle as the current one.
+func comparedKlines(klines []BinanceKline, closed bool) []BinanceKline {
+	if closed && len(klines) > 0 {
+		return klines[:len(klines)-1]
+	}
+	return klines
+}
+
+// candleLabels names the baseline and current candles of interval in
+// alerts, e.g. "Previous 1h" and "Closed 1h".
+func candleLabels(cfg ChatConfig, interval string) (baseline, current string) {
+	if comparesClosed(cfg) {
+		return "Previous " + interval, "Closed " + interval
+	}
+	return "Previous " + interval, "Current " + interval
+}
+
+func handleCandleModeCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		cfg := getChatConfig(chatID)
+		if comparesClosed(cfg) {
+			return "Comparing the last closed candle with the one before. Usage: /candlemode closed|live"
+		}
+		return "Comparing the open candle with the last closed one. Usage: /candlemode closed|live"
+	case candleModeClosed:
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.CandleMode = ""
+		})
+		return "Comparing the last closed candle with the one before it. Spikes are reported once their candle closes."
+	case candleModeLive:
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.CandleMode = candleModeLive
+		})
+		reply := fmt.Sprintf("Comparing the open candle with the last closed one, once it is %g%% done. Spikes are caught as they happen.", currentSettings().MinCandleProgress*100)
+		if cfg.AlignScans {
+			reply += " Aligned scans still compare closed candles; use /align off to scan live."
+		}
+		return reply
+	default:
+		return "Usage: /candlemode closed|live"
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestComparedKlines(t *testing.T) {
+	klines := []BinanceKline{testKline(1, 10, 1), testKline(2, 11, 1), testKline(3, 12, 1)}
+
+	tests := []struct {
+		name     string
+		closed   bool
+		wantLen  int
+		wantLast int
+	}{
+		{"live keeps the open candle", false, 3, 3},
+		{"closed drops the open candle", true, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := comparedKlines(klines, tt.closed)
+			if len(got) != tt.wantLen {
+				t.Fatalf("comparedKlines returned %d klines, want %d", len(got), tt.wantLen)
+			}
+			if last := klineOpenTime(got[len(got)-1]); last != int64(tt.wantLast)*3600*1000 {
+				t.Errorf("latest kline opened at %d, want hour %d", last, tt.wantLast)
+			}
+		})
+	}
+
+	if got := comparedKlines(nil, true); len(got) != 0 {
+		t.Errorf("comparedKlines(nil, true) = %v, want none", got)
+	}
+	if n := candlesNeeded(21, true); len(comparedKlines(make([]BinanceKline, n), true)) != 21 {
+		t.Errorf("candlesNeeded(21, true) = %d does not leave 21 compared klines", n)
+	}
+}
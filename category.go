@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const categoryCacheTTL = 30 * time.Minute
+
+var errUnknownCategory = errors.New("unknown category")
+
+type categoryEntry struct {
+	Symbols   []string
+	FetchedAt time.Time
+}
+
+var (
+	categoryCache   = make(map[string]categoryEntry)
+	categoryCacheMu sync.Mutex
+)
+
+// getCategorySymbols returns the Binance symbols of a CoinGecko category in
+// the given CoinGecko order, served from cache while the cached list is
+// younger than categoryCacheTTL.
+func getCategorySymbols(category, order string) ([]string, error) {
+	key := category + ":" + order
+	categoryCacheMu.Lock()
+	entry, ok := categoryCache[key]
+	categoryCacheMu.Unlock()
+	if ok && time.Since(entry.FetchedAt) < categoryCacheTTL {
+		return entry.Symbols, nil
+	}
+
+	symbols, err := getMarketCapRank(category, order)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) == 0 {
+		return nil, errUnknownCategory
+	}
+
+	categoryCacheMu.Lock()
+	categoryCache[key] = categoryEntry{Symbols: symbols, FetchedAt: time.Now()}
+	categoryCacheMu.Unlock()
+
+	return symbols, nil
+}
+
+// truncatedUniverses remembers, per universe, the size MAX_SYMBOLS last
+// truncated it from, so each truncation is logged once rather than every
+// scan.
+var (
+	truncatedUniverses   = make(map[string]int)
+	truncatedUniversesMu sync.Mutex
+)
+
+// getMonitoredSymbols returns the universe of symbols scanned for cfg,
+// limited by its tier and MAX_SYMBOLS.
+func getMonitoredSymbols(cfg ChatConfig) ([]string, error) {
+	order := coinGeckoOrder(cfg.RankBy)
+
+	var symbols []string
+	var err error
+	label, universe := "top coins by "+rankLabel(cfg.RankBy), "top:"+order
+	switch {
+	case len(cfg.Watchlist) > 0:
+		symbols = cfg.Watchlist
+		label, universe = "a watchlist", "watchlist:"+strings.Join(cfg.Watchlist, ",")
+	case cfg.Category != "":
+		symbols, err = getCategorySymbols(cfg.Category, order)
+		label, universe = "category "+cfg.Category, "category:"+cfg.Category+":"+order
+	default:
+		symbols = getTopSymbols(order)
+	}
+
+	limit, capped := symbolLimit(cfg)
+	if capped && len(symbols) > limit {
+		truncatedUniversesMu.Lock()
+		if truncatedUniverses[universe] != len(symbols) {
+			truncatedUniverses[universe] = len(symbols)
+			log.Printf("MAX_SYMBOLS truncated %s from %d to %d symbols", label, len(symbols), limit)
+		}
+		truncatedUniversesMu.Unlock()
+	}
+	return limitSymbols(symbols, limit), err
+}
+
+// validCategoryID reports whether id looks like a CoinGecko category ID,
+// such as "layer-1": lowercase letters, digits and dashes.
+func validCategoryID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func handleCategoryCommand(chatID int64, args string) string {
+	category := strings.ToLower(strings.TrimSpace(args))
+
+	switch category {
+	case "":
+		if cfg := getChatConfig(chatID); cfg.Category != "" {
+			return fmt.Sprintf("Monitoring category %s. Use /category off to monitor the top coins by %s.", cfg.Category, rankLabel(cfg.RankBy))
+		}
+		return "Usage: /category <category_id>, or /category off"
+	case "off":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Category = ""
+		})
+		return fmt.Sprintf("Category filter removed. Monitoring the top coins by %s.", rankLabel(cfg.RankBy))
+	}
+	if !validCategoryID(category) {
+		return "Category IDs contain only letters, digits and dashes. Use /categories to list them."
+	}
+
+	symbols, err := getCategorySymbols(category, coinGeckoOrder(getChatConfig(chatID).RankBy))
+	if errors.Is(err, errUnknownCategory) {
+		return fmt.Sprintf("Unknown category %q. Use /categories to list the available category IDs.", category)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not fetch category %s: %v", category, err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Category = category
+	})
+	return fmt.Sprintf("Now monitoring category %s (%d coins).", category, len(symbols))
+}
+
+const (
+	categoryListTTL      = 24 * time.Hour
+	categoriesPerPage    = 50
+	categoriesListingURL = "https://api.coingecko.com/api/v3/coins/categories/list"
+)
+
+type coinGeckoCategory struct {
+	CategoryID string `json:"category_id"`
+	Name       string `json:"name"`
+}
+
+var (
+	categoryList          []coinGeckoCategory
+	categoryListFetchedAt time.Time
+	categoryListMu        sync.Mutex
+)
+
+// getCategoryList returns the CoinGecko category IDs, refreshed once a day.
+func getCategoryList() ([]coinGeckoCategory, error) {
+	categoryListMu.Lock()
+	defer categoryListMu.Unlock()
+
+	if categoryList != nil && time.Since(categoryListFetchedAt) < categoryListTTL {
+		return categoryList, nil
+	}
+
+	resp, err := httpClient.Get(categoriesListingURL)
+	if err != nil {
+		return nil, requestError("coingecko categories", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError("coingecko categories", resp); err != nil {
+		return nil, err
+	}
+
+	var categories []coinGeckoCategory
+	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		return nil, decodeError("coingecko categories", err)
+	}
+
+	categoryList = categories
+	categoryListFetchedAt = time.Now()
+	return categoryList, nil
+}
+
+func handleCategoriesCommand(args string) string {
+	page := 1
+	if arg := strings.TrimSpace(args); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return "Usage: /categories [page]"
+		}
+		page = n
+	}
+
+	categories, err := getCategoryList()
+	if err != nil {
+		return fmt.Sprintf("Could not fetch categories: %v", err)
+	}
+
+	pages := (len(categories) + categoriesPerPage - 1) / categoriesPerPage
+	if page > pages {
+		return fmt.Sprintf("Page %d does not exist, there are %d pages.", page, pages)
+	}
+
+	start := (page - 1) * categoriesPerPage
+	end := start + categoriesPerPage
+	if end > len(categories) {
+		end = len(categories)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CoinGecko categories (page %d/%d):\n", page, pages)
+	for _, category := range categories[start:end] {
+		fmt.Fprintf(&sb, "%s - %s\n", category.CategoryID, category.Name)
+	}
+	if page < pages {
+		fmt.Fprintf(&sb, "\nNext page: /categories %d", page+1)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestValidCategoryID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"layer-1", true},
+		{"decentralized-finance-defi", true},
+		{"", false},
+		{"layer-1&per_page=250", false},
+		{"layer 1", false},
+		{"../coins", false},
+		{"Layer-1", false},
+	}
+	for _, tt := range tests {
+		if got := validCategoryID(tt.id); got != tt.want {
+			t.Errorf("validCategoryID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryCommandRejectsInjection(t *testing.T) {
+	const chatID int64 = 6201
+	t.Cleanup(func() {
+		chatConfigsMu.Lock()
+		delete(chatConfigs, chatID)
+		chatConfigsMu.Unlock()
+	})
+
+	handleCategoryCommand(chatID, "layer-1&per_page=250")
+	if got := getChatConfig(chatID).Category; got != "" {
+		t.Errorf("Category = %q, want it left unset", got)
+	}
+}
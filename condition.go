@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A condition is a boolean expression over a symbol's metrics, such as
+// "ratio > 5 AND quoteVolume > 1M" or "(ratio > 10 OR priceChangePct > 8%)".
+// AND binds tighter than OR; parentheses group. Numbers accept K/M/B
+// suffixes and a trailing % (which is ignored, percentages are in points).
+type condition interface {
+	eval(metrics map[string]float64) bool
+}
+
+// conditionMetrics are the metric names a condition may reference, keyed
+// by their lower-case form.
+var conditionMetrics = map[string]string{
+	"ratio":          "ratio",
+	"quotevolume":    "quoteVolume",
+	"pricechangepct": "priceChangePct",
+}
+
+type orCondition struct{ left, right condition }
+type andCondition struct{ left, right condition }
+type comparison struct {
+	metric string
+	op     string
+	value  float64
+}
+
+func (c orCondition) eval(m map[string]float64) bool  { return c.left.eval(m) || c.right.eval(m) }
+func (c andCondition) eval(m map[string]float64) bool { return c.left.eval(m) && c.right.eval(m) }
+
+func (c comparison) eval(m map[string]float64) bool {
+	v := m[c.metric]
+	switch c.op {
+	case ">":
+		return v > c.value
+	case ">=":
+		return v >= c.value
+	case "<":
+		return v < c.value
+	case "<=":
+		return v <= c.value
+	case "==":
+		return v == c.value
+	case "!=":
+		return v != c.value
+	}
+	return false
+}
+
+// volumeMetrics exposes the fields of data that conditions can reference.
+func volumeMetrics(data *VolumeData) map[string]float64 {
+	return map[string]float64{
+		"ratio":          data.Ratio,
+		"quoteVolume":    data.QuoteVolume,
+		"priceChangePct": data.PriceChangePct,
+	}
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseCondition parses a condition expression, returning an error that
+// names the offending token when the expression is invalid.
+func parseCondition(input string) (condition, error) {
+	tokens, err := tokenizeCondition(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+
+	p := &conditionParser{tokens: tokens}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q after a complete expression", p.tokens[p.pos])
+	}
+	return cond, nil
+}
+
+func tokenizeCondition(input string) ([]string, error) {
+	var tokens []string
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case strings.ContainsRune("<>=!", r):
+			j := i + 1
+			if j < len(runes) && runes[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '%':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+				runes[j] == '.' || runes[j] == '-' || runes[j] == '%') {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *conditionParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (condition, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseTerm() (condition, error) {
+	token := p.next()
+	if token == "" {
+		return nil, fmt.Errorf("expression ends early, expected a metric")
+	}
+
+	if token == "(" {
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return cond, nil
+	}
+
+	metric, ok := conditionMetrics[strings.ToLower(token)]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q, use ratio, quoteVolume or priceChangePct", token)
+	}
+
+	op := p.next()
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	case "":
+		return nil, fmt.Errorf("expected a comparison after %s", metric)
+	default:
+		return nil, fmt.Errorf("unknown operator %q after %s, use > >= < <= == !=", op, metric)
+	}
+
+	raw := p.next()
+	if raw == "" {
+		return nil, fmt.Errorf("expected a number after %s %s", metric, op)
+	}
+	value, err := parseConditionNumber(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison{metric: metric, op: op, value: value}, nil
+}
+
+// parseConditionNumber parses numbers like 5, 1.5M, 250K or 8%.
+func parseConditionNumber(raw string) (float64, error) {
+	s := strings.TrimSuffix(raw, "%")
+	multiplier := 1.0
+	if s != "" {
+		switch unicode.ToUpper(rune(s[len(s)-1])) {
+		case 'K':
+			multiplier = 1e3
+		case 'M':
+			multiplier = 1e6
+		case 'B':
+			multiplier = 1e9
+		}
+		if multiplier != 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", raw)
+	}
+	return value * multiplier, nil
+}
+
+func handleConditionCommand(chatID int64, args string) string {
+	expr := strings.TrimSpace(args)
+	switch strings.ToLower(expr) {
+	case "":
+		if cfg := getChatConfig(chatID); cfg.Condition != "" {
+			return fmt.Sprintf("Alerting when: %s\nUse /condition off to go back to the %gx threshold.", cfg.Condition, cfg.Threshold)
+		}
+		return "Usage: /condition <expression>, e.g. /condition ratio > 5 AND quoteVolume > 1M\n" +
+			"Metrics: ratio, quoteVolume, priceChangePct. Combine with AND/OR and parentheses."
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Condition = ""
+		})
+		return "Custom condition removed."
+	}
+
+	if _, err := parseCondition(expr); err != nil {
+		return fmt.Sprintf("Invalid condition: %v", err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Condition = expr
+	})
+	return fmt.Sprintf("Alerting when: %s", expr)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"maps"
+	"os"
+	"sync"
+)
+
+// ChatConfig holds the alert settings of a single chat.
+type ChatConfig struct {
+	Threshold float64 `json:"threshold"`
+	MAType    string  `json:"ma_type,omitempty"`
+	MAWindow  int     `json:"ma_window,omitempty"`
+	Category  string  `json:"category,omitempty"`
+	RankBy    string  `json:"rank_by,omitempty"`
+	Precision int     `json:"precision"`
+	Silent    bool    `json:"silent,omitempty"`
+	Digest    string  `json:"digest,omitempty"`
+	Dedup     string  `json:"dedup,omitempty"`
+	Condition string  `json:"condition,omitempty"`
+	Metric    string  `json:"metric,omitempty"`
+	AutoPause int     `json:"auto_pause,omitempty"`
+
+	CooldownMinutes int  `json:"cooldown_minutes,omitempty"`
+	ScaledCooldown  bool `json:"scaled_cooldown,omitempty"`
+
+	CandleColor string `json:"candle_color,omitempty"`
+
+	BreakoutLookback int  `json:"breakout_lookback,omitempty"`
+	BreakoutVolume   bool `json:"breakout_volume,omitempty"`
+
+	DefaultQuote string `json:"default_quote,omitempty"`
+
+	DailySummary string `json:"daily_summary,omitempty"`
+	Timezone     string `json:"timezone,omitempty"`
+
+	MarketRelative bool `json:"market_relative,omitempty"`
+
+	Daily bool `json:"daily,omitempty"`
+
+	DisplayCurrency string `json:"display_currency,omitempty"`
+
+	Acceleration float64 `json:"acceleration,omitempty"`
+
+	Intervals []IntervalScan `json:"intervals,omitempty"`
+
+	MessageThreadID int `json:"message_thread_id,omitempty"`
+
+	AlignScans bool `json:"align_scans,omitempty"`
+
+	Sparkline bool `json:"sparkline,omitempty"`
+
+	Adaptive bool `json:"adaptive,omitempty"`
+
+	// Severity labels alerts by ratio band; nil uses defaultSeverityBands.
+	Severity []SeverityBand `json:"severity,omitempty"`
+
+	// AlertPer is "" to alert per symbol or "base" to alert once per base
+	// asset; see perbase.go.
+	AlertPer string `json:"alert_per,omitempty"`
+
+	// CandleMode is "" for closed candles or "live"; see candlemode.go.
+	CandleMode string `json:"candle_mode,omitempty"`
+
+	// MinVolume is the minimum quote volume of the current candle, keyed by
+	// quote asset and in units of that asset.
+	MinVolume map[string]float64 `json:"min_volume,omitempty"`
+
+	// SymbolThresholds overrides Threshold for individual symbols.
+	SymbolThresholds map[string]float64 `json:"symbol_thresholds,omitempty"`
+
+	MergeWindowSeconds int `json:"merge_window_seconds,omitempty"`
+
+	// BoostFactor multiplies every threshold until BoostUntil, a Unix time.
+	BoostFactor float64 `json:"boost_factor,omitempty"`
+	BoostUntil  int64   `json:"boost_until,omitempty"`
+
+	NewListings bool `json:"new_listings,omitempty"`
+
+	// Watchlist replaces the top-coin or category universe when set.
+	Watchlist []string `json:"watchlist,omitempty"`
+
+	// Tier is set by the admin with /tier and is not imported or exported.
+	Tier string `json:"tier,omitempty"`
+
+	// WashTrading is "flag" or "suppress" to apply the wash-trading
+	// heuristic with the given parameters.
+	WashTrading       string  `json:"wash_trading,omitempty"`
+	WashMaxRangePct   float64 `json:"wash_max_range_pct,omitempty"`
+	WashMaxTradeShare float64 `json:"wash_max_trade_share,omitempty"`
+
+	// RangeSpike alerts when a candle's high-low range exceeds this multiple
+	// of the average range over RangeSpikeLookback candles.
+	RangeSpike         float64 `json:"range_spike,omitempty"`
+	RangeSpikeLookback int     `json:"range_spike_lookback,omitempty"`
+
+	// RVOL alerts when a candle's metric exceeds this multiple of the
+	// average candle at the same time of day over the past RVOLDays days.
+	RVOL     float64 `json:"rvol,omitempty"`
+	RVOLDays int     `json:"rvol_days,omitempty"`
+
+	TrackEffectiveness bool `json:"track_effectiveness,omitempty"`
+
+	Dashboard          bool `json:"dashboard,omitempty"`
+	DashboardMessageID int  `json:"dashboard_message_id,omitempty"`
+
+	WebhookURL    string `json:"webhook_url,omitempty"`
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+
+	Email string `json:"email,omitempty"`
+
+	CrossAlerts []CrossAlert `json:"cross_alerts,omitempty"`
+
+	Baskets []Basket `json:"baskets,omitempty"`
+
+	Priority []string `json:"priority,omitempty"`
+}
+
+const (
+	configFile       = "chat_config.json"
+	defaultThreshold = 5.0
+)
+
+var (
+	chatConfigs   = make(map[int64]*ChatConfig)
+	chatConfigsMu sync.RWMutex
+)
+
+func defaultChatConfig() ChatConfig {
+	return ChatConfig{
+		Threshold: defaultThreshold,
+		Precision: defaultPrecision,
+	}
+}
+
+// clone returns a copy of cfg that shares no slices or maps with it.
+func (cfg ChatConfig) clone() ChatConfig {
+	cfg.CrossAlerts = append([]CrossAlert(nil), cfg.CrossAlerts...)
+	cfg.Priority = append([]string(nil), cfg.Priority...)
+	cfg.Watchlist = append([]string(nil), cfg.Watchlist...)
+	cfg.Severity = append([]SeverityBand(nil), cfg.Severity...)
+	cfg.Baskets = cloneBaskets(cfg.Baskets)
+	cfg.Intervals = append([]IntervalScan(nil), cfg.Intervals...)
+	cfg.MinVolume = maps.Clone(cfg.MinVolume)
+	cfg.SymbolThresholds = maps.Clone(cfg.SymbolThresholds)
+	return cfg
+}
+
+// getChatConfig returns a copy of the chat's config, or the defaults if the
+// chat has never changed a setting.
+func getChatConfig(chatID int64) ChatConfig {
+	chatConfigsMu.RLock()
+	defer chatConfigsMu.RUnlock()
+
+	if cfg, ok := chatConfigs[chatID]; ok {
+		return cfg.clone()
+	}
+	return defaultChatConfig()
+}
+
+// allChatConfigs returns a copy of every stored chat config.
+func allChatConfigs() map[int64]ChatConfig {
+	chatConfigsMu.RLock()
+	defer chatConfigsMu.RUnlock()
+
+	configs := make(map[int64]ChatConfig, len(chatConfigs))
+	for chatID, cfg := range chatConfigs {
+		configs[chatID] = cfg.clone()
+	}
+	return configs
+}
+
+// updateChatConfig applies fn to the chat's config, persists the result and
+// returns the updated copy.
+func updateChatConfig(chatID int64, fn func(cfg *ChatConfig)) ChatConfig {
+	chatConfigsMu.Lock()
+	cfg, ok := chatConfigs[chatID]
+	if !ok {
+		def := defaultChatConfig()
+		cfg = &def
+		chatConfigs[chatID] = cfg
+	}
+	fn(cfg)
+	updated := cfg.clone()
+	chatConfigsMu.Unlock()
+
+	saveChatConfigs()
+	return updated
+}
+
+func saveChatConfigs() {
+	chatConfigsMu.RLock()
+	data, err := json.Marshal(chatConfigs)
+	chatConfigsMu.RUnlock()
+	if err != nil {
+		log.Printf("Error marshaling chat configs: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(configFile), data, 0644); err != nil {
+		log.Printf("Error saving chat configs: %v", err)
+	}
+}
+
+func loadChatConfigs() {
+	data, err := os.ReadFile(dataPath(configFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading chat config file: %v", err)
+		}
+		return
+	}
+
+	raw := make(map[int64]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		log.Printf("Error unmarshaling chat configs: %v", err)
+		return
+	}
+
+	// Decode each entry over the defaults so settings added after the file
+	// was written keep their default values.
+	configs := make(map[int64]*ChatConfig, len(raw))
+	for chatID, entry := range raw {
+		cfg := defaultChatConfig()
+		if err := json.Unmarshal(entry, &cfg); err != nil {
+			log.Printf("Error unmarshaling config for chat %d: %v", chatID, err)
+			continue
+		}
+		configs[chatID] = &cfg
+	}
+
+	chatConfigsMu.Lock()
+	chatConfigs = configs
+	chatConfigsMu.Unlock()
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxConfigFileSize bounds the size of an imported config document.
+const maxConfigFileSize = 64 * 1024
+
+// validate checks that every setting holds a value the commands could have
+// produced.
+func (cfg ChatConfig) validate() error {
+	if cfg.Threshold <= 1 {
+		return fmt.Errorf("threshold must be greater than 1")
+	}
+	if cfg.MAType != "" {
+		if cfg.MAType != maTypeSMA && cfg.MAType != maTypeEMA {
+			return fmt.Errorf("ma_type must be %q or %q", maTypeSMA, maTypeEMA)
+		}
+		if cfg.MAWindow < minMAWindow || cfg.MAWindow > maxMAWindow {
+			return fmt.Errorf("ma_window must be between %d and %d", minMAWindow, maxMAWindow)
+		}
+	}
+	if cfg.Category != "" && !validCategoryID(cfg.Category) {
+		return fmt.Errorf("category must contain only lowercase letters, digits and dashes")
+	}
+	switch cfg.RankBy {
+	case "", rankByVolume:
+	default:
+		return fmt.Errorf("rank_by must be %q", rankByVolume)
+	}
+	if cfg.Precision < 0 || cfg.Precision > maxPrecision {
+		return fmt.Errorf("precision must be between 0 and %d", maxPrecision)
+	}
+	switch cfg.Digest {
+	case digestOff, digestFlat, digestGrouped:
+	default:
+		return fmt.Errorf("digest must be %q or %q", digestFlat, digestGrouped)
+	}
+	switch cfg.Dedup {
+	case "", dedupCandle:
+	default:
+		return fmt.Errorf("dedup must be %q", dedupCandle)
+	}
+	switch cfg.Metric {
+	case "", metricVolume, metricTrades, metricTakerBuy:
+	default:
+		return fmt.Errorf("metric must be %s, %s or %s", metricVolume, metricTrades, metricTakerBuy)
+	}
+	switch cfg.CandleColor {
+	case candleColorAny, candleColorUp, candleColorDown:
+	default:
+		return fmt.Errorf("candle_color must be %q or %q", candleColorUp, candleColorDown)
+	}
+	if cfg.Condition != "" {
+		if _, err := parseCondition(cfg.Condition); err != nil {
+			return fmt.Errorf("condition: %v", err)
+		}
+	}
+	if cfg.CooldownMinutes < 0 {
+		return fmt.Errorf("cooldown_minutes must not be negative")
+	}
+	if len(cfg.SymbolThresholds) > maxSymbolThresholds {
+		return fmt.Errorf("symbol_thresholds may list at most %d symbols", maxSymbolThresholds)
+	}
+	for symbol, threshold := range cfg.SymbolThresholds {
+		if threshold <= 1 {
+			return fmt.Errorf("symbol_thresholds[%s] must be greater than 1", symbol)
+		}
+	}
+	for quote, amount := range cfg.MinVolume {
+		if !validCurrencyCode(quote) || amount <= 0 {
+			return fmt.Errorf("min_volume[%s] is invalid", quote)
+		}
+	}
+	if cfg.MessageThreadID < 0 {
+		return fmt.Errorf("message_thread_id must not be negative")
+	}
+	if cfg.BoostFactor != 0 && (cfg.BoostFactor < minBoostFactor || cfg.BoostFactor > maxBoostFactor) {
+		return fmt.Errorf("boost_factor must be between %g and %g", minBoostFactor, maxBoostFactor)
+	}
+	if cfg.Acceleration < 0 {
+		return fmt.Errorf("acceleration must not be negative")
+	}
+	if cfg.AutoPause < 0 {
+		return fmt.Errorf("auto_pause must not be negative")
+	}
+	if cfg.RangeSpike != 0 && cfg.RangeSpike <= 1 {
+		return fmt.Errorf("range_spike must be greater than 1")
+	}
+	if cfg.RangeSpikeLookback != 0 && (cfg.RangeSpikeLookback < minRangeSpikeLookback || cfg.RangeSpikeLookback > maxRangeSpikeLookback) {
+		return fmt.Errorf("range_spike_lookback must be between %d and %d", minRangeSpikeLookback, maxRangeSpikeLookback)
+	}
+	if cfg.RVOL != 0 && cfg.RVOL <= 1 {
+		return fmt.Errorf("rvol must be greater than 1")
+	}
+	if cfg.RVOLDays != 0 && (cfg.RVOLDays < minRVOLDays || cfg.RVOLDays > maxRVOLDays) {
+		return fmt.Errorf("rvol_days must be between %d and %d", minRVOLDays, maxRVOLDays)
+	}
+	if cfg.BreakoutLookback != 0 && (cfg.BreakoutLookback < minBreakoutLookback || cfg.BreakoutLookback > maxBreakoutLookback) {
+		return fmt.Errorf("breakout_lookback must be between %d and %d", minBreakoutLookback, maxBreakoutLookback)
+	}
+	if cfg.DailySummary != "" {
+		if _, err := time.Parse(dailySummaryLayout, cfg.DailySummary); err != nil {
+			return fmt.Errorf("daily_summary must be HH:MM")
+		}
+	}
+	if cfg.Timezone != "" {
+		if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+	}
+	if cfg.WebhookURL != "" {
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook_url must be an absolute http(s) URL")
+		}
+	}
+	if cfg.Email != "" {
+		if addr, err := mail.ParseAddress(cfg.Email); err != nil || addr.Address != cfg.Email {
+			return fmt.Errorf("email must be a bare email address")
+		}
+	}
+	if cfg.DisplayCurrency != "" && !validCurrencyCode(cfg.DisplayCurrency) {
+		return fmt.Errorf("display_currency must be an uppercase currency code")
+	}
+	if len(cfg.Watchlist) > maxWatchlistSymbols {
+		return fmt.Errorf("watchlist may list at most %d symbols", maxWatchlistSymbols)
+	}
+	for i, symbol := range cfg.Watchlist {
+		if symbol == "" || symbol != strings.ToUpper(symbol) {
+			return fmt.Errorf("watchlist[%d] must be an upper-case symbol", i)
+		}
+	}
+	if len(cfg.Priority) > maxPrioritySymbols {
+		return fmt.Errorf("priority may list at most %d symbols", maxPrioritySymbols)
+	}
+	if _, ok := tiers[cfg.Tier]; !ok {
+		return fmt.Errorf("tier must be %q or %q", tierFree, tierPremium)
+	}
+	switch cfg.AlertPer {
+	case alertPerSymbol, alertPerBase:
+	default:
+		return fmt.Errorf("alert_per must be %q", alertPerBase)
+	}
+	switch cfg.CandleMode {
+	case "", candleModeLive:
+	default:
+		return fmt.Errorf("candle_mode must be %q", candleModeLive)
+	}
+	switch cfg.WashTrading {
+	case washTradingOff, washTradingFlag, washTradingSuppress:
+	default:
+		return fmt.Errorf("wash_trading must be %q or %q", washTradingFlag, washTradingSuppress)
+	}
+	if cfg.WashMaxRangePct < 0 || cfg.WashMaxTradeShare < 0 || cfg.WashMaxTradeShare > 1 {
+		return fmt.Errorf("wash_max_range_pct must not be negative and wash_max_trade_share must be between 0 and 1")
+	}
+	if cfg.MergeWindowSeconds != 0 {
+		if d := time.Duration(cfg.MergeWindowSeconds) * time.Second; d < minMergeWindow || d > maxMergeWindow {
+			return fmt.Errorf("merge_window_seconds must be between %d and %d", int(minMergeWindow/time.Second), int(maxMergeWindow/time.Second))
+		}
+	}
+	if len(cfg.Intervals) > maxIntervalScans {
+		return fmt.Errorf("intervals may list at most %d scans", maxIntervalScans)
+	}
+	for i, scan := range cfg.Intervals {
+		if err := scan.validate(); err != nil {
+			return fmt.Errorf("intervals[%d]: %v", i, err)
+		}
+	}
+	if len(cfg.Severity) > maxSeverityBands {
+		return fmt.Errorf("severity may list at most %d bands", maxSeverityBands)
+	}
+	for i, band := range cfg.Severity {
+		if err := band.validate(); err != nil {
+			return fmt.Errorf("severity[%d]: %v", i, err)
+		}
+		if i > 0 && band.Min <= cfg.Severity[i-1].Min {
+			return fmt.Errorf("severity must be sorted by ratio without repeats")
+		}
+	}
+	if len(cfg.Baskets) > maxBaskets {
+		return fmt.Errorf("baskets may list at most %d baskets", maxBaskets)
+	}
+	for i, basket := range cfg.Baskets {
+		if err := basket.validate(); err != nil {
+			return fmt.Errorf("baskets[%d]: %v", i, err)
+		}
+		if findBasket(cfg.Baskets[:i], basket.Name) >= 0 {
+			return fmt.Errorf("baskets[%d]: %s is defined twice", i, basket.Name)
+		}
+	}
+	for i, alert := range cfg.CrossAlerts {
+		if alert.Symbol == "" || (alert.Side != crossSideHigh && alert.Side != crossSideLow) || alert.Level <= 0 {
+			return fmt.Errorf("cross_alerts[%d] is invalid", i)
+		}
+	}
+	return nil
+}
+
+// portableConfig returns cfg without state that only makes sense in the
+// chat it came from.
+func portableConfig(cfg ChatConfig) ChatConfig {
+	cfg.DashboardMessageID = 0
+	cfg.Tier = tierStandard
+	cfg.BoostFactor = 0
+	cfg.BoostUntil = 0
+	return cfg
+}
+
+func handleExportConfigCommand(chatID int64) {
+	data, err := json.MarshalIndent(portableConfig(getChatConfig(chatID)), "", "  ")
+	if err != nil {
+		send(botFor(chatID), tgbotapi.NewMessage(chatID, fmt.Sprintf("Could not export config: %v", err)))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("chat_config_%d.json", chatID),
+		Bytes: data,
+	})
+	doc.Caption = "Send this file back with the caption /importconfig to restore these settings."
+	if _, err := send(botFor(chatID), doc); err != nil {
+		send(botFor(chatID), tgbotapi.NewMessage(chatID, fmt.Sprintf("Could not send config: %v", err)))
+	}
+}
+
+// decodeChatConfig parses an exported config on top of the defaults,
+// rejecting unknown fields and invalid values.
+func decodeChatConfig(data []byte) (ChatConfig, error) {
+	cfg := defaultChatConfig()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return ChatConfig{}, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return ChatConfig{}, err
+	}
+	return portableConfig(cfg), nil
+}
+
+var errFileTooLarge = errors.New("file is too large")
+
+// downloadDocument fetches a document sent to the chat, failing with
+// errFileTooLarge if it exceeds maxSize bytes.
+func downloadDocument(chatID int64, doc *tgbotapi.Document, maxSize int) ([]byte, error) {
+	if doc.FileSize > maxSize {
+		return nil, errFileTooLarge
+	}
+
+	fileURL, err := botFor(chatID).GetFileDirectURL(doc.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Get(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, errFileTooLarge
+	}
+	return data, nil
+}
+
+// handleImportConfigCommand restores a config from a JSON document sent
+// with the command as its caption or replied to with the command.
+func handleImportConfigCommand(chatID int64, message *tgbotapi.Message) string {
+	doc := message.Document
+	if doc == nil && message.ReplyToMessage != nil {
+		doc = message.ReplyToMessage.Document
+	}
+	if doc == nil {
+		return "Attach an exported config file with the caption /importconfig, or reply to one with /importconfig."
+	}
+
+	data, err := downloadDocument(chatID, doc, maxConfigFileSize)
+	if errors.Is(err, errFileTooLarge) {
+		return "Config file is too large."
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not download the file: %v", err)
+	}
+
+	imported, err := decodeChatConfig(data)
+	if err != nil {
+		return fmt.Sprintf("Config not imported: %v", err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		tier := cfg.Tier
+		*cfg = imported
+		cfg.Tier = tier
+	})
+	return "Config imported."
+}
+
+// isImportConfigCaption reports whether a document was sent with the
+// /importconfig command as its caption.
+func isImportConfigCaption(message *tgbotapi.Message) bool {
+	if message.Document == nil {
+		return false
+	}
+	command := strings.Fields(message.Caption)
+	return len(command) > 0 && strings.Split(command[0], "@")[0] == "/importconfig"
+}
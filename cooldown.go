@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dedupCooldown = "cooldown"
+	dedupCandle   = "candle"
+)
+
+type alertKey struct {
+	ChatID int64
+	Symbol string
+}
+
+// alertRecord remembers the last alert sent to a chat for a symbol.
+type alertRecord struct {
+	Time       time.Time
+	Ratio      float64
+	CandleOpen time.Time
+	// Acked is set when the chat acknowledged the alert, which stops it
+	// from escalating.
+	Acked bool
+}
+
+var (
+	alertRecords   = make(map[alertKey]alertRecord)
+	alertRecordsMu sync.Mutex
+)
+
+// checkAlert reports whether symbol may alert the chat. A repeat is
+// suppressed while it is within the cooldown (or, with candle dedup, while
+// it is for the same candle as the last alert), unless it is an escalation:
+// a ratio at least EscalationFactor times the last alerted one and the last
+// alert was not acknowledged.
+func checkAlert(chatID int64, cfg ChatConfig, symbol string, data *VolumeData, now time.Time) (ok, escalated bool) {
+	alertRecordsMu.Lock()
+	defer alertRecordsMu.Unlock()
+
+	last, found := alertRecords[alertKey{chatID, symbol}]
+	if !found {
+		return true, false
+	}
+
+	if cfg.Dedup == dedupCandle {
+		if !data.OpenTime.Equal(last.CandleOpen) {
+			return true, false
+		}
+	} else if now.Sub(last.Time) >= alertCooldown(cfg, data.Ratio) {
+		return true, false
+	}
+
+	if factor := currentSettings().EscalationFactor; !last.Acked && factor > 1 && data.Ratio >= last.Ratio*factor {
+		return true, true
+	}
+	return false, false
+}
+
+// Bounds for ratio-scaled cooldowns, as multiples of the base cooldown.
+const (
+	minCooldownScale = 0.125
+	maxCooldownScale = 2
+)
+
+// baseCooldown returns the chat's cooldown, or ALERT_COOLDOWN if it has
+// not set one.
+func baseCooldown(cfg ChatConfig) time.Duration {
+	if cfg.CooldownMinutes > 0 {
+		return time.Duration(cfg.CooldownMinutes) * time.Minute
+	}
+	return currentSettings().AlertCooldown
+}
+
+// alertCooldown returns how long a symbol alerted at ratio stays quiet.
+// With scaling enabled the cooldown is base / (ratio / threshold), so a
+// spike twice the threshold may repeat after half the base cooldown. It is
+// clamped to between 1/8 and 2 times the base.
+func alertCooldown(cfg ChatConfig, ratio float64) time.Duration {
+	base := baseCooldown(cfg)
+	if !cfg.ScaledCooldown || ratio <= 0 || cfg.Threshold <= 0 {
+		return base
+	}
+
+	scale := cfg.Threshold / ratio
+	scale = max(minCooldownScale, min(maxCooldownScale, scale))
+	return time.Duration(float64(base) * scale)
+}
+
+func handleCooldownCommand(chatID int64, args string) string {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	switch arg {
+	case "":
+		cfg := getChatConfig(chatID)
+		base := baseCooldown(cfg)
+		if cfg.ScaledCooldown {
+			return fmt.Sprintf("Cooldown is %s divided by ratio/threshold, between %s and %s. Usage: /cooldown <duration>|scaled|fixed",
+				base, time.Duration(float64(base)*minCooldownScale), time.Duration(float64(base)*maxCooldownScale))
+		}
+		return fmt.Sprintf("Cooldown is %s. Usage: /cooldown <duration>|scaled|fixed", base)
+	case "scaled":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.ScaledCooldown = true
+		})
+		return fmt.Sprintf("Stronger spikes now repeat sooner: cooldown = %s / (ratio / %gx).", baseCooldown(cfg), cfg.Threshold)
+	case "fixed":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.ScaledCooldown = false
+		})
+		return fmt.Sprintf("Every symbol now waits %s between alerts.", baseCooldown(cfg))
+	}
+
+	d, err := time.ParseDuration(arg)
+	if err != nil || d < time.Minute || d > 24*time.Hour {
+		return "Cooldown must be a duration between 1m and 24h, e.g. 30m or 2h"
+	}
+
+	minutes := int(d / time.Minute)
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.CooldownMinutes = minutes
+	})
+	return fmt.Sprintf("Base cooldown set to %s.", time.Duration(minutes)*time.Minute)
+}
+
+func recordAlert(chatID int64, symbol string, data *VolumeData, now time.Time) {
+	alertRecordsMu.Lock()
+	alertRecords[alertKey{chatID, symbol}] = alertRecord{Time: now, Ratio: data.Ratio, CandleOpen: data.OpenTime}
+	alertRecordsMu.Unlock()
+}
+
+func handleDedupCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case dedupCandle:
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Dedup = dedupCandle
+		})
+		return "Each symbol alerts at most once per candle, unless its ratio keeps escalating."
+	case dedupCooldown:
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Dedup = ""
+		})
+		cfg := getChatConfig(chatID)
+		return fmt.Sprintf("Each symbol alerts at most once per %s, unless its ratio keeps escalating.", baseCooldown(cfg))
+	default:
+		return "Usage: /dedup candle|cooldown"
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCorrelationCandles = 100
+	minCorrelationCandles     = 10
+	maxCorrelationCandles     = 500
+)
+
+var errTooFewPoints = errors.New("not enough overlapping candles")
+
+// pearson returns the Pearson correlation coefficient of two equally long
+// series. It fails when there are fewer than three points or either series
+// is constant.
+func pearson(x, y []float64) (float64, error) {
+	if len(x) != len(y) {
+		return 0, fmt.Errorf("series lengths differ: %d and %d", len(x), len(y))
+	}
+	if len(x) < 3 {
+		return 0, errTooFewPoints
+	}
+
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(len(x))
+	meanY /= float64(len(y))
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, errors.New("one of the series is constant")
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
+
+// alignSeries pairs the field of two kline series by candle open time, so
+// candles missing from either side, or failing to parse, are left out.
+func alignSeries(a, b []BinanceKline, field int) (x, y []float64) {
+	byOpenTime := make(map[int64]float64, len(b))
+	for _, kline := range b {
+		if v, err := klineFloat(kline, field); err == nil {
+			byOpenTime[klineOpenTime(kline)] = v
+		}
+	}
+	for _, kline := range a {
+		v, err := klineFloat(kline, field)
+		if err != nil {
+			continue
+		}
+		if w, ok := byOpenTime[klineOpenTime(kline)]; ok {
+			x = append(x, v)
+			y = append(y, w)
+		}
+	}
+	return x, y
+}
+
+// describeCorrelation puts a coefficient into words, e.g. "strong positive".
+func describeCorrelation(r float64) string {
+	strength := "no meaningful"
+	switch abs := math.Abs(r); {
+	case abs >= 0.7:
+		strength = "strong"
+	case abs >= 0.4:
+		strength = "moderate"
+	case abs >= 0.2:
+		strength = "weak"
+	default:
+		return strength
+	}
+	if r < 0 {
+		return strength + " negative"
+	}
+	return strength + " positive"
+}
+
+func handleCorrelateCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 2 && len(fields) != 3 {
+		return fmt.Sprintf("Usage: /correlate <symbol> <symbol> [candles], e.g. /correlate BTCUSDT ETHUSDT %d", defaultCorrelationCandles)
+	}
+
+	candles := defaultCorrelationCandles
+	if len(fields) == 3 {
+		n, err := strconv.Atoi(fields[2])
+		if err != nil || n < minCorrelationCandles || n > maxCorrelationCandles {
+			return fmt.Sprintf("Candles must be between %d and %d", minCorrelationCandles, maxCorrelationCandles)
+		}
+		candles = n
+	}
+
+	var symbols [2]string
+	for i := range symbols {
+		symbol, err := resolveSymbol(chatID, fields[i])
+		if err != nil {
+			return err.Error()
+		}
+		symbols[i] = symbol
+	}
+	if symbols[0] == symbols[1] {
+		return "Pick two different symbols."
+	}
+
+	cfg := getChatConfig(chatID)
+	var series [2][]BinanceKline
+	for i, symbol := range symbols {
+		// Fetch one extra candle and drop the open one, whose volume is
+		// still growing.
+		klines, err := getBinanceKlines(symbol, candles+1)
+		if err != nil {
+			return fmt.Sprintf("Could not fetch %s: %v", symbol, err)
+		}
+		if len(klines) > 0 {
+			klines = klines[:len(klines)-1]
+		}
+		series[i] = klines
+	}
+
+	x, y := alignSeries(series[0], series[1], metricField(cfg.Metric))
+	r, err := pearson(x, y)
+	if errors.Is(err, errTooFewPoints) {
+		return fmt.Sprintf("%s and %s share too few %s candles to compare.", symbols[0], symbols[1], klineInterval)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not correlate %s and %s: %v", symbols[0], symbols[1], err)
+	}
+
+	result := fmt.Sprintf("%s correlation of %s and %s over %d %s candles: %.2f (%s)",
+		metricLabel(cfg.Metric), symbols[0], symbols[1], len(x), klineInterval, r, describeCorrelation(r))
+	if len(x) < candles {
+		result += fmt.Sprintf("\nOnly %d of %d candles overlap.", len(x), candles)
+	}
+	return result
+}
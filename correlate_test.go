@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"perfect positive", []float64{1, 2, 3, 4}, []float64{10, 20, 30, 40}, 1},
+		{"perfect negative", []float64{1, 2, 3, 4}, []float64{8, 6, 4, 2}, -1},
+		{"uncorrelated", []float64{1, 2, 3, 4}, []float64{1, -1, -1, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pearson(tt.x, tt.y)
+			if err != nil {
+				t.Fatalf("pearson returned error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("pearson(%v, %v) = %g, want %g", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPearsonErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+	}{
+		{"zero variance", []float64{5, 5, 5, 5}, []float64{1, 2, 3, 4}},
+		{"zero variance in y", []float64{1, 2, 3}, []float64{0, 0, 0}},
+		{"lengths differ", []float64{1, 2, 3}, []float64{1, 2}},
+		{"too few points", []float64{1, 2}, []float64{2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := pearson(tt.x, tt.y); err == nil {
+				t.Errorf("pearson(%v, %v) returned no error", tt.x, tt.y)
+			}
+		})
+	}
+	if _, err := pearson([]float64{1}, []float64{1}); !errors.Is(err, errTooFewPoints) {
+		t.Errorf("pearson of one point error = %v, want errTooFewPoints", err)
+	}
+}
+
+func TestAlignSeries(t *testing.T) {
+	// b lacks hour 2 and has an extra hour 5; each side holds an
+	// unparseable volume that must be left out with its pair.
+	bad := testKline(4, 10, 0)
+	bad[5] = "n/a"
+	a := []BinanceKline{testKline(1, 10, 100), testKline(2, 10, 200), testKline(3, 10, 300), bad}
+	b := []BinanceKline{testKline(3, 10, 30), testKline(1, 10, 10), testKline(4, 10, 40), testKline(5, 10, 50)}
+
+	x, y := alignSeries(a, b, metricField(metricVolume))
+	if want := []float64{100, 300}; !slices.Equal(x, want) {
+		t.Errorf("x = %v, want %v", x, want)
+	}
+	if want := []float64{10, 30}; !slices.Equal(y, want) {
+		t.Errorf("y = %v, want %v pairing by open time", y, want)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	crossSideHigh = "high"
+	crossSideLow  = "low"
+)
+
+// CrossAlert fires once when a candle's high trades above Level (side
+// "high") or its low trades below Level (side "low").
+type CrossAlert struct {
+	Symbol string  `json:"symbol"`
+	Side   string  `json:"side"`
+	Level  float64 `json:"level"`
+}
+
+func (c CrossAlert) String() string {
+	if c.Side == crossSideHigh {
+		return fmt.Sprintf("%s high above %g", c.Symbol, c.Level)
+	}
+	return fmt.Sprintf("%s low below %g", c.Symbol, c.Level)
+}
+
+// checkCrossAlerts evaluates the chat's cross alerts against the current
+// candle and removes the ones that fired.
+func checkCrossAlerts(chatID int64, cfg ChatConfig) {
+	var fired []CrossAlert
+	for _, alert := range cfg.CrossAlerts {
+		klines, err := getBinanceKlines(alert.Symbol, 1)
+		if err != nil {
+			log.Printf("Error getting kline data for %s: %v\n", alert.Symbol, err)
+			continue
+		}
+		if len(klines) == 0 {
+			continue
+		}
+
+		high, _ := strconv.ParseFloat(klines[0][2].(string), 64)
+		low, _ := strconv.ParseFloat(klines[0][3].(string), 64)
+
+		var message string
+		switch {
+		case alert.Side == crossSideHigh && high >= alert.Level:
+			message = fmt.Sprintf("📈 %s candle high %g crossed above %g", alert.Symbol, high, alert.Level)
+		case alert.Side == crossSideLow && low <= alert.Level:
+			message = fmt.Sprintf("📉 %s candle low %g crossed below %g", alert.Symbol, low, alert.Level)
+		default:
+			continue
+		}
+
+		// Once queued the alert is delivered or retried by the outbox, so
+		// it counts as fired either way.
+		queueMessage(chatID, message, cfg.Silent)
+		fired = append(fired, alert)
+	}
+
+	if len(fired) == 0 {
+		return
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		remaining := cfg.CrossAlerts[:0]
+		for _, alert := range cfg.CrossAlerts {
+			if !containsCrossAlert(fired, alert) {
+				remaining = append(remaining, alert)
+			}
+		}
+		cfg.CrossAlerts = remaining
+	})
+}
+
+func containsCrossAlert(alerts []CrossAlert, target CrossAlert) bool {
+	for _, alert := range alerts {
+		if alert == target {
+			return true
+		}
+	}
+	return false
+}
+
+func handleCrossAlertCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	usage := "Usage: /crossalert <symbol> high|low <level>, /crossalert list, or /crossalert clear"
+
+	if len(fields) == 1 {
+		switch strings.ToLower(fields[0]) {
+		case "list":
+			alerts := getChatConfig(chatID).CrossAlerts
+			if len(alerts) == 0 {
+				return "No cross alerts set."
+			}
+			var sb strings.Builder
+			sb.WriteString("Cross alerts:\n")
+			for _, alert := range alerts {
+				sb.WriteString(alert.String() + "\n")
+			}
+			return strings.TrimRight(sb.String(), "\n")
+		case "clear":
+			updateChatConfig(chatID, func(cfg *ChatConfig) {
+				cfg.CrossAlerts = nil
+			})
+			return "Cross alerts cleared."
+		}
+	}
+
+	if len(fields) != 3 {
+		return usage
+	}
+
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+
+	side := strings.ToLower(fields[1])
+	if side != crossSideHigh && side != crossSideLow {
+		return usage
+	}
+
+	level, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil || level <= 0 {
+		return "Level must be a positive number"
+	}
+
+	klines, err := getBinanceKlines(symbol, 1)
+	if err != nil && !errors.Is(err, ErrSymbolNotFound) {
+		return fmt.Sprintf("Could not fetch %s: %v", symbol, err)
+	}
+	if len(klines) == 0 {
+		return fmt.Sprintf("Symbol %s was not found on Binance", symbol)
+	}
+
+	alert := CrossAlert{Symbol: symbol, Side: side, Level: level}
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.CrossAlerts = append(cfg.CrossAlerts, alert)
+	})
+	return fmt.Sprintf("Cross alert set: %s. It is checked each scan while monitoring is running.", alert)
+}
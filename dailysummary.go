@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	// Embed the timezone database so /dailysummary works on hosts without
+	// one installed.
+	_ "time/tzdata"
+)
+
+const (
+	dailySummaryLayout = "15:04"
+	// dailySummaryTopSymbols is how many of the most frequent symbols the
+	// summary lists.
+	dailySummaryTopSymbols = 3
+)
+
+// summarySentOn remembers the local date each chat last got its summary.
+var (
+	summarySentOn   = make(map[int64]string)
+	summarySentOnMu sync.Mutex
+)
+
+// chatLocation returns the chat's timezone, falling back to UTC.
+func chatLocation(cfg ChatConfig) *time.Location {
+	if cfg.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// startDailySummaries checks once a minute whether any chat's summary time
+// has come.
+func startDailySummaries() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			for chatID, cfg := range allChatConfigs() {
+				if cfg.DailySummary == "" {
+					continue
+				}
+				local := now.In(chatLocation(cfg))
+				if local.Format(dailySummaryLayout) != cfg.DailySummary {
+					continue
+				}
+
+				date := local.Format("2006-01-02")
+				summarySentOnMu.Lock()
+				sent := summarySentOn[chatID] == date
+				summarySentOn[chatID] = date
+				summarySentOnMu.Unlock()
+				if sent {
+					continue
+				}
+
+				queueMessage(chatID, formatDailySummary(chatHistory(chatID, now.Add(-24*time.Hour)), cfg.Precision), false)
+			}
+		}
+	}()
+}
+
+// formatDailySummary recaps a day of alerts: how many there were, the
+// biggest spike and the symbols that alerted most often.
+func formatDailySummary(entries []historyEntry, precision int) string {
+	if len(entries) == 0 {
+		return "📊 Daily summary: no alerts in the last 24 hours."
+	}
+
+	biggest := entries[0]
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Ratio > biggest.Ratio {
+			biggest = entry
+		}
+		counts[entry.Symbol]++
+	}
+
+	symbols := make([]string, 0, len(counts))
+	for symbol := range counts {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if counts[symbols[i]] != counts[symbols[j]] {
+			return counts[symbols[i]] > counts[symbols[j]]
+		}
+		return symbols[i] < symbols[j]
+	})
+	if len(symbols) > dailySummaryTopSymbols {
+		symbols = symbols[:dailySummaryTopSymbols]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📊 Daily summary for the last 24 hours\n")
+	fmt.Fprintf(&sb, "Alerts: %d\n", len(entries))
+	fmt.Fprintf(&sb, "Biggest spike: %s at %s\n", biggest.Symbol, formatRatio(biggest.Ratio, precision))
+	sb.WriteString("Most frequent:\n")
+	for _, symbol := range symbols {
+		fmt.Fprintf(&sb, "%s: %d\n", symbol, counts[symbol])
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func handleDailySummaryCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	usage := "Usage: /dailysummary HH:MM [timezone], or /dailysummary off"
+
+	if len(fields) == 1 && strings.ToLower(fields[0]) == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.DailySummary = ""
+		})
+		return "Daily summary disabled."
+	}
+
+	if len(fields) == 0 || len(fields) > 2 {
+		return usage
+	}
+
+	at, err := time.Parse(dailySummaryLayout, fields[0])
+	if err != nil {
+		return usage
+	}
+
+	timezone := getChatConfig(chatID).Timezone
+	if len(fields) == 2 {
+		if _, err := time.LoadLocation(fields[1]); err != nil {
+			return fmt.Sprintf("Unknown timezone %q. Use an IANA name such as Europe/Berlin.", fields[1])
+		}
+		timezone = fields[1]
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.DailySummary = at.Format(dailySummaryLayout)
+		cfg.Timezone = timezone
+	})
+	return fmt.Sprintf("Daily summary will be sent at %s %s.", cfg.DailySummary, chatLocation(cfg))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const dashboardSize = 10
+
+func formatDashboard(results []symbolVolume, precision int, now time.Time) string {
+	sorted := append([]symbolVolume(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Data.Ratio > sorted[j].Data.Ratio })
+	if len(sorted) > dashboardSize {
+		sorted = sorted[:dashboardSize]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📊 Volume Dashboard (%s candles)\n", klineInterval)
+	if len(sorted) == 0 {
+		sb.WriteString("No data in the last scan.\n")
+	}
+	for i, result := range sorted {
+		fmt.Fprintf(&sb, "%d. %s %s\n", i+1, result.Symbol, formatRatio(result.Data.Ratio, precision))
+	}
+	fmt.Fprintf(&sb, "\nUpdated: %s", now.Format("2006-01-02 15:04:05"))
+	return sb.String()
+}
+
+// updateDashboard edits the chat's dashboard message with the latest scan,
+// posting and pinning a new one if there is none or it was deleted.
+func updateDashboard(chatID int64, cfg ChatConfig, results []symbolVolume) {
+	text := formatDashboard(results, cfg.Precision, time.Now())
+
+	if cfg.DashboardMessageID != 0 {
+		edit := tgbotapi.NewEditMessageText(chatID, cfg.DashboardMessageID, text)
+		_, err := request(botFor(chatID), edit)
+		if err == nil {
+			return
+		}
+		if !dashboardGone(err) {
+			// A rate limit or outage passes; keep the pinned message.
+			log.Printf("Error editing dashboard for chat %d: %v", chatID, err)
+			return
+		}
+		log.Printf("Dashboard for chat %d is gone, posting a new one: %v", chatID, err)
+	}
+
+	sent, err := sendChatMessage(tgbotapi.NewMessage(chatID, text))
+	if err != nil {
+		log.Printf("Error sending dashboard: %v", err)
+		return
+	}
+
+	pin := tgbotapi.PinChatMessageConfig{
+		ChatID:              chatID,
+		MessageID:           sent.MessageID,
+		DisableNotification: true,
+	}
+	if _, err := request(botFor(chatID), pin); err != nil {
+		log.Printf("Error pinning dashboard for chat %d: %v", chatID, err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.DashboardMessageID = sent.MessageID
+	})
+}
+
+// dashboardGone reports whether an edit failed because the dashboard
+// message no longer exists or can't be edited, as opposed to a failure
+// that a later edit may get past.
+func dashboardGone(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusBadRequest {
+		return false
+	}
+	description := strings.ToLower(tgErr.Message)
+	return strings.Contains(description, "message to edit not found") ||
+		strings.Contains(description, "message_id_invalid") ||
+		strings.Contains(description, "message can't be edited")
+}
+
+func handleDashboardCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Dashboard = true
+		})
+		return "Dashboard enabled. A pinned message will be updated after every scan."
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Dashboard = false
+			cfg.DashboardMessageID = 0
+		})
+		return "Dashboard disabled."
+	default:
+		return "Usage: /dashboard on|off"
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestUpdateDashboardEditFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		description string
+		want        []string
+		wantID      int
+	}{
+		{"edited", http.StatusOK, "", []string{"editMessageText"}, 77},
+		{"rate limited", http.StatusTooManyRequests, "Too Many Requests: retry after 5", []string{"editMessageText"}, 77},
+		{"server error", http.StatusInternalServerError, "", []string{"editMessageText"}, 77},
+		{"not modified", http.StatusBadRequest, "Bad Request: message is not modified", []string{"editMessageText"}, 77},
+		{"deleted", http.StatusBadRequest, "Bad Request: message to edit not found", []string{"editMessageText", "sendMessage", "pinChatMessage"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := useFakeTelegram(t, tt.status)
+			fake.failMethod, fake.description = "editMessageText", tt.description
+			const chatID int64 = 6301
+			cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+				cfg.Dashboard = true
+				cfg.DashboardMessageID = 77
+			})
+			t.Cleanup(func() {
+				chatConfigsMu.Lock()
+				delete(chatConfigs, chatID)
+				chatConfigsMu.Unlock()
+			})
+
+			updateDashboard(chatID, cfg, nil)
+			if got := fake.calls(); !slices.Equal(got, tt.want) {
+				t.Errorf("requests = %v, want %v", got, tt.want)
+			}
+			if got := getChatConfig(chatID).DashboardMessageID; got != tt.wantID {
+				t.Errorf("DashboardMessageID = %d, want %d", got, tt.wantID)
+			}
+		})
+	}
+}
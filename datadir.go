@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// dataPath returns where the persistent file name lives: inside DataDir
+// unless name is already absolute.
+func dataPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(currentSettings().DataDir, name)
+}
+
+// ensureDataDir creates DataDir if it does not exist yet.
+func ensureDataDir() {
+	if err := os.MkdirAll(currentSettings().DataDir, 0755); err != nil {
+		log.Fatalf("Error creating data directory %s: %v", currentSettings().DataDir, err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const coinGeckoPingURL = "https://api.coingecko.com/api/v3/ping"
+
+// diagnosticResult is the outcome of one connectivity check.
+type diagnosticResult struct {
+	Name       string
+	StatusCode int
+	Latency    time.Duration
+	Err        error
+}
+
+// pingURL times a GET of url.
+func pingURL(name, url string) diagnosticResult {
+	start := time.Now()
+	resp, err := httpClient.Get(url)
+	result := diagnosticResult{Name: name, Latency: time.Since(start), Err: err}
+	if err == nil {
+		result.StatusCode = resp.StatusCode
+		resp.Body.Close()
+	}
+	return result
+}
+
+// runDiagnostics checks Binance, CoinGecko and the chat's Telegram bot
+// concurrently, returning results in that order.
+func runDiagnostics(chatID int64) []diagnosticResult {
+	checks := []func() diagnosticResult{
+		func() diagnosticResult { return pingURL("Binance", binanceBaseURL+"/api/v3/ping") },
+		func() diagnosticResult { return pingURL("CoinGecko", coinGeckoPingURL) },
+		func() diagnosticResult {
+			start := time.Now()
+			_, err := botFor(chatID).GetMe()
+			return diagnosticResult{Name: "Telegram", Latency: time.Since(start), Err: err}
+		},
+	}
+
+	results := make([]diagnosticResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check func() diagnosticResult) {
+			defer wg.Done()
+			results[i] = check()
+		}(i, check)
+	}
+	wg.Wait()
+	return results
+}
+
+func handlePingCommand(chatID int64, args string) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+
+	names := make([]string, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		names = append(names, strings.ToLower(exchange.Name()))
+	}
+	usage := fmt.Sprintf("Usage: /ping %s", strings.Join(names, "|"))
+
+	name := strings.TrimSpace(args)
+	if name == "" {
+		return usage
+	}
+	for _, exchange := range exchanges {
+		if !strings.EqualFold(exchange.Name(), name) {
+			continue
+		}
+
+		url := exchange.PingURL()
+		result := pingURL(exchange.Name(), url)
+		latency := result.Latency.Round(time.Millisecond)
+		switch {
+		case result.Err != nil:
+			return fmt.Sprintf("❌ %s unreachable after %s: %v\nEndpoint: %s", result.Name, latency, result.Err, url)
+		case result.StatusCode != 200:
+			return fmt.Sprintf("⚠️ %s answered HTTP %d in %s\nEndpoint: %s", result.Name, result.StatusCode, latency, url)
+		}
+		return fmt.Sprintf("✅ %s reachable in %s\nEndpoint: %s", result.Name, latency, url)
+	}
+	return usage
+}
+
+func handleDiagnosticsCommand(chatID int64) string {
+	var sb strings.Builder
+	sb.WriteString("Diagnostics:\n")
+	for _, result := range runDiagnostics(chatID) {
+		latency := result.Latency.Round(time.Millisecond)
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(&sb, "❌ %s: %v (%s)\n", result.Name, result.Err, latency)
+		case result.StatusCode != 0 && result.StatusCode != 200:
+			fmt.Fprintf(&sb, "⚠️ %s: HTTP %d in %s\n", result.Name, result.StatusCode, latency)
+		case result.StatusCode != 0:
+			fmt.Fprintf(&sb, "✅ %s: HTTP %d in %s\n", result.Name, result.StatusCode, latency)
+		default:
+			fmt.Fprintf(&sb, "✅ %s: authorized as @%s in %s\n", result.Name, botFor(chatID).Self.UserName, latency)
+		}
+	}
+	fmt.Fprintf(&sb, "Binance endpoint: %s\n", binanceBaseURL)
+	fmt.Fprintf(&sb, "Monitoring: %s", onOff(chatIsMonitoring(chatID)))
+	return sb.String()
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	digestOff     = ""
+	digestFlat    = "flat"
+	digestGrouped = "grouped"
+
+	sectorMapTTL = 6 * time.Hour
+	otherSector  = "Other"
+)
+
+// sectorCategories are the CoinGecko categories used as digest headings, in
+// priority order for coins that belong to several of them.
+var sectorCategories = []struct {
+	ID   string
+	Name string
+}{
+	{"stablecoins", "Stablecoins"},
+	{"meme-token", "Meme"},
+	{"artificial-intelligence", "AI"},
+	{"decentralized-finance-defi", "DeFi"},
+	{"gaming", "Gaming"},
+	{"layer-2", "Layer 2"},
+	{"layer-1", "Layer 1"},
+	{"exchange-based-tokens", "Exchange Tokens"},
+}
+
+var (
+	sectorMap          map[string]string
+	sectorMapFetchedAt time.Time
+	sectorMapMu        sync.Mutex
+)
+
+// getSectorMap returns a symbol -> sector name mapping built from the
+// sector categories. Categories that fail to load are skipped; an error is
+// returned only if none could be loaded.
+func getSectorMap() (map[string]string, error) {
+	sectorMapMu.Lock()
+	defer sectorMapMu.Unlock()
+
+	if sectorMap != nil && time.Since(sectorMapFetchedAt) < sectorMapTTL {
+		return sectorMap, nil
+	}
+
+	sectors := make(map[string]string)
+	var lastErr error
+	loaded := 0
+	for _, category := range sectorCategories {
+		symbols, err := getCategorySymbols(category.ID, coinGeckoOrder(""))
+		if err != nil {
+			log.Printf("Error loading sector %s: %v", category.ID, err)
+			lastErr = err
+			continue
+		}
+		loaded++
+		for _, symbol := range symbols {
+			if _, ok := sectors[symbol]; !ok {
+				sectors[symbol] = category.Name
+			}
+		}
+	}
+	if loaded == 0 {
+		return nil, lastErr
+	}
+
+	sectorMap = sectors
+	sectorMapFetchedAt = time.Now()
+	return sectorMap, nil
+}
+
+func formatDigestLine(a Alert, precision int) string {
+	return fmt.Sprintf("%s: %s (%s → %s)", a.Symbol,
+		formatRatio(a.Data.Ratio, precision),
+		formatVolume(a.Data.PrevVolume, precision),
+		formatVolume(a.Data.CurrVolume, precision))
+}
+
+// formatDigest renders a scan's alerts as one message, grouped under sector
+// headings when sectors is non-nil.
+func formatDigest(alerts []Alert, precision int, sectors map[string]string) string {
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Data.Ratio > alerts[j].Data.Ratio })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⚠️ Volume Digest: %d symbols\n", len(alerts))
+
+	if sectors == nil {
+		for _, a := range alerts {
+			sb.WriteString(formatDigestLine(a, precision) + "\n")
+		}
+		return strings.TrimRight(sb.String(), "\n")
+	}
+
+	grouped := make(map[string][]Alert)
+	var names []string
+	for _, a := range alerts {
+		sector, ok := sectors[a.Symbol]
+		if !ok {
+			sector = otherSector
+		}
+		if _, seen := grouped[sector]; !seen {
+			names = append(names, sector)
+		}
+		grouped[sector] = append(grouped[sector], a)
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\n%s\n", name)
+		for _, a := range grouped[name] {
+			sb.WriteString(formatDigestLine(a, precision) + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// sendDigest delivers a scan's alerts to the chat as a single message.
+// Extra notifiers still receive each alert individually.
+func sendDigest(chatID int64, alerts []Alert) {
+	cfg := getChatConfig(chatID)
+
+	var sectors map[string]string
+	if cfg.Digest == digestGrouped {
+		var err error
+		if sectors, err = getSectorMap(); err != nil {
+			log.Printf("Sector data unavailable, sending flat digest: %v", err)
+		}
+	}
+
+	if err := queueMessage(chatID, formatDigest(alerts, cfg.Precision, sectors), cfg.Silent); isBlockedError(err) {
+		return
+	}
+
+	for _, a := range alerts {
+		notifyAll(cfg, a)
+	}
+}
+
+func handleDigestCommand(chatID int64, args string) string {
+	var mode string
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on", digestFlat:
+		mode = digestFlat
+	case digestGrouped:
+		mode = digestGrouped
+	case "off":
+		mode = digestOff
+	default:
+		return "Usage: /digest on|grouped|off"
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Digest = mode
+	})
+
+	switch mode {
+	case digestFlat:
+		return "Alerts will be sent as one digest per scan."
+	case digestGrouped:
+		return "Alerts will be sent as one digest per scan, grouped by sector."
+	default:
+		return "Digest disabled. Alerts will be sent individually."
+	}
+}
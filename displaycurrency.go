@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	displayRateTTL          = 10 * time.Minute
+	coinGeckoSimplePriceURL = "https://api.coingecko.com/api/v3/simple/price?ids=tether&vs_currencies="
+	maxCurrencyCodeLength   = 10
+)
+
+type displayRate struct {
+	Rate      float64
+	FetchedAt time.Time
+}
+
+var (
+	displayRates   = make(map[string]displayRate)
+	displayRatesMu sync.Mutex
+)
+
+// getDisplayRate returns how many units of currency one USDT is worth,
+// taken from CoinGecko's tether price and cached for displayRateTTL.
+func getDisplayRate(currency string) (float64, error) {
+	code := strings.ToLower(currency)
+	displayRatesMu.Lock()
+	cached, ok := displayRates[code]
+	displayRatesMu.Unlock()
+	if ok && time.Since(cached.FetchedAt) < displayRateTTL {
+		return cached.Rate, nil
+	}
+
+	resp, err := httpClient.Get(coinGeckoSimplePriceURL + code)
+	if err != nil {
+		return 0, requestError("coingecko simple price", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError("coingecko simple price", resp); err != nil {
+		return 0, err
+	}
+
+	var prices map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return 0, decodeError("coingecko simple price", err)
+	}
+	rate, ok := prices["tether"][code]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no USDT rate for %s", strings.ToUpper(code))
+	}
+
+	displayRatesMu.Lock()
+	displayRates[code] = displayRate{Rate: rate, FetchedAt: time.Now()}
+	displayRatesMu.Unlock()
+	return rate, nil
+}
+
+// applyDisplayCurrency sets the alert's conversion rate when the chat has a
+// display currency and the symbol is quoted in USDT. On a failed lookup the
+// alert is sent without the converted line.
+func applyDisplayCurrency(alert *Alert, cfg ChatConfig) {
+	if cfg.DisplayCurrency == "" || !strings.HasSuffix(alert.Symbol, defaultQuoteAsset) {
+		return
+	}
+	rate, err := getDisplayRate(cfg.DisplayCurrency)
+	if err != nil {
+		log.Printf("Error getting %s display rate: %v", cfg.DisplayCurrency, err)
+		return
+	}
+	alert.DisplayCurrency = cfg.DisplayCurrency
+	alert.DisplayRate = rate
+}
+
+func validCurrencyCode(code string) bool {
+	if code == "" || len(code) > maxCurrencyCodeLength {
+		return false
+	}
+	for _, r := range code {
+		if !unicode.IsUpper(r) || r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func handleDisplayCurrencyCommand(chatID int64, args string) string {
+	currency := strings.ToUpper(strings.TrimSpace(args))
+	switch currency {
+	case "":
+		if current := getChatConfig(chatID).DisplayCurrency; current != "" {
+			return fmt.Sprintf("Quote volumes are converted to %s (approximate). Use /displaycurrency USDT to turn it off.", current)
+		}
+		return "Quote volumes are shown in USDT. Usage: /displaycurrency <currency>, e.g. /displaycurrency EUR"
+	case defaultQuoteAsset, "OFF":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.DisplayCurrency = ""
+		})
+		return "Quote volumes will be shown in USDT."
+	}
+
+	if !validCurrencyCode(currency) {
+		return "Currency must be a code such as EUR or GBP"
+	}
+	rate, err := getDisplayRate(currency)
+	if err != nil {
+		return fmt.Sprintf("Could not get a USDT rate for %s: %v", currency, err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.DisplayCurrency = currency
+	})
+	return fmt.Sprintf("USDT quote volumes will also be shown in %s (1 USDT ≈ %.4f %s, refreshed every %s). Conversions are approximate.", currency, rate, currency, displayRateTTL)
+}
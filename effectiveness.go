@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	effectivenessFile = "effectiveness.json"
+
+	// followThroughShare is the fraction of the alert candle's volume the
+	// next candle must reach for the alert to count as effective.
+	followThroughShare = 0.5
+)
+
+// pendingEffectiveness is an alert waiting for the candle after it to close.
+type pendingEffectiveness struct {
+	ChatID     int64
+	Symbol     string
+	Metric     string
+	CandleOpen time.Time
+}
+
+// effectivenessStats counts evaluated alerts of a chat and how many were
+// followed by continued activity.
+type effectivenessStats struct {
+	Evaluated int `json:"evaluated"`
+	Effective int `json:"effective"`
+}
+
+var (
+	pendingChecks      []pendingEffectiveness
+	effectiveness      = make(map[int64]effectivenessStats)
+	effectivenessMu    sync.Mutex
+	effectivenessDirty bool
+)
+
+// trackEffectiveness queues an alert for evaluation once the next candle
+// has closed.
+func trackEffectiveness(chatID int64, symbol, metric string, data *VolumeData) {
+	effectivenessMu.Lock()
+	pendingChecks = append(pendingChecks, pendingEffectiveness{
+		ChatID:     chatID,
+		Symbol:     symbol,
+		Metric:     metric,
+		CandleOpen: data.OpenTime,
+	})
+	effectivenessMu.Unlock()
+}
+
+// followedThrough reports whether the candle after the alerted one kept at
+// least followThroughShare of its activity. klines must be oldest first and
+// contain both candles.
+func followedThrough(klines []BinanceKline, candleOpen time.Time, metric string) (bool, error) {
+	field := metricField(metric)
+	for i := 0; i+1 < len(klines); i++ {
+		if klineOpenTime(klines[i]) != candleOpen.UnixMilli() {
+			continue
+		}
+		alerted, err := klineFloat(klines[i], field)
+		if err != nil {
+			return false, err
+		}
+		next, err := klineFloat(klines[i+1], field)
+		if err != nil {
+			return false, err
+		}
+		return next >= alerted*followThroughShare, nil
+	}
+	return false, fmt.Errorf("candle %s not found", candleOpen.Format("2006-01-02 15:04"))
+}
+
+// evaluateEffectiveness scores the chat's pending alerts whose following
+// candle has closed.
+func evaluateEffectiveness(chatID int64, now time.Time) {
+	interval := intervalDuration(klineInterval)
+
+	effectivenessMu.Lock()
+	var due []pendingEffectiveness
+	kept := pendingChecks[:0]
+	for _, check := range pendingChecks {
+		if check.ChatID == chatID && !now.Before(check.CandleOpen.Add(2*interval)) {
+			due = append(due, check)
+		} else {
+			kept = append(kept, check)
+		}
+	}
+	pendingChecks = kept
+	effectivenessMu.Unlock()
+
+	for _, check := range due {
+		klines, err := getBinanceKlines(check.Symbol, 3)
+		if err != nil {
+			log.Printf("Error getting kline data for %s: %v\n", check.Symbol, err)
+			continue
+		}
+		effective, err := followedThrough(klines, check.CandleOpen, check.Metric)
+		if err != nil {
+			continue
+		}
+
+		effectivenessMu.Lock()
+		stats := effectiveness[chatID]
+		stats.Evaluated++
+		if effective {
+			stats.Effective++
+		}
+		effectiveness[chatID] = stats
+		effectivenessDirty = true
+		effectivenessMu.Unlock()
+	}
+
+	saveEffectiveness()
+}
+
+func saveEffectiveness() {
+	effectivenessMu.Lock()
+	if !effectivenessDirty {
+		effectivenessMu.Unlock()
+		return
+	}
+	data, err := json.Marshal(effectiveness)
+	effectivenessDirty = false
+	effectivenessMu.Unlock()
+	if err != nil {
+		log.Printf("Error marshaling effectiveness: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(effectivenessFile), data, 0644); err != nil {
+		log.Printf("Error saving effectiveness: %v", err)
+	}
+}
+
+func loadEffectiveness() {
+	data, err := os.ReadFile(dataPath(effectivenessFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading effectiveness file: %v", err)
+		}
+		return
+	}
+
+	loaded := make(map[int64]effectivenessStats)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("Error unmarshaling effectiveness: %v", err)
+		return
+	}
+
+	effectivenessMu.Lock()
+	effectiveness = loaded
+	effectivenessMu.Unlock()
+}
+
+func handleEffectivenessCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.TrackEffectiveness = true
+		})
+		return "Tracking alert effectiveness. This is stored only on this bot's server and never reported anywhere."
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.TrackEffectiveness = false
+		})
+		return "Stopped tracking alert effectiveness."
+	case "reset":
+		effectivenessMu.Lock()
+		delete(effectiveness, chatID)
+		effectivenessDirty = true
+		effectivenessMu.Unlock()
+		saveEffectiveness()
+		return "Effectiveness statistics cleared."
+	case "":
+	default:
+		return "Usage: /effectiveness [on|off|reset]"
+	}
+
+	effectivenessMu.Lock()
+	stats := effectiveness[chatID]
+	effectivenessMu.Unlock()
+
+	if !getChatConfig(chatID).TrackEffectiveness && stats.Evaluated == 0 {
+		return "Effectiveness tracking is off. Enable it with /effectiveness on."
+	}
+	if stats.Evaluated == 0 {
+		return "No alerts have been evaluated yet. Each alert is scored once the candle after it closes."
+	}
+	return fmt.Sprintf("%d of %d alerts (%.0f%%) were followed by continued activity: the next candle reached at least %.0f%% of the alert candle's %s.",
+		stats.Effective, stats.Evaluated, float64(stats.Effective)/float64(stats.Evaluated)*100,
+		followThroughShare*100, strings.ToLower(metricLabel(getChatConfig(chatID).Metric)))
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	emailAttempts       = 3
+	emailInitialBackoff = 2 * time.Second
+)
+
+// smtpTimeout bounds the dial and, separately, the whole exchange with the
+// SMTP server, so a stalled server can't hold a worker or shutdown's drain.
+var smtpTimeout = 30 * time.Second
+
+// smtpConfigured reports whether the SMTP_* settings name a server and a
+// sender, without which email delivery is unavailable.
+func smtpConfigured() bool {
+	s := currentSettings()
+	return s.SMTPHost != "" && s.SMTPFrom != ""
+}
+
+// emailNotifier sends alerts as plain-text emails through the SMTP server
+// configured by the SMTP_* settings.
+type emailNotifier struct {
+	to        string
+	precision int
+}
+
+func newEmailNotifier(to string, precision int) *emailNotifier {
+	return &emailNotifier{to: to, precision: precision}
+}
+
+func (e *emailNotifier) Name() string {
+	return "email"
+}
+
+// buildAlertEmail renders an alert as an RFC 5322 message. The subject is
+// MIME-encoded and the body quoted-printable, so emoji and non-ASCII text
+// survive any mail server and no alert text can inject headers.
+func buildAlertEmail(from, to string, alert Alert, precision int, now time.Time) ([]byte, error) {
+	subject := fmt.Sprintf("%s volume alert: %s", alert.Symbol, formatRatio(alert.Data.Ratio, precision))
+	if alert.Test {
+		subject = "Test: " + subject
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", (&mail.Address{Address: from}).String())
+	fmt.Fprintf(&msg, "To: %s\r\n", (&mail.Address{Address: to}).String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&msg)
+	text := strings.ReplaceAll(formatAlert(alert, precision), "\n", "\r\n")
+	if _, err := body.Write([]byte(text + "\r\n")); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+// isTransientSMTPError reports whether a failed send is worth retrying:
+// network failures and 4xx replies are, 5xx replies are permanent.
+func isTransientSMTPError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (e *emailNotifier) Notify(alert Alert) error {
+	if !smtpConfigured() {
+		return fmt.Errorf("SMTP is not configured")
+	}
+	s := currentSettings()
+
+	msg, err := buildAlertEmail(s.SMTPFrom, e.to, alert, e.precision, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %v", err)
+	}
+
+	var auth smtp.Auth
+	if s.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.SMTPUsername, s.SMTPPassword, s.SMTPHost)
+	}
+
+	backoff := emailInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = sendMail(s.SMTPHost, s.SMTPPort, auth, s.SMTPFrom, e.to, msg)
+		if err == nil || attempt == emailAttempts || !isTransientSMTPError(err) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// sendMail is smtp.SendMail with timeouts: it upgrades to TLS when the
+// server offers STARTTLS and authenticates when auth is set.
+func sendMail(host string, port int, auth smtp.Auth, from, to string, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), smtpTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func handleEmailCommand(chatID int64, args string) string {
+	arg := strings.TrimSpace(args)
+	switch {
+	case strings.EqualFold(arg, "off"):
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Email = ""
+		})
+		return "Email delivery disabled."
+	case !smtpConfigured():
+		return "Email delivery is not configured on this bot."
+	case arg == "":
+		if cfg := getChatConfig(chatID); cfg.Email != "" {
+			return fmt.Sprintf("Alerts are also emailed to %s. Use /email off to disable.", cfg.Email)
+		}
+		return "Usage: /email <address>, or /email off"
+	}
+
+	addr, err := mail.ParseAddress(arg)
+	if err != nil {
+		return "That is not a valid email address."
+	}
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Email = addr.Address
+	})
+	return fmt.Sprintf("Alerts will also be emailed to %s. Use /testnotifiers to check delivery.", addr.Address)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEmailAlert(symbol string) Alert {
+	return Alert{
+		ChatID:    1,
+		Symbol:    symbol,
+		Threshold: 5,
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:      &VolumeData{PrevVolume: 100, CurrVolume: 800, Ratio: 8, TypicalPrice: 65000},
+	}
+}
+
+// parseAlertEmail builds an alert email and parses it back, returning its
+// headers, decoded subject and decoded body.
+func parseAlertEmail(t *testing.T, alert Alert) (mail.Header, string, string) {
+	t.Helper()
+	raw, err := buildAlertEmail("bot@example.com", "trader@example.com", alert, 2, time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildAlertEmail returned error: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("email does not parse: %v\n%s", err, raw)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("subject does not decode: %v", err)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("body is not valid quoted-printable: %v", err)
+	}
+	return msg.Header, subject, string(body)
+}
+
+func TestBuildAlertEmail(t *testing.T) {
+	alert := testEmailAlert("BTCUSDT")
+	header, subject, body := parseAlertEmail(t, alert)
+
+	if want := "BTCUSDT volume alert: 8.00x"; subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	for key, want := range map[string]string{
+		"From":                      "<bot@example.com>",
+		"To":                        "<trader@example.com>",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "quoted-printable",
+		"Date":                      "Fri, 02 Jan 2026 03:05:00 +0000",
+	} {
+		if got := header.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if want := strings.ReplaceAll(formatAlert(alert, 2), "\n", "\r\n") + "\r\n"; body != want {
+		t.Errorf("body = %q, want the formatted alert with CRLF line ends %q", body, want)
+	}
+}
+
+func TestBuildAlertEmailEncodesNonASCII(t *testing.T) {
+	alert := testEmailAlert("ÄBCUSDT")
+	alert.Test = true
+	raw, err := buildAlertEmail("bot@example.com", "trader@example.com", alert, 2, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(raw, []byte("Subject: =?utf-8?q?")) {
+		t.Errorf("non-ASCII subject is not Q-encoded:\n%s", raw)
+	}
+	for i, line := range bytes.Split(raw, []byte("\r\n")) {
+		for _, c := range line {
+			if c > '~' {
+				t.Fatalf("line %d carries raw non-ASCII bytes: %q", i, line)
+			}
+		}
+		if len(line) > 998 {
+			t.Errorf("line %d is %d bytes long", i, len(line))
+		}
+	}
+
+	_, subject, body := parseAlertEmail(t, alert)
+	if want := "Test: ÄBCUSDT volume alert: 8.00x"; subject != want {
+		t.Errorf("subject = %q, want %q", subject, want)
+	}
+	if !strings.Contains(body, "ÄBCUSDT") {
+		t.Errorf("body lost the non-ASCII symbol: %q", body)
+	}
+}
+
+func TestBuildAlertEmailHeaderInjection(t *testing.T) {
+	alert := testEmailAlert("BTCUSDT\r\nBcc: victim@example.com\r\n\r\nforged body")
+	header, subject, _ := parseAlertEmail(t, alert)
+
+	if header.Get("Bcc") != "" {
+		t.Errorf("alert text injected a Bcc header: %q", header.Get("Bcc"))
+	}
+	for key := range header {
+		switch key {
+		case "From", "To", "Subject", "Date", "Mime-Version", "Content-Type", "Content-Transfer-Encoding":
+		default:
+			t.Errorf("unexpected header %s: %q", key, header.Get(key))
+		}
+	}
+	if !strings.HasPrefix(subject, "BTCUSDT\r\nBcc: victim@example.com") {
+		t.Errorf("subject = %q, want the CRLF kept inside the encoded word", subject)
+	}
+}
+
+// fakeSMTP accepts one connection on a loopback port and hands it to serve.
+func fakeSMTP(t *testing.T, serve func(conn *textproto.Conn)) (host string, port int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(textproto.NewConn(conn))
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestSendMail(t *testing.T) {
+	received := make(chan string, 1)
+	host, port := fakeSMTP(t, func(c *textproto.Conn) {
+		c.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := c.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				c.PrintfLine("250 OK")
+			case "DATA":
+				c.PrintfLine("354 go ahead")
+				data, _ := io.ReadAll(c.DotReader())
+				received <- string(data)
+				c.PrintfLine("250 queued")
+			case "QUIT":
+				c.PrintfLine("221 bye")
+				return
+			default:
+				c.PrintfLine("502 unrecognized")
+			}
+		}
+	})
+
+	msg := []byte("Subject: hi\r\n\r\nbody\r\n")
+	if err := sendMail(host, port, nil, "bot@example.com", "trader@example.com", msg); err != nil {
+		t.Fatalf("sendMail returned error: %v", err)
+	}
+	select {
+	case got := <-received:
+		if got != "Subject: hi\n\nbody\n" {
+			t.Errorf("server received %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("server received no message")
+	}
+}
+
+func TestSendMailStalledServer(t *testing.T) {
+	old := smtpTimeout
+	smtpTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { smtpTimeout = old })
+
+	// The server accepts the connection but never greets.
+	host, port := fakeSMTP(t, func(c *textproto.Conn) {
+		bufio.NewReader(c.R).ReadString('\n')
+	})
+
+	start := time.Now()
+	err := sendMail(host, port, nil, "bot@example.com", "trader@example.com", []byte("x"))
+	if elapsed := time.Since(start); elapsed > 5*smtpTimeout {
+		t.Errorf("sendMail took %v against a stalled server, want about %v", elapsed, smtpTimeout)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("sendMail error = %v, want a timeout", err)
+	}
+	if !isTransientSMTPError(err) {
+		t.Errorf("isTransientSMTPError(%v) = false, want a timeout retried", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	// binanceAPIURL is the main REST API. It serves every endpoint but its
+	// request-weight limits are shared with trading traffic and it is
+	// geo-blocked in some regions.
+	binanceAPIURL = "https://api.binance.com"
+
+	// binanceMirrorURL is Binance's public market-data mirror. It needs no
+	// API key, has its own rate limits and is reachable from some regions
+	// where api.binance.com is not, but it only serves public market
+	// endpoints (klines, tickers, depth, exchangeInfo) and may lag the main
+	// API slightly.
+	binanceMirrorURL = "https://data-api.binance.vision"
+
+	endpointMirror = "mirror"
+)
+
+var binanceBaseURL = binanceAPIURL
+
+// configureBinanceEndpoint selects the base URL used for Binance requests.
+// BINANCE_BASE_URL wins; otherwise BINANCE_ENDPOINT=mirror selects the data
+// mirror. The mirror is only kept if its kline response passes the schema
+// check, falling back to the main API otherwise.
+func configureBinanceEndpoint() {
+	baseURL := binanceAPIURL
+	if currentSettings().BinanceBaseURL != "" {
+		baseURL = strings.TrimRight(currentSettings().BinanceBaseURL, "/")
+	} else if currentSettings().BinanceEndpoint == endpointMirror {
+		baseURL = binanceMirrorURL
+	}
+
+	if baseURL == binanceAPIURL {
+		return
+	}
+
+	binanceBaseURL = baseURL
+	if err := checkKlineEndpoint(); err != nil {
+		log.Printf("Binance endpoint %s failed validation, falling back to %s: %v", baseURL, binanceAPIURL, err)
+		binanceBaseURL = binanceAPIURL
+		return
+	}
+	log.Printf("Using Binance endpoint %s", binanceBaseURL)
+}
+
+// checkKlineEndpoint fetches a known symbol's klines from binanceBaseURL and
+// verifies the response has the expected shape.
+func checkKlineEndpoint() error {
+	klines, err := getBinanceKlines("BTCUSDT", 2)
+	if err != nil {
+		return err
+	}
+	if len(klines) != 2 {
+		return fmt.Errorf("expected 2 klines, got %d", len(klines))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Exchange fetches the latest candle-over-candle volume for a symbol from
+// one venue. Volume returns an error matching ErrSymbolNotFound when the
+// venue does not list the symbol, and nil data without an error when the
+// previous candle had no volume to compare against. PingURL is a
+// lightweight endpoint, such as the server time, used to check the venue is
+// reachable.
+type Exchange interface {
+	Name() string
+	Volume(symbol string) (*VolumeData, error)
+	PingURL() string
+}
+
+var exchanges = []Exchange{binanceExchange{}, bybitExchange{}}
+
+type binanceExchange struct{}
+
+func (binanceExchange) Name() string {
+	return "Binance"
+}
+
+func (binanceExchange) PingURL() string {
+	return binanceBaseURL + "/api/v3/time"
+}
+
+func (binanceExchange) Volume(symbol string) (*VolumeData, error) {
+	// Bybit is read including its open candle, so compare like for like.
+	return getBinanceVolume(symbol, metricVolume, false)
+}
+
+// bybitRetCodeUnknownSymbol is returned by Bybit's v5 API for symbols it
+// does not list.
+const bybitRetCodeUnknownSymbol = 10001
+
+type bybitKlineResponse struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List [][]string `json:"list"`
+	} `json:"result"`
+}
+
+type bybitExchange struct{}
+
+func (bybitExchange) Name() string {
+	return "Bybit"
+}
+
+func (bybitExchange) PingURL() string {
+	return currentSettings().BybitBaseURL + "/v5/market/time"
+}
+
+// Volume reads spot klines from Bybit's v5 API. Bybit returns candles
+// newest first as [start, open, high, low, close, volume, turnover].
+func (bybitExchange) Volume(symbol string) (*VolumeData, error) {
+	interval, err := bybitInterval(klineInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/v5/market/kline?category=spot&symbol=%s&interval=%s&limit=2", currentSettings().BybitBaseURL, symbol, interval)
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, requestError("bybit klines", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError("bybit klines", resp); err != nil {
+		return nil, err
+	}
+
+	var klines bybitKlineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&klines); err != nil {
+		return nil, decodeError("bybit klines", err)
+	}
+
+	if klines.RetCode == bybitRetCodeUnknownSymbol {
+		return nil, &FetchError{Source: "bybit klines", Kind: ErrSymbolNotFound}
+	}
+	if klines.RetCode != 0 {
+		return nil, &FetchError{Source: "bybit klines", Kind: ErrBadResponse, Err: fmt.Errorf("retCode %d: %s", klines.RetCode, klines.RetMsg)}
+	}
+
+	list := klines.Result.List
+	if len(list) < 2 || len(list[0]) < 6 || len(list[1]) < 6 {
+		return nil, decodeError("bybit klines", fmt.Errorf("insufficient kline data"))
+	}
+
+	currVolume, _ := strconv.ParseFloat(list[0][5], 64)
+	prevVolume, _ := strconv.ParseFloat(list[1][5], 64)
+	startTime, _ := strconv.ParseInt(list[0][0], 10, 64)
+
+	if prevVolume == 0 {
+		return nil, nil
+	}
+
+	return &VolumeData{
+		PrevVolume: prevVolume,
+		CurrVolume: currVolume,
+		Ratio:      currVolume / prevVolume,
+		OpenTime:   time.UnixMilli(startTime),
+	}, nil
+}
+
+// bybitInterval converts a Binance-style interval to Bybit's notation:
+// minutes for intraday candles, "D" and "W" for days and weeks.
+func bybitInterval(interval string) (string, error) {
+	d := intervalDuration(interval)
+	switch {
+	case d == 24*time.Hour:
+		return "D", nil
+	case d == 7*24*time.Hour:
+		return "W", nil
+	case d > 0 && d < 24*time.Hour:
+		return strconv.Itoa(int(d / time.Minute)), nil
+	}
+	return "", fmt.Errorf("interval %s is not supported by bybit", interval)
+}
+
+type exchangeVolume struct {
+	Exchange string
+	Data     *VolumeData
+	Err      error
+}
+
+// compareExchanges fetches the symbol's volume from every exchange
+// concurrently, returning results in the order of exchanges.
+func compareExchanges(symbol string) []exchangeVolume {
+	results := make([]exchangeVolume, len(exchanges))
+
+	var wg sync.WaitGroup
+	for i, exchange := range exchanges {
+		wg.Add(1)
+		go func(i int, exchange Exchange) {
+			defer wg.Done()
+			data, err := exchange.Volume(symbol)
+			results[i] = exchangeVolume{Exchange: exchange.Name(), Data: data, Err: err}
+		}(i, exchange)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func handleCompareExchangesCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 1 {
+		return "Usage: /compareexchanges <symbol>"
+	}
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+	precision := getChatConfig(chatID).Precision
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s volume by exchange (%s candles):\n", symbol, klineInterval)
+	for _, result := range compareExchanges(symbol) {
+		switch {
+		case errors.Is(result.Err, ErrSymbolNotFound):
+			fmt.Fprintf(&sb, "%s: not listed\n", result.Exchange)
+		case result.Err != nil:
+			fmt.Fprintf(&sb, "%s: error: %v\n", result.Exchange, result.Err)
+		case result.Data == nil:
+			fmt.Fprintf(&sb, "%s: no previous volume\n", result.Exchange)
+		default:
+			fmt.Fprintf(&sb, "%s: %s → %s (%s)\n", result.Exchange,
+				formatVolume(result.Data.PrevVolume, precision),
+				formatVolume(result.Data.CurrVolume, precision),
+				formatRatio(result.Data.Ratio, precision))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	exchangeInfoTTL = time.Hour
+	// exchangeInfoErrorBackoff is how long a failed fetch is returned to
+	// callers before exchangeInfo is requested again, so an outage costs
+	// one timeout rather than one per symbol scanned.
+	exchangeInfoErrorBackoff = time.Minute
+	defaultQuoteAsset        = "USDT"
+	maxSymbolSuggestion      = 5
+)
+
+// SymbolInfo is the subset of a Binance exchangeInfo symbol entry used here.
+type SymbolInfo struct {
+	Symbol              string                   `json:"symbol"`
+	Status              string                   `json:"status"`
+	BaseAsset           string                   `json:"baseAsset"`
+	QuoteAsset          string                   `json:"quoteAsset"`
+	BaseAssetPrecision  int                      `json:"baseAssetPrecision"`
+	QuoteAssetPrecision int                      `json:"quoteAssetPrecision"`
+	Filters             []map[string]interface{} `json:"filters"`
+}
+
+type exchangeInfoResponse struct {
+	Symbols []SymbolInfo `json:"symbols"`
+}
+
+var (
+	exchangeSymbols          map[string]SymbolInfo
+	exchangeSymbolsFetchedAt time.Time
+	exchangeInfoErr          error
+	exchangeInfoErrAt        time.Time
+	// exchangeInfoFetching is closed when the fetch in flight, if any,
+	// completes.
+	exchangeInfoFetching chan struct{}
+	exchangeSymbolsMu    sync.Mutex
+)
+
+// getExchangeInfo returns every Binance symbol keyed by name, refreshed at
+// most once per exchangeInfoTTL. A failure is returned without a new
+// request for exchangeInfoErrorBackoff, and callers arriving while a fetch
+// is in flight wait for its result instead of starting their own.
+func getExchangeInfo() (map[string]SymbolInfo, error) {
+	exchangeSymbolsMu.Lock()
+	for {
+		if exchangeSymbols != nil && time.Since(exchangeSymbolsFetchedAt) < exchangeInfoTTL {
+			symbols := exchangeSymbols
+			exchangeSymbolsMu.Unlock()
+			return symbols, nil
+		}
+		if exchangeInfoErr != nil && time.Since(exchangeInfoErrAt) < exchangeInfoErrorBackoff {
+			err := exchangeInfoErr
+			exchangeSymbolsMu.Unlock()
+			return nil, err
+		}
+		if exchangeInfoFetching == nil {
+			break
+		}
+		wait := exchangeInfoFetching
+		exchangeSymbolsMu.Unlock()
+		<-wait
+		exchangeSymbolsMu.Lock()
+	}
+	done := make(chan struct{})
+	exchangeInfoFetching = done
+	exchangeSymbolsMu.Unlock()
+
+	symbols, err := fetchExchangeInfo()
+
+	exchangeSymbolsMu.Lock()
+	defer exchangeSymbolsMu.Unlock()
+	exchangeInfoFetching = nil
+	close(done)
+	if err != nil {
+		exchangeInfoErr, exchangeInfoErrAt = err, time.Now()
+		return nil, err
+	}
+	exchangeSymbols, exchangeSymbolsFetchedAt = symbols, time.Now()
+	exchangeInfoErr = nil
+	return symbols, nil
+}
+
+func fetchExchangeInfo() (map[string]SymbolInfo, error) {
+	resp, err := httpClient.Get(binanceBaseURL + "/api/v3/exchangeInfo")
+	if err != nil {
+		return nil, requestError("binance exchange info", err)
+	}
+	defer resp.Body.Close()
+
+	if err := responseError("binance exchange info", resp); err != nil {
+		return nil, err
+	}
+
+	var info exchangeInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, decodeError("binance exchange info", err)
+	}
+
+	symbols := make(map[string]SymbolInfo, len(info.Symbols))
+	for _, s := range info.Symbols {
+		symbols[s.Symbol] = s
+	}
+	return symbols, nil
+}
+
+// resolveSymbol turns user input such as "btc" or "BTCUSDT" into a Binance
+// symbol, expanding bare base assets with the chat's default quote. Unknown
+// input yields an error suggesting close matches. If exchangeInfo cannot be
+// fetched the expanded symbol is returned unvalidated.
+func resolveSymbol(chatID int64, input string) (string, error) {
+	upper := strings.ToUpper(strings.TrimSpace(input))
+	quote := getChatConfig(chatID).DefaultQuote
+	if quote == "" {
+		quote = defaultQuoteAsset
+	}
+
+	symbols, err := getExchangeInfo()
+	if err != nil {
+		log.Printf("Error resolving symbol %s: %v", upper, err)
+		if strings.HasSuffix(upper, quote) {
+			return upper, nil
+		}
+		return upper + quote, nil
+	}
+
+	if _, ok := symbols[upper]; ok {
+		return upper, nil
+	}
+	if _, ok := symbols[upper+quote]; ok {
+		return upper + quote, nil
+	}
+
+	suggestions := suggestSymbols(symbols, upper)
+	if len(suggestions) == 0 {
+		return "", fmt.Errorf("symbol %s was not found on Binance", upper)
+	}
+	return "", fmt.Errorf("symbol %s was not found on Binance. Did you mean: %s?", upper, strings.Join(suggestions, ", "))
+}
+
+// suggestSymbols lists trading symbols whose base asset is input, followed
+// by symbols within a small edit distance of it.
+func suggestSymbols(symbols map[string]SymbolInfo, input string) []string {
+	var exact, near []string
+	for name, info := range symbols {
+		if info.Status != "TRADING" {
+			continue
+		}
+		if info.BaseAsset == input {
+			exact = append(exact, name)
+		} else if editDistance(name, input) <= 2 || editDistance(info.BaseAsset, input) == 1 {
+			near = append(near, name)
+		}
+	}
+	sort.Strings(exact)
+	sort.Strings(near)
+
+	suggestions := append(exact, near...)
+	if len(suggestions) > maxSymbolSuggestion {
+		suggestions = suggestions[:maxSymbolSuggestion]
+	}
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func handleDefaultQuoteCommand(chatID int64, args string) string {
+	quote := strings.ToUpper(strings.TrimSpace(args))
+	if quote == "" {
+		current := getChatConfig(chatID).DefaultQuote
+		if current == "" {
+			current = defaultQuoteAsset
+		}
+		return fmt.Sprintf("Bare symbols like btc expand with %s. Usage: /defaultquote <asset>", current)
+	}
+
+	if symbols, err := getExchangeInfo(); err == nil {
+		found := false
+		for _, info := range symbols {
+			if info.QuoteAsset == quote {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%s is not a quote asset on Binance", quote)
+		}
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.DefaultQuote = quote
+		if quote == defaultQuoteAsset {
+			cfg.DefaultQuote = ""
+		}
+	})
+	return fmt.Sprintf("Bare symbols will expand with %s, e.g. btc → BTC%s.", quote, quote)
+}
+
+// formatFilter renders an exchangeInfo filter as "TYPE: key=value ...",
+// with keys sorted.
+func formatFilter(filter map[string]interface{}) string {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		if key != "filterType" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, filter[key]))
+	}
+	return fmt.Sprintf("%v: %s", filter["filterType"], strings.Join(parts, " "))
+}
+
+func handleValidateCommand(args string) string {
+	fields := strings.Fields(strings.ToUpper(args))
+	var symbol string
+	switch len(fields) {
+	case 1:
+		symbol = fields[0]
+	case 2:
+		symbol = fields[0] + fields[1]
+	default:
+		return "Usage: /validate <base> <quote>, e.g. /validate BTC USDT"
+	}
+
+	symbols, err := getExchangeInfo()
+	if err != nil {
+		return fmt.Sprintf("Could not fetch exchange info: %v", err)
+	}
+
+	info, ok := symbols[symbol]
+	if !ok {
+		if suggestions := suggestSymbols(symbols, symbol); len(suggestions) > 0 {
+			return fmt.Sprintf("❌ %s does not exist on Binance. Did you mean: %s?", symbol, strings.Join(suggestions, ", "))
+		}
+		return fmt.Sprintf("❌ %s does not exist on Binance.", symbol)
+	}
+
+	var sb strings.Builder
+	if info.Status == "TRADING" {
+		fmt.Fprintf(&sb, "✅ %s is actively trading\n", symbol)
+	} else {
+		fmt.Fprintf(&sb, "⚠️ %s exists but is not trading (status %s)\n", symbol, info.Status)
+	}
+	fmt.Fprintf(&sb, "Base: %s (precision %d)\n", info.BaseAsset, info.BaseAssetPrecision)
+	fmt.Fprintf(&sb, "Quote: %s (precision %d)\n", info.QuoteAsset, info.QuoteAssetPrecision)
+	for _, filter := range info.Filters {
+		sb.WriteString(formatFilter(filter) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
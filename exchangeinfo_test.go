@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useFakeExchangeInfo points binanceBaseURL at a server answering
+// exchangeInfo with status and body, clearing the cached exchangeInfo.
+// The returned counter holds the number of requests served.
+func useFakeExchangeInfo(t *testing.T, status int, body string, delay time.Duration) *atomic.Int32 {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(delay)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+
+	resetExchangeInfo := func() {
+		exchangeSymbolsMu.Lock()
+		exchangeSymbols, exchangeInfoErr = nil, nil
+		exchangeSymbolsMu.Unlock()
+	}
+	previous := binanceBaseURL
+	binanceBaseURL = srv.URL
+	resetExchangeInfo()
+	t.Cleanup(func() {
+		srv.Close()
+		binanceBaseURL = previous
+		resetExchangeInfo()
+	})
+	return &hits
+}
+
+func TestExchangeInfoFailureIsCached(t *testing.T) {
+	hits := useFakeExchangeInfo(t, http.StatusServiceUnavailable, "", 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := getExchangeInfo(); err == nil {
+				t.Error("getExchangeInfo returned no error during an outage")
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := getExchangeInfo(); err == nil {
+		t.Error("getExchangeInfo returned no error within the backoff")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("exchangeInfo requested %d times, want 1", n)
+	}
+
+	exchangeSymbolsMu.Lock()
+	exchangeInfoErrAt = time.Now().Add(-exchangeInfoErrorBackoff)
+	exchangeSymbolsMu.Unlock()
+	getExchangeInfo()
+	if n := hits.Load(); n != 2 {
+		t.Errorf("exchangeInfo requested %d times after the backoff, want 2", n)
+	}
+}
+
+func TestExchangeInfoSuccessIsCached(t *testing.T) {
+	hits := useFakeExchangeInfo(t, http.StatusOK, `{"symbols":[{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT"}]}`, 0)
+
+	for range 3 {
+		symbols, err := getExchangeInfo()
+		if err != nil {
+			t.Fatalf("getExchangeInfo returned error: %v", err)
+		}
+		if symbols["BTCUSDT"].QuoteAsset != "USDT" {
+			t.Errorf("getExchangeInfo = %v, want BTCUSDT quoted in USDT", symbols)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("exchangeInfo requested %d times, want 1", n)
+	}
+}
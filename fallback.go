@@ -0,0 +1,86 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	marketCapAttempts = 3
+	marketCapBackoff  = 2 * time.Second
+
+	symbolSourceCoinGecko = "CoinGecko"
+	symbolSourceCache     = "cached CoinGecko list"
+	symbolSourceStatic    = "static symbol list"
+)
+
+//go:embed static_symbols.txt
+var staticSymbolsFile string
+
+var (
+	lastTopSymbols  = make(map[string][]string)
+	lastSymbolsFrom string
+	topSymbolsMu    sync.Mutex
+)
+
+// staticSymbols parses the embedded symbol list, skipping blank lines and
+// comments.
+func staticSymbols() []string {
+	var symbols []string
+	for _, line := range strings.Split(staticSymbolsFile, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		symbols = append(symbols, line)
+	}
+	return symbols
+}
+
+// getTopSymbols returns the top coins in the given CoinGecko order,
+// retrying CoinGecko a few times before falling back to the last list it
+// returned and, if there is none, to the embedded static list.
+func getTopSymbols(order string) []string {
+	var err error
+	backoff := marketCapBackoff
+	for attempt := 1; attempt <= marketCapAttempts; attempt++ {
+		var symbols []string
+		if symbols, err = getMarketCapRank("", order); err == nil && len(symbols) > 0 {
+			topSymbolsMu.Lock()
+			lastTopSymbols[order] = symbols
+			topSymbolsMu.Unlock()
+			useSymbolSource(symbolSourceCoinGecko)
+			return symbols
+		}
+		if attempt < marketCapAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	log.Printf("Error getting market cap rank after %d attempts: %v", marketCapAttempts, err)
+
+	topSymbolsMu.Lock()
+	cached := lastTopSymbols[order]
+	topSymbolsMu.Unlock()
+	if len(cached) > 0 {
+		useSymbolSource(symbolSourceCache)
+		return cached
+	}
+
+	useSymbolSource(symbolSourceStatic)
+	return staticSymbols()
+}
+
+// useSymbolSource logs when the source of the monitored symbols changes.
+func useSymbolSource(source string) {
+	topSymbolsMu.Lock()
+	defer topSymbolsMu.Unlock()
+
+	if source != lastSymbolsFrom {
+		log.Printf("Using %s for monitored symbols", source)
+		lastSymbolsFrom = source
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Kinds of upstream fetch failure. Fetch functions return a *FetchError
+// whose Kind is one of these, so callers can branch with errors.Is.
+var (
+	ErrSymbolNotFound      = errors.New("symbol not found")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrBadResponse         = errors.New("bad response")
+	// ErrInvalidRequest is a request Binance rejected as malformed, such as
+	// one with a bad interval. It points at a bug rather than a symbol to
+	// skip.
+	ErrInvalidRequest = errors.New("invalid request")
+)
+
+// defaultRetryAfter is used when a rate-limited response doesn't say how
+// long to wait.
+const defaultRetryAfter = time.Minute
+
+// FetchError describes a failed request to an upstream API such as Binance
+// or CoinGecko.
+type FetchError struct {
+	// Source names the request, e.g. "binance klines".
+	Source string
+	Kind   error
+	// StatusCode is the HTTP status, zero if no response was received.
+	StatusCode int
+	// RetryAfter is how long to wait before retrying a rate-limited
+	// request.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Source, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(": %v", e.Err)
+	}
+	return msg
+}
+
+func (e *FetchError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// requestError wraps a transport-level failure, where no response arrived.
+func requestError(source string, err error) error {
+	return recordAPIError(&FetchError{Source: source, Kind: ErrUpstreamUnavailable, Err: err})
+}
+
+// decodeError wraps a response body that could not be parsed.
+func decodeError(source string, err error) error {
+	return recordAPIError(&FetchError{Source: source, Kind: ErrBadResponse, Err: err})
+}
+
+// responseError classifies a non-2xx response, returning nil for success.
+func responseError(source string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	e := &FetchError{Source: source, StatusCode: resp.StatusCode}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		e.Kind = ErrSymbolNotFound
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot:
+		// Binance answers 418 once an IP keeps ignoring 429s.
+		e.Kind = ErrRateLimited
+		e.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode >= 500:
+		e.Kind = ErrUpstreamUnavailable
+	default:
+		e.Kind = ErrBadResponse
+	}
+	return recordAPIError(e)
+}
+
+// binanceCodeInvalidSymbol is the error code Binance sends for a symbol it
+// does not list.
+const binanceCodeInvalidSymbol = -1121
+
+// maxErrorBodySize bounds how much of an error response is read.
+const maxErrorBodySize = 4096
+
+// BinanceAPIError is the body Binance sends with a 4xx response.
+type BinanceAPIError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *BinanceAPIError) Error() string {
+	return fmt.Sprintf("binance error %d: %s", e.Code, e.Msg)
+}
+
+// parseBinanceError decodes a Binance error body.
+func parseBinanceError(r io.Reader) (*BinanceAPIError, error) {
+	apiErr := &BinanceAPIError{}
+	if err := json.NewDecoder(io.LimitReader(r, maxErrorBodySize)).Decode(apiErr); err != nil {
+		return nil, err
+	}
+	return apiErr, nil
+}
+
+// binanceBadRequest classifies a 400 from Binance by the code in its body:
+// -1121 is an unknown symbol, anything else, or a body that can't be read,
+// an invalid request, which is recorded for /errors.
+func binanceBadRequest(source string, resp *http.Response) *FetchError {
+	apiErr, err := parseBinanceError(resp.Body)
+	if err != nil {
+		return recordAPIError(&FetchError{Source: source, Kind: ErrInvalidRequest, StatusCode: resp.StatusCode, Err: fmt.Errorf("unreadable error body: %v", err)})
+	}
+	if apiErr.Code == binanceCodeInvalidSymbol {
+		return &FetchError{Source: source, Kind: ErrSymbolNotFound, StatusCode: resp.StatusCode, Err: apiErr}
+	}
+	return recordAPIError(&FetchError{Source: source, Kind: ErrInvalidRequest, StatusCode: resp.StatusCode, Err: apiErr})
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return defaultRetryAfter
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// fetchRetryAfter returns how long err asks callers to wait, or zero if it
+// is not a rate limit.
+func fetchRetryAfter(err error) time.Duration {
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) && errors.Is(fetchErr.Kind, ErrRateLimited) {
+		return fetchErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchErrorIs(t *testing.T) {
+	kinds := []error{ErrSymbolNotFound, ErrRateLimited, ErrUpstreamUnavailable, ErrBadResponse, ErrInvalidRequest}
+	cause := io.ErrUnexpectedEOF
+
+	for _, kind := range kinds {
+		t.Run(kind.Error(), func(t *testing.T) {
+			for _, err := range []error{
+				&FetchError{Source: "test", Kind: kind},
+				&FetchError{Source: "test", Kind: kind, Err: cause},
+				fmt.Errorf("wrapped: %w", &FetchError{Source: "test", Kind: kind}),
+			} {
+				if !errors.Is(err, kind) {
+					t.Errorf("errors.Is(%v, %v) = false", err, kind)
+				}
+				for _, other := range kinds {
+					if other != kind && errors.Is(err, other) {
+						t.Errorf("errors.Is(%v, %v) = true", err, other)
+					}
+				}
+			}
+			if err := (&FetchError{Source: "test", Kind: kind, Err: cause}); !errors.Is(err, cause) {
+				t.Errorf("errors.Is(%v, %v) = false", err, cause)
+			}
+		})
+	}
+}
+
+func TestFetchBinanceKlinesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		body       string
+		want       error
+		retryAfter time.Duration
+	}{
+		{"rate limited", http.StatusTooManyRequests, http.Header{"Retry-After": {"7"}}, "", ErrRateLimited, 7 * time.Second},
+		{"banned", http.StatusTeapot, nil, "", ErrRateLimited, defaultRetryAfter},
+		{"unavailable", http.StatusServiceUnavailable, nil, "", ErrUpstreamUnavailable, 0},
+		{"server error", http.StatusInternalServerError, nil, "", ErrUpstreamUnavailable, 0},
+		{"not found", http.StatusNotFound, nil, "", ErrSymbolNotFound, 0},
+		{"malformed JSON", http.StatusOK, nil, `[[1700000000000, "1.0"`, ErrBadResponse, 0},
+		{"not a list", http.StatusOK, nil, `{"symbol":"TESTUSDT"}`, ErrBadResponse, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			_, err := fetchBinanceKlines("TESTUSDT", srv.URL+"/api/v3/klines?symbol=TESTUSDT")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("fetchBinanceKlines error = %v, want %v", err, tt.want)
+			}
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("fetchBinanceKlines error = %T, want a *FetchError", err)
+			}
+			// A body that fails to decode came with a 200, which
+			// decodeError doesn't record.
+			wantStatus := tt.status
+			if wantStatus == http.StatusOK {
+				wantStatus = 0
+			}
+			if fetchErr.StatusCode != wantStatus {
+				t.Errorf("StatusCode = %d, want %d", fetchErr.StatusCode, wantStatus)
+			}
+			if got := fetchRetryAfter(err); got != tt.retryAfter {
+				t.Errorf("fetchRetryAfter = %v, want %v", got, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestFetchBinanceKlinesTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/api/v3/klines?symbol=TESTUSDT"
+	srv.Close()
+
+	_, err := fetchBinanceKlines("TESTUSDT", url)
+	if !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Fatalf("fetchBinanceKlines error = %v, want %v", err, ErrUpstreamUnavailable)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != 0 || fetchErr.Err == nil {
+		t.Errorf("fetchBinanceKlines error = %#v, want a FetchError with no status and the transport error", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"30", 30 * time.Second},
+		{"1", time.Second},
+		{"", defaultRetryAfter},
+		{"0", defaultRetryAfter},
+		{"-5", defaultRetryAfter},
+		{"soon", defaultRetryAfter},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", defaultRetryAfter},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.header); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestFetchRetryAfter(t *testing.T) {
+	limited := &FetchError{Source: "test", Kind: ErrRateLimited, RetryAfter: 5 * time.Second}
+	if got := fetchRetryAfter(fmt.Errorf("scan: %w", limited)); got != 5*time.Second {
+		t.Errorf("fetchRetryAfter(rate limited) = %v, want 5s", got)
+	}
+	if got := fetchRetryAfter(&FetchError{Source: "test", Kind: ErrUpstreamUnavailable, RetryAfter: time.Second}); got != 0 {
+		t.Errorf("fetchRetryAfter(unavailable) = %v, want 0", got)
+	}
+	if got := fetchRetryAfter(errors.New("plain")); got != 0 {
+		t.Errorf("fetchRetryAfter(plain error) = %v, want 0", got)
+	}
+}
+
+// resetAPIErrors clears the errors recorded for /errors.
+func resetAPIErrors() {
+	recentErrorsMu.Lock()
+	recentErrors = nil
+	recentErrorsNext = 0
+	recentErrorsMu.Unlock()
+}
+
+func TestFetchBinanceKlinesBadRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     error
+		recorded bool
+	}{
+		{"unknown symbol", `{"code":-1121,"msg":"Invalid symbol."}`, ErrSymbolNotFound, false},
+		{"invalid interval", `{"code":-1120,"msg":"Invalid interval."}`, ErrInvalidRequest, true},
+		{"unreadable body", `<html>Bad Request</html>`, ErrInvalidRequest, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			resetAPIErrors()
+
+			_, err := fetchBinanceKlines("TESTUSDT", srv.URL+"/api/v3/klines?symbol=TESTUSDT")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("fetchBinanceKlines error = %v, want %v", err, tt.want)
+			}
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusBadRequest {
+				t.Errorf("fetchBinanceKlines error = %v, want a FetchError with status 400", err)
+			}
+			if recorded := len(latestAPIErrors(maxRecentErrors)) > 0; recorded != tt.recorded {
+				t.Errorf("recorded for /errors = %v, want %v", recorded, tt.recorded)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPrecision = 2
+	maxPrecision     = 8
+)
+
+var volumeUnits = []struct {
+	Size   float64
+	Suffix string
+}{
+	{1e12, "T"},
+	{1e9, "B"},
+	{1e6, "M"},
+	{1e3, "K"},
+}
+
+// formatVolume renders v with precision decimals, abbreviating thousands and
+// above with K/M/B/T suffixes (e.g. 1.23M).
+func formatVolume(v float64, precision int) string {
+	abs := math.Abs(v)
+	for _, unit := range volumeUnits {
+		if abs >= unit.Size {
+			return strconv.FormatFloat(v/unit.Size, 'f', precision, 64) + unit.Suffix
+		}
+	}
+	return strconv.FormatFloat(v, 'f', precision, 64)
+}
+
+// formatPrice renders a price with cents above 1 and four significant
+// digits below, so both BTC and sub-cent coins read sensibly.
+func formatPrice(p float64) string {
+	decimals := 2
+	if abs := math.Abs(p); abs > 0 && abs < 1 {
+		decimals = 3 - int(math.Floor(math.Log10(abs)))
+	}
+	return strconv.FormatFloat(p, 'f', decimals, 64)
+}
+
+func formatRatio(r float64, precision int) string {
+	return strconv.FormatFloat(r, 'f', precision, 64) + "x"
+}
+
+func handlePrecisionCommand(chatID int64, args string) string {
+	arg := strings.TrimSpace(args)
+	if arg == "" {
+		return fmt.Sprintf("Alerts use %d decimal places. Usage: /precision <0-%d>", getChatConfig(chatID).Precision, maxPrecision)
+	}
+
+	precision, err := strconv.Atoi(arg)
+	if err != nil || precision < 0 || precision > maxPrecision {
+		return fmt.Sprintf("Precision must be a number between 0 and %d", maxPrecision)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Precision = precision
+	})
+	return fmt.Sprintf("Alerts will use %d decimal places, e.g. %s", precision, formatVolume(1234567.891, precision))
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestFormatVolume(t *testing.T) {
+	tests := []struct {
+		v         float64
+		precision int
+		want      string
+	}{
+		{0, 2, "0.00"},
+		{999, 2, "999.00"},
+		{1000, 2, "1.00K"},
+		{1234567.891, 2, "1.23M"},
+		{2.5e9, 1, "2.5B"},
+		{3e12, 0, "3T"},
+		{-1500, 2, "-1.50K"},
+		{-12, 0, "-12"},
+		{1234567.891, 0, "1M"},
+		{1234567.891, maxPrecision, "1.23456789M"},
+	}
+
+	for _, tt := range tests {
+		if got := formatVolume(tt.v, tt.precision); got != tt.want {
+			t.Errorf("formatVolume(%g, %d) = %q, want %q", tt.v, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRatio(t *testing.T) {
+	tests := []struct {
+		r         float64
+		precision int
+		want      string
+	}{
+		{3.14159, 0, "3x"},
+		{3.14159, 2, "3.14x"},
+		{3.14159, maxPrecision, "3.14159000x"},
+		{0, 2, "0.00x"},
+		{-0.5, 1, "-0.5x"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRatio(tt.r, tt.precision); got != tt.want {
+			t.Errorf("formatRatio(%g, %d) = %q, want %q", tt.r, tt.precision, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const alertHistoryFile = "alert_history.json"
+
+// historyEntry is one alert delivered to a chat.
+type historyEntry struct {
+	ChatID int64     `json:"chat_id"`
+	Symbol string    `json:"symbol"`
+	Ratio  float64   `json:"ratio"`
+	Time   time.Time `json:"time"`
+}
+
+var (
+	alertHistory      []historyEntry
+	alertHistoryMu    sync.Mutex
+	alertHistoryDirty bool
+)
+
+func appendAlertHistory(chatID int64, symbol string, ratio float64, now time.Time) {
+	alertHistoryMu.Lock()
+	alertHistory = append(alertHistory, historyEntry{ChatID: chatID, Symbol: symbol, Ratio: ratio, Time: now})
+	alertHistoryDirty = true
+	alertHistoryMu.Unlock()
+}
+
+// chatHistory returns the chat's alerts at or after since, oldest first.
+func chatHistory(chatID int64, since time.Time) []historyEntry {
+	alertHistoryMu.Lock()
+	defer alertHistoryMu.Unlock()
+
+	var entries []historyEntry
+	for _, entry := range alertHistory {
+		if entry.ChatID == chatID && !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// lastAlertTime returns when the chat last received an alert for any
+// symbol within the retained history, which unlike the cooldown records
+// survives restarts and pruning.
+func lastAlertTime(chatID int64) (time.Time, bool) {
+	alertHistoryMu.Lock()
+	defer alertHistoryMu.Unlock()
+
+	// Entries are appended as alerts are sent, so the newest is last.
+	for i := len(alertHistory) - 1; i >= 0; i-- {
+		if alertHistory[i].ChatID == chatID {
+			return alertHistory[i].Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// pruneAlertHistory drops entries older than cutoff and returns how many
+// were removed.
+func pruneAlertHistory(cutoff time.Time) int {
+	alertHistoryMu.Lock()
+	defer alertHistoryMu.Unlock()
+
+	kept := alertHistory[:0]
+	for _, entry := range alertHistory {
+		if !entry.Time.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(alertHistory) - len(kept)
+	alertHistory = kept
+	if removed > 0 {
+		alertHistoryDirty = true
+	}
+	return removed
+}
+
+// saveAlertHistory writes the history to disk if it changed since the last
+// save.
+func saveAlertHistory() {
+	alertHistoryMu.Lock()
+	if !alertHistoryDirty {
+		alertHistoryMu.Unlock()
+		return
+	}
+	data, err := json.Marshal(alertHistory)
+	alertHistoryDirty = false
+	alertHistoryMu.Unlock()
+	if err != nil {
+		log.Printf("Error marshaling alert history: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(alertHistoryFile), data, 0644); err != nil {
+		log.Printf("Error saving alert history: %v", err)
+	}
+}
+
+func loadAlertHistory() {
+	data, err := os.ReadFile(dataPath(alertHistoryFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading alert history file: %v", err)
+		}
+		return
+	}
+
+	var loaded []historyEntry
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("Error unmarshaling alert history: %v", err)
+		return
+	}
+
+	alertHistoryMu.Lock()
+	alertHistory = loaded
+	alertHistoryMu.Unlock()
+}
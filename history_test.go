@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLastAlertTimeSurvivesRestart(t *testing.T) {
+	alertHistoryMu.Lock()
+	saved := alertHistory
+	alertHistory = nil
+	alertHistoryMu.Unlock()
+	t.Cleanup(func() {
+		alertHistoryMu.Lock()
+		alertHistory = saved
+		alertHistoryMu.Unlock()
+		os.Remove(dataPath(alertHistoryFile))
+	})
+
+	const chatID, other int64 = 9001, 9002
+	first := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	appendAlertHistory(chatID, "BTCUSDT", 6, first)
+	appendAlertHistory(chatID, "ETHUSDT", 7, first.Add(time.Hour))
+	appendAlertHistory(other, "SOLUSDT", 8, first.Add(2*time.Hour))
+	saveAlertHistory()
+
+	// A restart loses the in-memory history and cooldown records alike.
+	alertHistoryMu.Lock()
+	alertHistory = nil
+	alertHistoryMu.Unlock()
+	loadAlertHistory()
+
+	last, ok := lastAlertTime(chatID)
+	if !ok || !last.Equal(first.Add(time.Hour)) {
+		t.Errorf("lastAlertTime(%d) = %v, %v, want %v", chatID, last, ok, first.Add(time.Hour))
+	}
+	if _, ok := lastAlertTime(12345); ok {
+		t.Error("lastAlertTime of a chat without alerts reported one")
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxIntervalScans = 4
+
+	minIntervalCadence = time.Minute
+	maxIntervalCadence = 24 * time.Hour
+)
+
+// binanceIntervals lists the kline intervals an extra scan may use.
+var binanceIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w"}
+
+// IntervalScan is an extra scan of the chat's universe on its own candle
+// interval and cadence, running next to the main 1h loop. Every scan
+// fetches one kline request per symbol, so a 5m interval checked every
+// minute costs about as many requests per minute as the main loop makes in
+// a whole cycle.
+type IntervalScan struct {
+	Interval     string `json:"interval"`
+	EveryMinutes int    `json:"every_minutes"`
+}
+
+func (s IntervalScan) every() time.Duration {
+	return time.Duration(s.EveryMinutes) * time.Minute
+}
+
+func (s IntervalScan) String() string {
+	return fmt.Sprintf("%s every %s", s.Interval, s.every())
+}
+
+// validate checks that s names a Binance interval and a cadence within
+// bounds.
+func (s IntervalScan) validate() error {
+	if !containsString(binanceIntervals, s.Interval) {
+		return fmt.Errorf("interval must be one of %s", strings.Join(binanceIntervals, ", "))
+	}
+	if every := s.every(); every < minIntervalCadence || every > maxIntervalCadence {
+		return fmt.Errorf("cadence must be between %s and %s", minIntervalCadence, maxIntervalCadence)
+	}
+	return nil
+}
+
+// defaultIntervalCadence checks an interval four times per candle.
+func defaultIntervalCadence(interval string) time.Duration {
+	return max(minIntervalCadence, min(maxIntervalCadence, intervalDuration(interval)/4)).Truncate(time.Minute)
+}
+
+// parseIntervalScan parses "5m" or "5m:1m" (interval and cadence).
+func parseIntervalScan(arg string) (IntervalScan, error) {
+	interval, cadence, found := strings.Cut(arg, ":")
+	every := defaultIntervalCadence(interval)
+	if found {
+		var err error
+		if every, err = time.ParseDuration(cadence); err != nil {
+			return IntervalScan{}, fmt.Errorf("invalid cadence %q", cadence)
+		}
+	}
+
+	scan := IntervalScan{Interval: interval, EveryMinutes: int(every / time.Minute)}
+	return scan, scan.validate()
+}
+
+type intervalScanKey struct {
+	ChatID   int64
+	Interval string
+}
+
+var (
+	intervalScanners   = make(map[intervalScanKey]bool)
+	intervalScannersMu sync.Mutex
+)
+
+// ensureIntervalScanners starts a scanner for each of the chat's intervals
+// that isn't running yet.
+func ensureIntervalScanners(chatID int64) {
+	intervalScannersMu.Lock()
+	defer intervalScannersMu.Unlock()
+
+	for _, scan := range getChatConfig(chatID).Intervals {
+		key := intervalScanKey{chatID, scan.Interval}
+		if intervalScanners[key] {
+			continue
+		}
+		intervalScanners[key] = true
+		interval := scan.Interval
+		goWorker(func() { runIntervalScanner(chatID, interval) })
+	}
+}
+
+// intervalScan returns the chat's current scan settings for interval.
+func intervalScan(chatID int64, interval string) (IntervalScan, bool) {
+	for _, scan := range getChatConfig(chatID).Intervals {
+		if scan.Interval == interval {
+			return scan, true
+		}
+	}
+	return IntervalScan{}, false
+}
+
+// runIntervalScanner scans the chat on interval until the chat stops
+// monitoring or removes the interval. The cadence is re-read after every
+// scan so changes apply without a restart.
+func runIntervalScanner(chatID int64, interval string) {
+	defer func() {
+		intervalScannersMu.Lock()
+		delete(intervalScanners, intervalScanKey{chatID, interval})
+		intervalScannersMu.Unlock()
+	}()
+
+	for chatIsMonitoring(chatID) && !shuttingDown() {
+		scan, ok := intervalScan(chatID, interval)
+		if !ok {
+			return
+		}
+		if !warmingUp(chatID) {
+			scanInterval(chatID, getChatConfig(chatID), interval)
+		}
+		if !sleepUnlessShutdown(scan.every()) {
+			return
+		}
+	}
+}
+
+// scanInterval compares the last two candles of interval for each symbol
+// and alerts like the main loop does. Cooldowns are tracked per interval,
+// so a symbol may alert on 5m and 1h independently.
+func scanInterval(chatID int64, cfg ChatConfig, interval string) {
+	symbols, err := getMonitoredSymbols(cfg)
+	if err != nil {
+		log.Printf("Error getting symbols for %s scan of chat %d: %v\n", interval, chatID, err)
+		return
+	}
+
+	for _, symbol := range scanCandidates(symbols, time.Now()) {
+		if !chatIsMonitoring(chatID) || shuttingDown() {
+			return
+		}
+
+		klines, err := getBinanceKlinesInterval(symbol, interval, candlesNeeded(2, comparesClosed(cfg)))
+		if errors.Is(err, ErrSymbolNotFound) {
+			continue
+		}
+		if errors.Is(err, ErrRateLimited) {
+			wait := fetchRetryAfter(err)
+			log.Printf("Rate limited during %s scan for chat %d, waiting %s\n", interval, chatID, wait)
+			time.Sleep(wait)
+			continue
+		}
+		if errors.Is(err, ErrUpstreamUnavailable) {
+			log.Printf("Binance unavailable, ending %s scan for chat %d early: %v\n", interval, chatID, err)
+			return
+		}
+		if err != nil {
+			log.Printf("Error getting %s kline data for %s: %v\n", interval, symbol, err)
+			continue
+		}
+
+		data, err := computeVolumeData(klines, cfg.Metric, comparesClosed(cfg))
+		if err != nil {
+			log.Printf("Error getting %s volume data for %s: %v\n", interval, symbol, err)
+			continue
+		}
+		now := time.Now()
+		if data == nil || candleProgress(data.OpenTime, interval, now) < currentSettings().MinCandleProgress {
+			continue
+		}
+		if data.Ratio <= symbolThreshold(cfg, symbol) || !meetsMinVolume(cfg, symbol, data) || !candleColorMatches(cfg.CandleColor, data) || isSnoozed(chatID, symbol, now) {
+			continue
+		}
+
+		key := symbol + "@" + interval
+		if ok, escalated := checkAlert(chatID, cfg, key, data, now); ok {
+			alert := newAlert(chatID, cfg, symbol, data, escalated, now)
+			alert.BaselineLabel, alert.CurrentLabel = candleLabels(cfg, interval)
+			alert.Interval = interval
+			alert.Priority = isPrioritySymbol(cfg, symbol)
+			dispatchAlert(alert, cfg)
+			recordAlert(chatID, key, data, now)
+			appendAlertHistory(chatID, symbol, data.Ratio, now)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func handleIntervalsCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+	usage := "Usage: /intervals <interval>[:<cadence>] ..., e.g. /intervals 1d:3h 5m:1m, or /intervals off"
+
+	if len(fields) == 0 {
+		scans := getChatConfig(chatID).Intervals
+		if len(scans) == 0 {
+			return "Only the main 1h scan is running. " + usage
+		}
+		var sb strings.Builder
+		sb.WriteString("Extra interval scans:\n")
+		for _, scan := range scans {
+			sb.WriteString(scan.String() + "\n")
+		}
+		return strings.TrimRight(sb.String(), "\n")
+	}
+
+	if len(fields) == 1 && fields[0] == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Intervals = nil
+		})
+		return "Extra interval scans removed. Only the main 1h scan runs."
+	}
+
+	if len(fields) > maxIntervalScans {
+		return fmt.Sprintf("At most %d extra intervals can be scanned", maxIntervalScans)
+	}
+
+	var scans []IntervalScan
+	for _, field := range fields {
+		scan, err := parseIntervalScan(field)
+		if err != nil {
+			return fmt.Sprintf("%s: %v. %s", field, err, usage)
+		}
+		for _, existing := range scans {
+			if existing.Interval == scan.Interval {
+				return fmt.Sprintf("%s is listed twice", scan.Interval)
+			}
+		}
+		scans = append(scans, scan)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Intervals = scans
+	})
+	if chatIsMonitoring(chatID) {
+		ensureIntervalScanners(chatID)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Scanning, next to the main 1h scan:\n")
+	for _, scan := range scans {
+		sb.WriteString(scan.String() + "\n")
+	}
+	sb.WriteString("Each interval makes one request per monitored symbol per check, so fast cadences multiply the load on Binance's rate limits.")
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	klineCacheTTL  = 30 * time.Second
+	klineCacheSize = 512
+)
+
+type klineCacheEntry struct {
+	key       string
+	klines    []BinanceKline
+	fetchedAt time.Time
+}
+
+// klineCache is a small LRU of recent kline responses so monitoring and
+// on-demand commands asking for the same symbol share one request.
+type klineCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+func newKlineCache(capacity int, ttl time.Duration) *klineCache {
+	return &klineCache{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+var klineResponses = newKlineCache(klineCacheSize, klineCacheTTL)
+
+func klineCacheKey(symbol, interval string, limit int) string {
+	return fmt.Sprintf("%s:%s:%d", symbol, interval, limit)
+}
+
+func (c *klineCache) get(key string) ([]BinanceKline, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*klineCacheEntry)
+	if time.Since(entry.fetchedAt) > c.ttl {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.klines, true
+}
+
+func (c *klineCache) put(key string, value []BinanceKline) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*klineCacheEntry)
+		entry.klines = value
+		entry.fetchedAt = time.Now()
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&klineCacheEntry{key: key, klines: value, fetchedAt: time.Now()})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*klineCacheEntry).key)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// backdate makes the cached entry for key look fetched age ago.
+func backdate(c *klineCache, key string, age time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key].Value.(*klineCacheEntry).fetchedAt = time.Now().Add(-age)
+}
+
+func TestKlineCacheExpiry(t *testing.T) {
+	c := newKlineCache(4, time.Minute)
+	c.put("a", []BinanceKline{testKline(1, 1, 1)})
+
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("fresh entry missing")
+	}
+	backdate(c, "a", 2*time.Minute)
+	if _, ok := c.get("a"); ok {
+		t.Error("expired entry returned")
+	}
+	if c.order.Len() != 0 || len(c.entries) != 0 {
+		t.Error("expired entry not removed")
+	}
+}
+
+func TestKlineCacheEviction(t *testing.T) {
+	c := newKlineCache(2, time.Minute)
+	c.put("a", nil)
+	c.put("b", nil)
+	c.get("a") // a is now the most recently used
+	c.put("c", nil)
+
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("entry %s was evicted", key)
+		}
+	}
+}
+
+func TestKlineCacheRefreshOnPut(t *testing.T) {
+	c := newKlineCache(2, time.Minute)
+	c.put("a", []BinanceKline{testKline(1, 1, 1)})
+	backdate(c, "a", 2*time.Minute)
+
+	want := []BinanceKline{testKline(2, 1, 1)}
+	c.put("a", want)
+	got, ok := c.get("a")
+	if !ok {
+		t.Fatal("refreshed entry missing")
+	}
+	if len(got) != 1 || got[0][0] != want[0][0] {
+		t.Errorf("get returned %v, want the refreshed value %v", got, want)
+	}
+	if c.order.Len() != 1 {
+		t.Errorf("refresh added an entry, cache holds %d", c.order.Len())
+	}
+}
+
+func TestCachedKlinesSkipTheNetwork(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		json.NewEncoder(w).Encode([]BinanceKline{testKline(1, 10, 100), testKline(2, 10, 200)})
+	}))
+	defer srv.Close()
+
+	oldURL, oldCache := binanceBaseURL, klineResponses
+	binanceBaseURL, klineResponses = srv.URL, newKlineCache(4, time.Minute)
+	t.Cleanup(func() { binanceBaseURL, klineResponses = oldURL, oldCache })
+
+	for i := 0; i < 2; i++ {
+		klines, err := getBinanceKlinesInterval("CACHEUSDT", "1h", 2)
+		if err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		if len(klines) != 2 {
+			t.Fatalf("read %d returned %d klines, want 2", i, len(klines))
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+
+	if _, err := getBinanceKlinesInterval("CACHEUSDT", "5m", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server saw %d requests after a different interval, want 2", got)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLeaderboardPeriod = 7 * 24 * time.Hour
+	leaderboardSize          = 20
+)
+
+// leaderboardEntry aggregates one symbol's alerts over a period.
+type leaderboardEntry struct {
+	Symbol   string
+	Count    int
+	AvgRatio float64
+}
+
+// parsePeriod parses periods such as "7d", "12h" or "90m".
+func parsePeriod(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q", s)
+	}
+	return d, nil
+}
+
+// buildLeaderboard ranks symbols by alert count, then by average ratio.
+func buildLeaderboard(entries []historyEntry) []leaderboardEntry {
+	bySymbol := make(map[string]*leaderboardEntry)
+	for _, entry := range entries {
+		e, ok := bySymbol[entry.Symbol]
+		if !ok {
+			e = &leaderboardEntry{Symbol: entry.Symbol}
+			bySymbol[entry.Symbol] = e
+		}
+		e.Count++
+		e.AvgRatio += entry.Ratio
+	}
+
+	board := make([]leaderboardEntry, 0, len(bySymbol))
+	for _, e := range bySymbol {
+		e.AvgRatio /= float64(e.Count)
+		board = append(board, *e)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Count != board[j].Count {
+			return board[i].Count > board[j].Count
+		}
+		if board[i].AvgRatio != board[j].AvgRatio {
+			return board[i].AvgRatio > board[j].AvgRatio
+		}
+		return board[i].Symbol < board[j].Symbol
+	})
+	return board
+}
+
+func handleLeaderboardCommand(chatID int64, args string) string {
+	period, label := defaultLeaderboardPeriod, "7d"
+	if arg := strings.ToLower(strings.TrimSpace(args)); arg != "" {
+		var err error
+		label = arg
+		if period, err = parsePeriod(arg); err != nil {
+			return "Usage: /leaderboard [period], e.g. /leaderboard 7d or /leaderboard 12h"
+		}
+	}
+	if retention := currentSettings().HistoryRetention; period > retention {
+		return fmt.Sprintf("Alert history only goes back %s", retention)
+	}
+
+	board := buildLeaderboard(chatHistory(chatID, time.Now().Add(-period)))
+	if len(board) == 0 {
+		return fmt.Sprintf("No alerts in the last %s.", label)
+	}
+	if len(board) > leaderboardSize {
+		board = board[:leaderboardSize]
+	}
+
+	precision := getChatConfig(chatID).Precision
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Most alerted symbols in the last %s:\n", label)
+	for i, e := range board {
+		fmt.Fprintf(&sb, "%2d. %-12s %3d alerts, avg %s\n", i+1, e.Symbol, e.Count, formatRatio(e.AvgRatio, precision))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxFetchLimit caps /concurrency; Binance's request weight limits make
+// more parallel requests counterproductive.
+const maxFetchLimit = 32
+
+// The fetch limiter bounds how many Binance requests run at once across all
+// chats. Each chat runs its own monitoring loop, so without it the request
+// rate grows with the number of subscribers. The limit can be changed while
+// requests are in flight.
+var (
+	fetchLimit   int
+	fetchInUse   int
+	fetchLimitMu sync.Mutex
+	fetchFree    = sync.NewCond(&fetchLimitMu)
+)
+
+func initFetchLimiter(limit int) {
+	if limit < 1 {
+		limit = 1
+	}
+	setFetchLimit(limit)
+}
+
+// setFetchLimit changes the number of concurrent fetches. Lowering it takes
+// effect as in-flight requests finish.
+func setFetchLimit(limit int) {
+	fetchLimitMu.Lock()
+	fetchLimit = limit
+	fetchLimitMu.Unlock()
+	fetchFree.Broadcast()
+}
+
+func currentFetchLimit() int {
+	fetchLimitMu.Lock()
+	defer fetchLimitMu.Unlock()
+	return fetchLimit
+}
+
+func acquireFetchSlot() {
+	fetchLimitMu.Lock()
+	for fetchInUse >= fetchLimit {
+		fetchFree.Wait()
+	}
+	fetchInUse++
+	fetchLimitMu.Unlock()
+}
+
+func releaseFetchSlot() {
+	fetchLimitMu.Lock()
+	fetchInUse--
+	fetchLimitMu.Unlock()
+	fetchFree.Signal()
+}
+
+func handleConcurrencyCommand(chatID int64, args string) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+
+	arg := strings.TrimSpace(args)
+	if arg == "" {
+		return fmt.Sprintf("Up to %d Binance requests run concurrently.", currentFetchLimit())
+	}
+
+	limit, err := strconv.Atoi(arg)
+	if err != nil || limit < 1 || limit > maxFetchLimit {
+		return fmt.Sprintf("Concurrency must be a number between 1 and %d", maxFetchLimit)
+	}
+
+	setFetchLimit(limit)
+	return fmt.Sprintf("Concurrency set to %d. This lasts until the bot restarts; set MAX_CONCURRENT_FETCHES to keep it.", limit)
+}
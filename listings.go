@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	knownSymbolsFile = "known_symbols.json"
+
+	// listingsCheckInterval matches exchangeInfoTTL, since exchangeInfo is
+	// not refetched more often than that anyway.
+	listingsCheckInterval = exchangeInfoTTL
+)
+
+var (
+	// knownSymbols is every trading symbol seen so far. It is nil until the
+	// first successful check, which records the listings without alerting.
+	knownSymbols   map[string]bool
+	knownSymbolsMu sync.Mutex
+)
+
+// newListings returns the trading symbols in info that are not in known,
+// sorted.
+func newListings(known map[string]bool, info map[string]SymbolInfo) []string {
+	var listed []string
+	for name, s := range info {
+		if s.Status == "TRADING" && !known[name] {
+			listed = append(listed, name)
+		}
+	}
+	sort.Strings(listed)
+	return listed
+}
+
+// startListingWatcher checks exchangeInfo for new trading pairs every
+// listingsCheckInterval and announces them to the chats that opted in.
+func startListingWatcher() {
+	go func() {
+		for {
+			checkNewListings()
+			if !sleepUnlessShutdown(listingsCheckInterval) {
+				return
+			}
+		}
+	}()
+}
+
+func checkNewListings() {
+	info, err := getExchangeInfo()
+	if err != nil {
+		log.Printf("Error checking for new listings: %v", err)
+		return
+	}
+
+	knownSymbolsMu.Lock()
+	first := knownSymbols == nil
+	var listed []string
+	if first {
+		knownSymbols = make(map[string]bool, len(info))
+	} else {
+		listed = newListings(knownSymbols, info)
+	}
+	for name, s := range info {
+		if s.Status == "TRADING" {
+			knownSymbols[name] = true
+		}
+	}
+	knownSymbolsMu.Unlock()
+
+	if first {
+		log.Printf("Recorded %d listed symbols; later listings will be announced", len(info))
+	}
+	if first || len(listed) > 0 {
+		saveKnownSymbols()
+	}
+	if len(listed) == 0 {
+		return
+	}
+
+	log.Printf("New Binance listings: %s", strings.Join(listed, ", "))
+	var sb strings.Builder
+	sb.WriteString("🆕 New on Binance:\n")
+	for _, name := range listed {
+		s := info[name]
+		fmt.Fprintf(&sb, "%s (%s/%s)\n", name, s.BaseAsset, s.QuoteAsset)
+	}
+	text := strings.TrimRight(sb.String(), "\n")
+
+	for chatID, cfg := range allChatConfigs() {
+		if !cfg.NewListings {
+			continue
+		}
+		if err := sendText(chatID, text, false); err != nil {
+			if isBlockedError(err) {
+				dropSubscription(chatID, err)
+				continue
+			}
+			log.Printf("Error sending new listings to chat %d: %v", chatID, err)
+		}
+	}
+}
+
+func saveKnownSymbols() {
+	knownSymbolsMu.Lock()
+	if knownSymbols == nil {
+		knownSymbolsMu.Unlock()
+		return
+	}
+	symbols := make([]string, 0, len(knownSymbols))
+	for name := range knownSymbols {
+		symbols = append(symbols, name)
+	}
+	knownSymbolsMu.Unlock()
+	sort.Strings(symbols)
+
+	data, err := json.Marshal(symbols)
+	if err != nil {
+		log.Printf("Error marshaling known symbols: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(knownSymbolsFile), data, 0644); err != nil {
+		log.Printf("Error saving known symbols: %v", err)
+	}
+}
+
+func loadKnownSymbols() {
+	data, err := os.ReadFile(dataPath(knownSymbolsFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading known symbols file: %v", err)
+		}
+		return
+	}
+
+	var symbols []string
+	if err := json.Unmarshal(data, &symbols); err != nil {
+		log.Printf("Error unmarshaling known symbols: %v", err)
+		return
+	}
+
+	known := make(map[string]bool, len(symbols))
+	for _, name := range symbols {
+		known[name] = true
+	}
+	knownSymbolsMu.Lock()
+	knownSymbols = known
+	knownSymbolsMu.Unlock()
+}
+
+func handleNewListingsCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.NewListings = true
+		})
+		return fmt.Sprintf("You'll get a message when a new pair starts trading on Binance (checked every %s).", listingsCheckInterval)
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.NewListings = false
+		})
+		return "New listing messages disabled."
+	default:
+		return "Usage: /newlistings on|off"
+	}
+}
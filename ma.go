@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maTypeSMA = "sma"
+	maTypeEMA = "ema"
+
+	minMAWindow = 2
+	maxMAWindow = 500
+)
+
+// simpleMovingAverage returns the arithmetic mean of values.
+func simpleMovingAverage(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// exponentialMovingAverage returns the EMA of values, oldest first, using the
+// usual 2/(n+1) smoothing factor and seeding with the first value.
+func exponentialMovingAverage(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	alpha := 2 / float64(len(values)+1)
+	ema := values[0]
+	for _, v := range values[1:] {
+		ema = alpha*v + (1-alpha)*ema
+	}
+	return ema
+}
+
+// movingAverage dispatches to the average selected by maType.
+func movingAverage(maType string, values []float64) (float64, error) {
+	switch maType {
+	case maTypeSMA:
+		return simpleMovingAverage(values), nil
+	case maTypeEMA:
+		return exponentialMovingAverage(values), nil
+	default:
+		return 0, fmt.Errorf("unknown moving average type %q", maType)
+	}
+}
+
+// getBinanceMAVolume compares the latest candle's metric (the last closed
+// one if closed is set) against the moving average of the window candles
+// before it. PrevVolume holds the average. A persisted baseline for the
+// compared candle is reused so only the latest klines have to be fetched.
+func getBinanceMAVolume(symbol, maType string, window int, metric string, closed bool) (*VolumeData, error) {
+	key := baselineKey(symbol, metric, maType, window)
+	field := metricField(metric)
+	live := 0
+	if closed {
+		live = 1
+	}
+
+	if b, ok := lookupBaseline(key); ok {
+		klines, err := getBinanceKlines(symbol, 1+live)
+		if err != nil {
+			return nil, err
+		}
+		if len(klines) == 1+live && klineOpenTime(klines[0]) == b.OpenTime {
+			currVolume, err := klineFloat(klines[0], field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid current %s: %v", metric, err)
+			}
+			quoteVolume, priceChangePct, typicalPrice := candleStats(klines[0])
+			return &VolumeData{
+				PrevVolume:     b.Value,
+				CurrVolume:     currVolume,
+				Ratio:          currVolume / b.Value,
+				OpenTime:       time.UnixMilli(b.OpenTime),
+				QuoteVolume:    quoteVolume,
+				PriceChangePct: priceChangePct,
+				TypicalPrice:   typicalPrice,
+			}, nil
+		}
+	}
+
+	klines, err := getBinanceKlines(symbol, window+1+live)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(klines) < window+1+live {
+		return nil, fmt.Errorf("insufficient kline data")
+	}
+	klines = klines[:window+1]
+
+	volumes := make([]float64, 0, window)
+	for _, kline := range klines[:window] {
+		volume, err := klineFloat(kline, field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", metric, err)
+		}
+		volumes = append(volumes, volume)
+	}
+	currVolume, err := klineFloat(klines[window], field)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current %s: %v", metric, err)
+	}
+
+	avg, err := movingAverage(maType, volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	if avg == 0 {
+		return nil, nil
+	}
+
+	storeBaseline(key, volumeBaseline{
+		Value:     avg,
+		OpenTime:  klineOpenTime(klines[window]),
+		UpdatedAt: time.Now(),
+	})
+
+	quoteVolume, priceChangePct, typicalPrice := candleStats(klines[window])
+
+	return &VolumeData{
+		PrevVolume:     avg,
+		CurrVolume:     currVolume,
+		Ratio:          currVolume / avg,
+		OpenTime:       time.UnixMilli(klineOpenTime(klines[window])),
+		QuoteVolume:    quoteVolume,
+		PriceChangePct: priceChangePct,
+		TypicalPrice:   typicalPrice,
+	}, nil
+}
+
+// klineOpenTime returns the open time of a kline in milliseconds.
+func klineOpenTime(kline BinanceKline) int64 {
+	openTime, _ := kline[0].(float64)
+	return int64(openTime)
+}
+
+// maLabel describes the baseline used by cfg, e.g. "SMA(20)".
+func maLabel(cfg ChatConfig) string {
+	return fmt.Sprintf("%s(%d)", strings.ToUpper(cfg.MAType), cfg.MAWindow)
+}
+
+func handleMACommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+
+	if len(fields) == 1 && fields[0] == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.MAType = ""
+			cfg.MAWindow = 0
+		})
+		return "Moving average mode disabled. Comparing against the previous candle."
+	}
+
+	if len(fields) != 2 || (fields[0] != maTypeSMA && fields[0] != maTypeEMA) {
+		return "Usage: /ma sma|ema <window>, or /ma off"
+	}
+
+	window, err := strconv.Atoi(fields[1])
+	if err != nil || window < minMAWindow || window > maxMAWindow {
+		return fmt.Sprintf("Window must be a number between %d and %d", minMAWindow, maxMAWindow)
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.MAType = fields[0]
+		cfg.MAWindow = window
+	})
+	return fmt.Sprintf("Alerting when volume exceeds the %s by %gx", maLabel(cfg), cfg.Threshold)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		maType string
+		values []float64
+		want   float64
+	}{
+		{"sma", maTypeSMA, []float64{1, 2, 3, 4}, 2.5},
+		{"sma single", maTypeSMA, []float64{7}, 7},
+		{"sma empty", maTypeSMA, nil, 0},
+		// alpha = 2/4: 2 → 0.5*4+0.5*2 = 3 → 0.5*8+0.5*3 = 5.5
+		{"ema", maTypeEMA, []float64{2, 4, 8}, 5.5},
+		{"ema seeds with first value", maTypeEMA, []float64{10}, 10},
+		{"ema constant", maTypeEMA, []float64{3, 3, 3, 3}, 3},
+		{"ema empty", maTypeEMA, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := movingAverage(tt.maType, tt.values)
+			if err != nil {
+				t.Fatalf("movingAverage(%q, %v) returned error: %v", tt.maType, tt.values, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("movingAverage(%q, %v) = %g, want %g", tt.maType, tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovingAverageUnknownType(t *testing.T) {
+	if _, err := movingAverage("wma", []float64{1, 2}); err == nil {
+		t.Error("movingAverage with an unknown type returned no error")
+	}
+}
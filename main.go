@@ -2,10 +2,11 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -13,7 +14,6 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"github.com/joho/godotenv"
 )
 
 type CoinGeckoResponse struct {
@@ -26,6 +26,16 @@ type VolumeData struct {
 	PrevVolume float64
 	CurrVolume float64
 	Ratio      float64
+	OpenTime   time.Time
+	// QuoteVolume, PriceChangePct and TypicalPrice describe the current
+	// candle. TypicalPrice is (high + low + close) / 3, roughly where its
+	// volume traded.
+	QuoteVolume    float64
+	PriceChangePct float64
+	TypicalPrice   float64
+	// Acceleration is how fast the ratio is rising per candle, only
+	// measured for chats with acceleration alerts.
+	Acceleration float64
 }
 
 var (
@@ -37,43 +47,76 @@ const (
 	statusFile = "monitoring_status.json"
 )
 
-func init() {
+// statusStore persists monitoringStatus.
+var statusStore Store = newJSONStore(statusFile)
+
+// setup loads the settings and connects every configured bot.
+func setup() {
 	var err error
 
-	if err = godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
+	captureExternalEnv()
+	// .env is optional; containers usually inject the variables directly.
+	if err = loadEnvFile(); err != nil {
+		if os.IsNotExist(err) {
+			log.Println("No .env file found, using environment variables only")
+		} else {
+			log.Fatalf("Error loading .env file: %v", err)
+		}
 	}
 
-	botToken := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if botToken == "" {
+	loaded := loadSettings()
+	settings.Store(&loaded)
+	if err := configureProxy(loaded.ProxyURL); err != nil {
+		log.Fatal(err)
+	}
+	initFetchLimiter(loaded.MaxConcurrentFetches)
+
+	tokens := botTokens()
+	if len(tokens) == 0 {
 		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is not set")
 	}
 
-	bot, err = tgbotapi.NewBotAPI(botToken)
-	if err != nil {
-		log.Fatal(err)
+	for _, token := range tokens {
+		b, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, httpClient)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Authorized on account %s", b.Self.UserName)
+		bots = append(bots, b)
 	}
+	bot = bots[0]
 
-	log.Printf("Authorized on account %s", bot.Self.UserName)
+	configureBinanceEndpoint()
 }
 
-func getMarketCapRank() ([]string, error) {
-	url := "https://api.coingecko.com/api/v3/coins/markets?vs_currency=usd&order=market_cap_desc&per_page=100&page=1&sparkline=false"
+func getMarketCapRank(category, order string) ([]string, error) {
+	query := url.Values{
+		"vs_currency": {"usd"},
+		"order":       {order},
+		"per_page":    {strconv.Itoa(maxTierSymbols)},
+		"page":        {"1"},
+		"sparkline":   {"false"},
+	}
+	if category != "" {
+		query.Set("category", category)
+	}
 
-	resp, err := http.Get(url)
+	resp, err := httpClient.Get("https://api.coingecko.com/api/v3/coins/markets?" + query.Encode())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get market cap rank: %v", err)
+		return nil, requestError("coingecko markets", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+	if category != "" && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest) {
+		return nil, errUnknownCategory
+	}
+	if err := responseError("coingecko markets", resp); err != nil {
+		return nil, err
 	}
 
 	var coins []CoinGeckoResponse
-	if err := json.Unmarshal(body, &coins); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	if err := json.NewDecoder(resp.Body).Decode(&coins); err != nil {
+		return nil, decodeError("coingecko markets", err)
 	}
 
 	var symbols []string
@@ -85,175 +128,545 @@ func getMarketCapRank() ([]string, error) {
 	return symbols, nil
 }
 
-func getBinanceVolume(symbol string) (*VolumeData, error) {
-	url := fmt.Sprintf("https://api.binance.com/api/v3/klines?symbol=%s&interval=1h&limit=2", symbol)
+func getBinanceKlines(symbol string, limit int) ([]BinanceKline, error) {
+	return getBinanceKlinesInterval(symbol, klineInterval, limit)
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get kline data: %v", err)
+func getBinanceKlinesInterval(symbol, interval string, limit int) ([]BinanceKline, error) {
+	cacheKey := klineCacheKey(symbol, interval, limit)
+	if cached, ok := klineResponses.get(cacheKey); ok {
+		return cached, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 400 {
-		return nil, nil
+	url := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d", binanceBaseURL, symbol, interval, limit)
+	klines, err := fetchBinanceKlines(symbol, url)
+	if err != nil {
+		return nil, err
 	}
+	klineResponses.put(cacheKey, klines)
+	return klines, nil
+}
+
+// fetchBinanceKlines requests url from the klines endpoint and validates
+// the response.
+func fetchBinanceKlines(symbol, url string) ([]BinanceKline, error) {
+	acquireFetchSlot()
+	defer releaseFetchSlot()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	resp, err := httpClient.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, requestError("binance klines", err)
+	}
+	defer resp.Body.Close()
+
+	// Binance answers unknown symbols with 400 rather than 404, but so it
+	// does malformed requests; only code -1121 means the symbol is unknown.
+	// binanceBadRequest records the malformed ones; unknown symbols go to
+	// the skip list instead.
+	if resp.StatusCode == http.StatusBadRequest {
+		fetchErr := binanceBadRequest("binance klines", resp)
+		if errors.Is(fetchErr, ErrSymbolNotFound) {
+			noteSymbolNotFound(symbol, time.Now())
+		}
+		return nil, fetchErr
+	}
+	if err := responseError("binance klines", resp); err != nil {
+		return nil, err
 	}
 
 	var klines []BinanceKline
-	if err := json.Unmarshal(body, &klines); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal klines: %v", err)
+	if err := json.NewDecoder(resp.Body).Decode(&klines); err != nil {
+		return nil, decodeError("binance klines", err)
+	}
+
+	if err := validateKlines(klines); err != nil {
+		reportSchemaMismatch(symbol, err)
+		return nil, decodeError("binance klines", err)
 	}
 
+	noteSymbolFound(symbol)
+	return klines, nil
+}
+
+func getBinanceVolume(symbol, metric string, closed bool) (*VolumeData, error) {
+	klines, err := getBinanceKlines(symbol, candlesNeeded(2, closed))
+	if err != nil {
+		return nil, err
+	}
+
+	return computeVolumeData(klines, metric, closed)
+}
+
+// computeVolumeData compares the metric (volume, trades or taker buy
+// volume) of two consecutive klines, given oldest first as Binance returns
+// them, whose last entry is the candle still in progress. With closed set
+// it compares the last closed candle against the one before it and ignores
+// the open candle; otherwise it compares the open candle against the last
+// closed one. It returns nil without an error when the previous value is
+// zero, since no meaningful ratio exists.
+func computeVolumeData(klines []BinanceKline, metric string, closed bool) (*VolumeData, error) {
+	klines = comparedKlines(klines, closed)
 	if len(klines) < 2 {
 		return nil, fmt.Errorf("insufficient kline data")
 	}
 
-	prevVolume, _ := strconv.ParseFloat(klines[0][5].(string), 64)
-	currVolume, _ := strconv.ParseFloat(klines[1][5].(string), 64)
+	prev, curr := klines[len(klines)-2], klines[len(klines)-1]
+	field := metricField(metric)
+
+	prevVolume, err := klineFloat(prev, field)
+	if err != nil {
+		return nil, fmt.Errorf("invalid previous %s: %v", metric, err)
+	}
+	currVolume, err := klineFloat(curr, field)
+	if err != nil {
+		return nil, fmt.Errorf("invalid current %s: %v", metric, err)
+	}
+	openTime, err := klineFloat(curr, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid open time: %v", err)
+	}
 
 	if prevVolume == 0 {
 		return nil, nil
 	}
 
-	ratio := currVolume / prevVolume
+	quoteVolume, priceChangePct, typicalPrice := candleStats(curr)
 
 	return &VolumeData{
-		PrevVolume: prevVolume,
-		CurrVolume: currVolume,
-		Ratio:      ratio,
+		PrevVolume:     prevVolume,
+		CurrVolume:     currVolume,
+		Ratio:          currVolume / prevVolume,
+		OpenTime:       time.UnixMilli(int64(openTime)),
+		QuoteVolume:    quoteVolume,
+		PriceChangePct: priceChangePct,
+		TypicalPrice:   typicalPrice,
 	}, nil
 }
 
-func sendAlert(chatID int64, symbol string, data *VolumeData) {
-	message := fmt.Sprintf("⚠️ Volume Alert for %s\n"+
-		"Previous Hour Volume: %.2f\n"+
-		"Current Hour Volume: %.2f\n"+
-		"Volume Ratio: %.2fx\n"+
-		"Time: %s",
-		symbol,
-		data.PrevVolume,
-		data.CurrVolume,
-		data.Ratio,
-		time.Now().Format("2006-01-02 15:04:05"))
-
-	msg := tgbotapi.NewMessage(chatID, message)
-	if _, err := bot.Send(msg); err != nil {
-		log.Printf("Error sending alert: %v", err)
+// candleStats returns a kline's quote-asset volume, its open-to-close
+// price change in percent and its typical price, the mean of high, low and
+// close.
+func candleStats(kline BinanceKline) (quoteVolume, priceChangePct, typicalPrice float64) {
+	quoteVolume, _ = klineFloat(kline, 7)
+	open, _ := klineFloat(kline, 1)
+	high, _ := klineFloat(kline, 2)
+	low, _ := klineFloat(kline, 3)
+	closePrice, _ := klineFloat(kline, 4)
+	if open != 0 {
+		priceChangePct = (closePrice - open) / open * 100
 	}
+	return quoteVolume, priceChangePct, (high + low + closePrice) / 3
 }
 
-func saveMonitoringStatus() {
-	statusMap := make(map[int64]bool)
+// klineFloat reads field i of a kline as a number. Binance encodes prices
+// and volumes as strings and timestamps as numbers, so both are accepted.
+func klineFloat(kline BinanceKline, i int) (float64, error) {
+	if i >= len(kline) {
+		return 0, fmt.Errorf("field %d missing", i)
+	}
 
-	monitoringStatus.Range(func(key, value interface{}) bool {
-		chatID := key.(int64)
-		status := value.(bool)
-		statusMap[chatID] = status
-		return true
-	})
+	switch v := kline[i].(type) {
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("field %d has unexpected type %T", i, v)
+	}
+}
 
-	data, err := json.Marshal(statusMap)
-	if err != nil {
-		log.Printf("Error marshaling monitoring status: %v", err)
-		return
+// sendAlert delivers an alert to the chat and any extra notifiers it has
+// configured.
+func sendAlert(alert Alert) {
+	cfg := getChatConfig(alert.ChatID)
+	applyDisplayCurrency(&alert, cfg)
+	applySparkline(&alert, cfg)
+	applySeverity(&alert, cfg)
+
+	if err := deliverAlert(enqueueAlert(alert, time.Now())); !isBlockedError(err) {
+		notifyAll(cfg, alert)
 	}
+}
 
-	err = ioutil.WriteFile(statusFile, data, 0644)
-	if err != nil {
+// saveMonitoringStatus persists the chat's current monitoring status.
+func saveMonitoringStatus(chatID int64) {
+	if err := statusStore.SaveChat(chatID, chatIsMonitoring(chatID)); err != nil {
 		log.Printf("Error saving monitoring status: %v", err)
 	}
 }
 
 func loadMonitoringStatus() {
-	data, err := ioutil.ReadFile(statusFile)
+	statusMap, err := statusStore.LoadAll()
 	if err != nil {
-		if !os.IsNotExist(err) {
-			log.Printf("Error reading monitoring status file: %v", err)
-		}
-		return
-	}
-
-	statusMap := make(map[int64]bool)
-	err = json.Unmarshal(data, &statusMap)
-	if err != nil {
-		log.Printf("Error unmarshaling monitoring status: %v", err)
+		log.Printf("Error loading monitoring status: %v", err)
 		return
 	}
 
+	var active []int64
 	for chatID, status := range statusMap {
 		monitoringStatus.Store(chatID, status)
 		if status {
-			go startMonitoring(chatID)
+			active = append(active, chatID)
 		}
 	}
+	restoreMonitors(active)
 }
 
 func startMonitoring(chatID int64) {
 	monitoringStatus.Store(chatID, true)
-	saveMonitoringStatus()
-	msg := tgbotapi.NewMessage(chatID, "Volume monitoring started! You will receive alerts when volume increases more than 5x.")
-	bot.Send(msg)
+	saveMonitoringStatus(chatID)
+	startText := fmt.Sprintf("Volume monitoring started! You will receive alerts when volume increases more than %gx.", getChatConfig(chatID).Threshold)
+	if currentSettings().WarmupCycles > 0 {
+		startText += fmt.Sprintf(" Alerts begin after a warmup of %d scan(s) while baselines settle.", currentSettings().WarmupCycles)
+	}
+	msg := tgbotapi.NewMessage(chatID, startText)
+	send(botFor(chatID), msg)
+	warmupScans.Store(chatID, currentSettings().WarmupCycles)
+	ensureIntervalScanners(chatID)
 
 	for {
-		monitoring, _ := monitoringStatus.Load(chatID)
-		if !monitoring.(bool) {
+		if !chatIsMonitoring(chatID) {
 			return
 		}
 
-		symbols, err := getMarketCapRank()
+		cfg := expireBoost(chatID, getChatConfig(chatID), time.Now())
+
+		symbols, err := getMonitoredSymbols(cfg)
 		if err != nil {
 			log.Printf("Error getting market cap rank: %v\n", err)
-			time.Sleep(5 * time.Minute)
+			if !sleepUnlessShutdown(5 * time.Minute) {
+				return
+			}
 			continue
 		}
 
-		for _, symbol := range symbols {
-			monitoring, _ := monitoringStatus.Load(chatID)
-			if !monitoring.(bool) {
+		results, ok := scanVolumes(chatID, cfg, symbols, func() bool {
+			return chatIsMonitoring(chatID) && !shuttingDown()
+		})
+		if !ok {
+			return
+		}
+
+		if cfg.Dashboard {
+			updateDashboard(chatID, cfg, results)
+		}
+
+		if inWarmup(chatID) {
+			log.Printf("Warmup scan completed for chat %d at %s\n", chatID, time.Now().Format("2006-01-02 15:04:05"))
+			saveBaselines()
+			saveSkippedSymbols()
+			saveAlertHistory()
+			if !sleepUnlessShutdown(scanWait(cfg, time.Now())) {
 				return
 			}
+			continue
+		}
+
+		marketRatio := averageRatio(results)
+
+		var cond condition
+		if cfg.Condition != "" {
+			if cond, err = parseCondition(cfg.Condition); err != nil {
+				log.Printf("Invalid condition for chat %d: %v\n", chatID, err)
+			}
+		}
+
+		var alertedBases map[string]bool
+		if cfg.AlertPer == alertPerBase {
+			results = orderForPerBaseAlerts(results)
+			alertedBases = make(map[string]bool)
+		}
 
-			volumeData, err := getBinanceVolume(symbol)
-			if err != nil {
-				log.Printf("Error getting volume data for %s: %v\n", symbol, err)
+		var triggers []recentTrigger
+		var digest []Alert
+		paused := false
+		for _, result := range results {
+			symbol, volumeData := result.Symbol, result.Data
+			now := time.Now()
+
+			threshold, volatility := effectiveThreshold(cfg, symbol, volumeData.Ratio)
+			if cond != nil {
+				if !cond.eval(volumeMetrics(volumeData)) {
+					continue
+				}
+			} else if volumeData.Ratio <= threshold && !accelerating(cfg, volumeData) {
+				if currentSettings().LogRatio > 0 && volumeData.Ratio > currentSettings().LogRatio {
+					log.Printf("Near miss for chat %d: %s at %.2fx (threshold %gx)\n", chatID, symbol, volumeData.Ratio, threshold)
+				}
 				continue
 			}
-
-			if volumeData != nil && volumeData.Ratio > 5 {
-				sendAlert(chatID, symbol, volumeData)
+			if cfg.MarketRelative && !exceedsMarket(volumeData.Ratio, marketRatio) {
+				continue
+			}
+			if !candleColorMatches(cfg.CandleColor, volumeData) {
+				continue
 			}
 
-			time.Sleep(100 * time.Millisecond)
+			priority := isPrioritySymbol(cfg, symbol)
+			if isSnoozed(chatID, symbol, now) {
+				continue
+			}
+			wash := cfg.WashTrading != washTradingOff && suspectWashTrading(cfg, symbol)
+			if wash && cfg.WashTrading == washTradingSuppress && !priority {
+				log.Printf("Suppressed possible wash trading for chat %d: %s at %.2fx\n", chatID, symbol, volumeData.Ratio)
+				continue
+			}
+			if alertedBases != nil {
+				// The most liquid qualifying pair claims its base even
+				// when its cooldown holds the alert back, so a less
+				// liquid pair can't report the same move instead.
+				base := symbolBaseAsset(symbol)
+				if alertedBases[base] {
+					continue
+				}
+				alertedBases[base] = true
+			}
+			if ok, escalated := checkAlert(chatID, cfg, symbol, volumeData, now); ok || priority {
+				alert := newAlert(chatID, cfg, symbol, volumeData, escalated, now)
+				alert.Priority = priority
+				alert.WashTrading = wash
+				if volatility > 0 {
+					alert.Threshold = threshold
+					alert.Volatility = volatility
+				}
+				if cfg.MarketRelative {
+					alert.MarketRatio = marketRatio
+				}
+				if previous, ok := previousRatio(chatID, symbol); ok {
+					alert.PreviousRatio = previous
+				}
+				if cfg.Digest != digestOff && !priority {
+					digest = append(digest, alert)
+				} else {
+					dispatchAlert(alert, cfg)
+				}
+				recordAlert(chatID, symbol, volumeData, now)
+				appendAlertHistory(chatID, symbol, volumeData.Ratio, now)
+				if cfg.TrackEffectiveness {
+					trackEffectiveness(chatID, symbol, cfg.Metric, volumeData)
+				}
+				if !priority {
+					paused = noteAlertForAutoPause(chatID, cfg.AutoPause, now)
+				}
+			}
+			triggers = append(triggers, recentTrigger{Symbol: symbol, Ratio: volumeData.Ratio})
+			if paused {
+				break
+			}
 		}
 
+		storeLastRatios(chatID, results, time.Now())
+		if len(digest) > 0 {
+			sendDigest(chatID, digest)
+		}
+		if paused {
+			storeRecentScan(chatID, triggers)
+			autoPauseMonitoring(chatID, cfg.AutoPause)
+			return
+		}
+		storeRecentScan(chatID, triggers)
+		checkCrossAlerts(chatID, cfg)
+		checkBreakouts(chatID, cfg, results)
+		checkRangeSpikes(chatID, cfg, results)
+		checkRVOL(chatID, cfg, results)
+		checkBaskets(chatID, cfg, results)
+		evaluateEffectiveness(chatID, time.Now())
+		saveBaselines()
+		saveSkippedSymbols()
+		saveAlertHistory()
 		log.Printf("Check completed for chat %d at %s\n", chatID, time.Now().Format("2006-01-02 15:04:05"))
-		time.Sleep(5 * time.Minute)
+		if !sleepUnlessShutdown(scanWait(cfg, time.Now())) {
+			return
+		}
+	}
+}
+
+// scanVolumes fetches the volume data of symbols as configured by cfg,
+// leaving out symbols Binance doesn't list and candles that have barely
+// started. It gives up, returning false, as soon as keepGoing does.
+func scanVolumes(chatID int64, cfg ChatConfig, symbols []string, keepGoing func() bool) ([]symbolVolume, bool) {
+	var tickers map[string]ticker24h
+	if cfg.Daily && dailySupported(cfg.Metric) {
+		var err error
+		if tickers, err = getBulkTickers(); err != nil {
+			log.Printf("Error getting 24h tickers, falling back to klines: %v\n", err)
+		}
+	}
+
+	var results []symbolVolume
+	for _, symbol := range scanCandidates(symbols, time.Now()) {
+		if !keepGoing() {
+			return nil, false
+		}
+
+		var volumeData *VolumeData
+		var err error
+		if tickers != nil {
+			volumeData, err = getDailyVolume(symbol, cfg.Metric, tickers, time.Now())
+		} else if cfg.MAType != "" {
+			volumeData, err = getBinanceMAVolume(symbol, cfg.MAType, cfg.MAWindow, cfg.Metric, comparesClosed(cfg))
+		} else {
+			volumeData, err = getBinanceVolume(symbol, cfg.Metric, comparesClosed(cfg))
+		}
+		if errors.Is(err, ErrSymbolNotFound) {
+			continue
+		}
+		if errors.Is(err, ErrRateLimited) {
+			wait := fetchRetryAfter(err)
+			log.Printf("Rate limited while scanning for chat %d, waiting %s\n", chatID, wait)
+			time.Sleep(wait)
+			continue
+		}
+		if errors.Is(err, ErrUpstreamUnavailable) {
+			log.Printf("Binance unavailable, ending scan for chat %d early: %v\n", chatID, err)
+			break
+		}
+		if err != nil {
+			log.Printf("Error getting volume data for %s: %v\n", symbol, err)
+			continue
+		}
+
+		if volumeData != nil && !meetsMinVolume(cfg, symbol, volumeData) {
+			volumeData = nil
+		}
+
+		if volumeData != nil && cfg.Acceleration > 0 && tickers == nil {
+			if volumeData.Acceleration, err = getVolumeAcceleration(symbol, cfg.Metric, comparesClosed(cfg)); err != nil {
+				log.Printf("Error getting volume acceleration for %s: %v\n", symbol, err)
+			}
+		}
+
+		if volumeData != nil && candleProgress(volumeData.OpenTime, klineInterval, time.Now()) >= currentSettings().MinCandleProgress {
+			results = append(results, symbolVolume{Symbol: symbol, Data: volumeData})
+		}
+
+		if tickers == nil {
+			time.Sleep(100 * time.Millisecond)
+		}
 	}
+	return results, true
 }
 
 func stopMonitoring(chatID int64) {
 	monitoringStatus.Store(chatID, false)
-	saveMonitoringStatus()
+	saveMonitoringStatus(chatID)
 	msg := tgbotapi.NewMessage(chatID, "Volume monitoring stopped!")
-	bot.Send(msg)
+	send(botFor(chatID), msg)
 }
 
-func handleCommands() {
+// helpText lists the user commands, shown by /start and /help.
+const helpText = "Available commands:\n" +
+	"/monitor - Start volume monitoring\n" +
+	"/stop - Stop volume monitoring\n" +
+	"/status - Check monitoring status and settings\n" +
+	"/ma sma|ema <window> - Compare against a moving average (/ma off to disable)\n" +
+	"/recent - List symbols that triggered in the last scan\n" +
+	"/category <id> - Monitor a CoinGecko category (/category off to disable)\n" +
+	"/categories [page] - List available category IDs\n" +
+	"/precision <n> - Set the decimal places used in alerts\n" +
+	"/webhook <url> [secret] - Also POST alerts to a URL (/webhook off to disable)\n" +
+	"/crossalert <symbol> high|low <level> - Alert when a candle's high/low crosses a level\n" +
+	"/silent on|off - Deliver alerts without a notification sound\n" +
+	"/threshold <x> - Set the volume ratio that triggers alerts\n" +
+	"/tune <symbol> - Show how often each threshold would have fired today\n" +
+	"/digest on|grouped|off - Bundle each scan's alerts into one message\n" +
+	"/marketrelative on|off - Only alert on spikes well above the market-wide ratio\n" +
+	"/compareexchanges <symbol> - Compare volume on Binance and Bybit\n" +
+	"/dedup candle|cooldown - Suppress repeats per candle or per cooldown period\n" +
+	"/dashboard on|off - Keep a pinned message with the top spiking symbols\n" +
+	"/condition <expr> - Alert on a custom condition, e.g. ratio > 5 AND quoteVolume > 1M\n" +
+	"/metric volume|trades|takerbuy - Choose which candle field is compared\n" +
+	"/autopause <n> - Pause monitoring after n alerts within a window\n" +
+	"/exportconfig - Download this chat's settings as JSON\n" +
+	"/importconfig - Restore settings from an exported file\n" +
+	"/defaultquote <asset> - Quote asset used to expand names like btc\n" +
+	"/breakout <candles> [volume] - Alert when the close breaks the recent high/low range\n" +
+	"/skipped - List symbols skipped because Binance doesn't list them\n" +
+	"/rankby volume|marketcap - Choose how the monitored top coins are ranked\n" +
+	"/dailysummary HH:MM [timezone] - Get a recap of the day's alerts (/dailysummary off to disable)\n" +
+	"/effectiveness [on|off|reset] - Track locally whether alerts were followed by continued activity\n" +
+	"/book <symbol> [depth] - Show order book spread and bid/ask imbalance\n" +
+	"/candlecolor up|down|any - Only alert on green or red candles\n" +
+	"/validate <base> <quote> - Check that a pair exists and is trading on Binance\n" +
+	"/cooldown <duration>|scaled|fixed - Set the repeat cooldown, optionally shorter for stronger spikes\n" +
+	"/diagnostics - Check connectivity to Binance, CoinGecko and Telegram\n" +
+	"/priority <symbol>... - Mark symbols whose alerts always come through (/priority off to clear)\n" +
+	"/leaderboard [period] - Rank the most alerted symbols, e.g. /leaderboard 7d\n" +
+	"/daily on|off - Compare rolling 24h volume against the previous day\n" +
+	"/displaycurrency <currency> - Also show quote volumes in e.g. EUR\n" +
+	"/acceleration on [threshold]|off - Alert when the volume ratio is gaining steam\n" +
+	"/preview - Count the symbols your settings will scan\n" +
+	"/intervals <interval>[:<cadence>] ...|off - Also scan other candle intervals, e.g. /intervals 5m:1m\n" +
+	"/thread <topic_id>|off - Post alerts to a forum topic\n" +
+	"/align on|off - Scan right after each candle closes\n" +
+	"/testnotifiers - Send a test alert to every notifier\n" +
+	"/minvolume <amount> [quote]|off - Skip symbols trading less than amount of their quote asset\n" +
+	"/setsymbolthreshold <symbol> <threshold> - Override the threshold for one symbol\n" +
+	"/clearsymbolthreshold <symbol>|all - Remove symbol threshold overrides\n" +
+	"/mergewindow <duration>|off - Merge alerts for a symbol across intervals\n" +
+	"/washtrading flag|suppress|off - Detect volume with flat price and few trades\n" +
+	"/rvol <threshold> [days]|off - Alert on volume above its usual level for the time of day\n" +
+	"/newlistings on|off - Get a message when a new pair starts trading\n" +
+	"/candlemode closed|live - Compare closed candles or include the open one\n" +
+	"/boost 0.5x 1h - Temporarily scale the threshold, e.g. around CPI or FOMC\n" +
+	"/watchlist [symbols|clear] - Scan only these symbols; or send a .txt/.csv file captioned /watchlist\n" +
+	"/correlate <symbol> <symbol> [candles] - Correlate two symbols' volume\n" +
+	"/rangespike <multiple> [candles]|off - Alert on candles with an unusually wide range\n" +
+	"/sparkline on|off - Chart recent candles in alerts\n" +
+	"/alertper symbol|base - Alert per pair or once per base asset\n" +
+	"/severity <ratio>=<label> ...|reset - Label alerts by how strong the spike is\n" +
+	"/movers [count] - List the monitored symbols' top 24h price gainers and losers\n" +
+	"/backtest <symbol> <threshold> <period> - Replay a threshold over past candles\n" +
+	"/email <address>|off - Also email alerts\n" +
+	"/adaptive on|off - Scale each symbol's threshold by its volatility\n" +
+	"/basket create|monitor|unmonitor|delete|list - Alert on a weighted group of symbols\n" +
+	"/setup - Pick coverage, threshold and interval step by step\n" +
+	"/help - Show this list"
+
+// handleCommands serves the updates of one bot.
+func handleCommands(b *tgbotapi.BotAPI) {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
-	updates := bot.GetUpdatesChan(u)
+	updates := getUpdatesChan(b, u)
 
 	for update := range updates {
+		if shuttingDown() {
+			continue
+		}
+		if query := update.CallbackQuery; query != nil {
+			if query.Message == nil {
+				continue
+			}
+			if chatID, ok := claimChat(query.Message.Chat.ID, b); ok {
+				if strings.HasPrefix(query.Data, callbackOnboard+":") {
+					handleOnboardingCallback(b, chatID, query)
+				} else {
+					handleAlertCallback(b, chatID, query)
+				}
+			}
+			continue
+		}
+
 		if update.Message == nil {
 			continue
 		}
 
-		chatID := update.Message.Chat.ID
+		chatID, ok := claimChat(update.Message.Chat.ID, b)
+		if !ok {
+			b.Send(tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("This chat is served by @%s.", chatOwner(update.Message.Chat.ID))))
+			continue
+		}
+
+		if isImportConfigCaption(update.Message) {
+			send(b, tgbotapi.NewMessage(chatID, handleImportConfigCommand(chatID, update.Message)))
+			continue
+		}
+
+		if isWatchlistCaption(update.Message) {
+			send(b, tgbotapi.NewMessage(chatID, handleWatchlistDocument(chatID, update.Message.Document)))
+			continue
+		}
 
 		if !update.Message.IsCommand() {
 			continue
@@ -261,22 +674,28 @@ func handleCommands() {
 
 		switch update.Message.Command() {
 		case "start":
-			msg := tgbotapi.NewMessage(chatID,
-				"Welcome to Binance Volume Monitor Bot!\n\n"+
-					"Available commands:\n"+
-					"/monitor - Start volume monitoring\n"+
-					"/stop - Stop volume monitoring\n"+
-					"/status - Check monitoring status")
-			bot.Send(msg)
+			if isFirstRun(chatID) {
+				startOnboarding(b, chatID)
+			} else {
+				msg := tgbotapi.NewMessage(chatID, "Welcome to Binance Volume Monitor Bot!\n\n"+helpText)
+				send(b, msg)
+			}
+
+		case "help":
+			msg := tgbotapi.NewMessage(chatID, helpText)
+			send(b, msg)
+
+		case "setup":
+			startOnboarding(b, chatID)
 
 		case "monitor":
 			monitoring, _ := monitoringStatus.Load(chatID)
 			isMonitoring := monitoring != nil && monitoring.(bool)
 			if !isMonitoring {
-				go startMonitoring(chatID)
+				goWorker(func() { startMonitoring(chatID) })
 			} else {
 				msg := tgbotapi.NewMessage(chatID, "Monitoring is already running!")
-				bot.Send(msg)
+				send(b, msg)
 			}
 
 		case "stop":
@@ -286,23 +705,304 @@ func handleCommands() {
 				stopMonitoring(chatID)
 			} else {
 				msg := tgbotapi.NewMessage(chatID, "Monitoring is not running!")
-				bot.Send(msg)
+				send(b, msg)
 			}
 
 		case "status":
-			status := "stopped"
-			monitoring, _ := monitoringStatus.Load(chatID)
-			if monitoring != nil && monitoring.(bool) {
-				status = "running"
+			msg := tgbotapi.NewMessage(chatID, formatStatus(chatID))
+			send(b, msg)
+
+		case "ma":
+			msg := tgbotapi.NewMessage(chatID, handleMACommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "recent":
+			sendText(chatID, handleRecentCommand(chatID), false)
+
+		case "category":
+			msg := tgbotapi.NewMessage(chatID, handleCategoryCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "categories":
+			msg := tgbotapi.NewMessage(chatID, handleCategoriesCommand(update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "precision":
+			msg := tgbotapi.NewMessage(chatID, handlePrecisionCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "webhook":
+			msg := tgbotapi.NewMessage(chatID, handleWebhookCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "crossalert":
+			msg := tgbotapi.NewMessage(chatID, handleCrossAlertCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "silent":
+			msg := tgbotapi.NewMessage(chatID, handleSilentCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "threshold":
+			msg := tgbotapi.NewMessage(chatID, handleThresholdCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "tune":
+			msg := tgbotapi.NewMessage(chatID, handleTuneCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "digest":
+			msg := tgbotapi.NewMessage(chatID, handleDigestCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "marketrelative":
+			msg := tgbotapi.NewMessage(chatID, handleMarketRelativeCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "compareexchanges":
+			msg := tgbotapi.NewMessage(chatID, handleCompareExchangesCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "dedup":
+			msg := tgbotapi.NewMessage(chatID, handleDedupCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "dashboard":
+			msg := tgbotapi.NewMessage(chatID, handleDashboardCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "condition":
+			msg := tgbotapi.NewMessage(chatID, handleConditionCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "metric":
+			msg := tgbotapi.NewMessage(chatID, handleMetricCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "autopause":
+			msg := tgbotapi.NewMessage(chatID, handleAutoPauseCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "exportconfig":
+			handleExportConfigCommand(chatID)
+
+		case "importconfig":
+			msg := tgbotapi.NewMessage(chatID, handleImportConfigCommand(chatID, update.Message))
+			send(b, msg)
+
+		case "defaultquote":
+			msg := tgbotapi.NewMessage(chatID, handleDefaultQuoteCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "breakout":
+			msg := tgbotapi.NewMessage(chatID, handleBreakoutCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "skipped":
+			sendText(chatID, handleSkippedCommand(), false)
+
+		case "rankby":
+			msg := tgbotapi.NewMessage(chatID, handleRankByCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "dailysummary":
+			msg := tgbotapi.NewMessage(chatID, handleDailySummaryCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "effectiveness":
+			msg := tgbotapi.NewMessage(chatID, handleEffectivenessCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "book":
+			msg := tgbotapi.NewMessage(chatID, handleBookCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "candlecolor":
+			msg := tgbotapi.NewMessage(chatID, handleCandleColorCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "validate":
+			msg := tgbotapi.NewMessage(chatID, handleValidateCommand(update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "cooldown":
+			msg := tgbotapi.NewMessage(chatID, handleCooldownCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "diagnostics":
+			msg := tgbotapi.NewMessage(chatID, handleDiagnosticsCommand(chatID))
+			send(b, msg)
+
+		case "priority":
+			msg := tgbotapi.NewMessage(chatID, handlePriorityCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "leaderboard":
+			sendText(chatID, handleLeaderboardCommand(chatID, update.Message.CommandArguments()), false)
+
+		case "daily":
+			msg := tgbotapi.NewMessage(chatID, handleDailyCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "displaycurrency":
+			msg := tgbotapi.NewMessage(chatID, handleDisplayCurrencyCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "acceleration":
+			msg := tgbotapi.NewMessage(chatID, handleAccelerationCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "preview":
+			msg := tgbotapi.NewMessage(chatID, handlePreviewCommand(chatID))
+			send(b, msg)
+
+		case "intervals":
+			msg := tgbotapi.NewMessage(chatID, handleIntervalsCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "thread":
+			msg := tgbotapi.NewMessage(chatID, handleThreadCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "align":
+			msg := tgbotapi.NewMessage(chatID, handleAlignCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "testnotifiers":
+			msg := tgbotapi.NewMessage(chatID, handleTestNotifiersCommand(chatID))
+			send(b, msg)
+
+		case "minvolume":
+			msg := tgbotapi.NewMessage(chatID, handleMinVolumeCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "setsymbolthreshold":
+			msg := tgbotapi.NewMessage(chatID, handleSetSymbolThresholdCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "clearsymbolthreshold":
+			msg := tgbotapi.NewMessage(chatID, handleClearSymbolThresholdCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "mergewindow":
+			msg := tgbotapi.NewMessage(chatID, handleMergeWindowCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "washtrading":
+			msg := tgbotapi.NewMessage(chatID, handleWashTradingCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "newlistings":
+			msg := tgbotapi.NewMessage(chatID, handleNewListingsCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "candlemode":
+			msg := tgbotapi.NewMessage(chatID, handleCandleModeCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "boost":
+			msg := tgbotapi.NewMessage(chatID, handleBoostCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "watchlist":
+			var msg tgbotapi.MessageConfig
+			if doc := watchlistReplyDocument(update.Message); doc != nil && update.Message.CommandArguments() == "" {
+				msg = tgbotapi.NewMessage(chatID, handleWatchlistDocument(chatID, doc))
+			} else {
+				msg = tgbotapi.NewMessage(chatID, handleWatchlistCommand(chatID, update.Message.CommandArguments()))
 			}
-			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Monitoring is currently %s", status))
-			bot.Send(msg)
+			send(b, msg)
+
+		case "correlate":
+			msg := tgbotapi.NewMessage(chatID, handleCorrelateCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "rvol":
+			msg := tgbotapi.NewMessage(chatID, handleRVOLCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "rangespike":
+			msg := tgbotapi.NewMessage(chatID, handleRangeSpikeCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "sparkline":
+			msg := tgbotapi.NewMessage(chatID, handleSparklineCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "alertper":
+			msg := tgbotapi.NewMessage(chatID, handleAlertPerCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "severity":
+			msg := tgbotapi.NewMessage(chatID, handleSeverityCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "movers":
+			msg := tgbotapi.NewMessage(chatID, handleMoversCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "backtest":
+			sendText(chatID, handleBacktestCommand(chatID, update.Message.CommandArguments()), false)
+
+		case "email":
+			msg := tgbotapi.NewMessage(chatID, handleEmailCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "adaptive":
+			msg := tgbotapi.NewMessage(chatID, handleAdaptiveCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "basket":
+			msg := tgbotapi.NewMessage(chatID, handleBasketCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "snapshot":
+			msg := tgbotapi.NewMessage(chatID, handleSnapshotCommand(chatID))
+			send(b, msg)
+
+		case "concurrency":
+			msg := tgbotapi.NewMessage(chatID, handleConcurrencyCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "errors":
+			sendText(chatID, handleErrorsCommand(chatID, update.Message.CommandArguments()), false)
+
+		case "ping":
+			msg := tgbotapi.NewMessage(chatID, handlePingCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "tier":
+			msg := tgbotapi.NewMessage(chatID, handleTierCommand(chatID, update.Message.CommandArguments()))
+			send(b, msg)
+
+		case "chatinfo":
+			sendText(chatID, handleChatInfoCommand(chatID), false)
 		}
 	}
 }
 
 func main() {
+	setup()
 	log.Println("Starting Binance Volume Monitor Bot...")
+	ensureDataDir()
+	loadChatBots()
+	loadChatConfigs()
+	loadBaselines()
+	loadSkippedSymbols()
+	loadAlertHistory()
+	loadEffectiveness()
+	loadKnownSymbols()
+	loadOutbox()
 	loadMonitoringStatus()
-	handleCommands()
+	startPruner()
+	watchReloadSignal()
+	watchShutdownSignals()
+	startDailySummaries()
+	startAPIServer()
+	startListingWatcher()
+	startOutbox()
+	for _, b := range bots[1:] {
+		go handleCommands(b)
+	}
+	handleCommands(bot)
 }
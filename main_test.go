@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestMain installs the default settings with DATA_DIR pointed at a
+// scratch directory, so tests never touch the working directory's files.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "binance-volume-alert-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("DATA_DIR", dir)
+	loaded := loadSettings()
+	settings.Store(&loaded)
+	initFetchLimiter(loaded.MaxConcurrentFetches)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testKline builds a kline as Binance encodes it, with its open time in
+// hours since the epoch and the given close and base-asset volume.
+func testKline(hour int, closePrice, volume float64) BinanceKline {
+	openTime := float64(int64(hour) * 3600 * 1000)
+	str := func(v float64) interface{} { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return BinanceKline{
+		openTime, str(closePrice), str(closePrice), str(closePrice), str(closePrice),
+		str(volume), openTime + 3600*1000 - 1, str(volume * closePrice), float64(10),
+		str(volume / 2), str(volume * closePrice / 2), "0",
+	}
+}
+
+func TestComputeVolumeData(t *testing.T) {
+	klines := []BinanceKline{testKline(1, 10, 100), testKline(2, 10, 200), testKline(3, 10, 50)}
+
+	tests := []struct {
+		name      string
+		closed    bool
+		wantRatio float64
+		wantHour  int
+	}{
+		{"live compares the open candle", false, 0.25, 3},
+		{"closed ignores the open candle", true, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := computeVolumeData(klines, metricVolume, tt.closed)
+			if err != nil {
+				t.Fatalf("computeVolumeData returned error: %v", err)
+			}
+			if data.Ratio != tt.wantRatio {
+				t.Errorf("Ratio = %g, want %g", data.Ratio, tt.wantRatio)
+			}
+			if want := time.UnixMilli(int64(tt.wantHour) * 3600 * 1000); !data.OpenTime.Equal(want) {
+				t.Errorf("OpenTime = %v, want %v", data.OpenTime, want)
+			}
+		})
+	}
+}
+
+func TestComputeVolumeDataZeroPrevious(t *testing.T) {
+	data, err := computeVolumeData([]BinanceKline{testKline(1, 10, 0), testKline(2, 10, 50)}, metricVolume, false)
+	if err != nil || data != nil {
+		t.Errorf("computeVolumeData with a zero previous volume = %v, %v, want nil, nil", data, err)
+	}
+}
+
+func TestComputeVolumeDataTooFewKlines(t *testing.T) {
+	tests := []struct {
+		name   string
+		klines []BinanceKline
+		closed bool
+	}{
+		{"none", nil, false},
+		{"one live", []BinanceKline{testKline(1, 10, 100)}, false},
+		{"two closed", []BinanceKline{testKline(1, 10, 100), testKline(2, 10, 100)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := computeVolumeData(tt.klines, metricVolume, tt.closed); err == nil {
+				t.Error("computeVolumeData returned no error")
+			}
+		})
+	}
+}
+
+func TestComputeVolumeDataMalformedFields(t *testing.T) {
+	short := testKline(1, 10, 100)[:5]
+	numeric := testKline(1, 10, 100)
+	numeric[5] = float64(100)
+	nonNumeric := testKline(1, 10, 100)
+	nonNumeric[5] = "n/a"
+	nilField := testKline(1, 10, 100)
+	nilField[5] = nil
+
+	tests := []struct {
+		name      string
+		prev      BinanceKline
+		wantErr   bool
+		wantRatio float64
+	}{
+		{"short kline", short, true, 0},
+		{"volume as a JSON number", numeric, false, 2},
+		{"non-numeric volume", nonNumeric, true, 0},
+		{"nil volume", nilField, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := computeVolumeData([]BinanceKline{tt.prev, testKline(2, 10, 200)}, metricVolume, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("computeVolumeData = %+v, want an error", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("computeVolumeData returned error: %v", err)
+			}
+			if data.Ratio != tt.wantRatio {
+				t.Errorf("Ratio = %g, want %g", data.Ratio, tt.wantRatio)
+			}
+		})
+	}
+}
+
+func TestComputeVolumeDataMetrics(t *testing.T) {
+	prev, curr := testKline(1, 10, 100), testKline(2, 10, 300)
+	prev[8], curr[8] = float64(20), float64(5)
+
+	tests := []struct {
+		metric    string
+		wantPrev  float64
+		wantCurr  float64
+		wantRatio float64
+	}{
+		{metricVolume, 100, 300, 3},
+		{metricTrades, 20, 5, 0.25},
+		{metricTakerBuy, 50, 150, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			data, err := computeVolumeData([]BinanceKline{prev, curr}, tt.metric, false)
+			if err != nil {
+				t.Fatalf("computeVolumeData returned error: %v", err)
+			}
+			if data.PrevVolume != tt.wantPrev || data.CurrVolume != tt.wantCurr || data.Ratio != tt.wantRatio {
+				t.Errorf("got %g -> %g (%gx), want %g -> %g (%gx)",
+					data.PrevVolume, data.CurrVolume, data.Ratio, tt.wantPrev, tt.wantCurr, tt.wantRatio)
+			}
+		})
+	}
+}
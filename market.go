@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// symbolVolume is the volume data fetched for one symbol during a scan.
+type symbolVolume struct {
+	Symbol string
+	Data   *VolumeData
+}
+
+// averageRatio is the mean ratio across a scan, used as the market-wide
+// volume ratio.
+func averageRatio(results []symbolVolume) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, result := range results {
+		sum += result.Data.Ratio
+	}
+	return sum / float64(len(results))
+}
+
+// exceedsMarket reports whether ratio stands out from the market ratio by
+// at least MarketRelativeFactor.
+func exceedsMarket(ratio, marketRatio float64) bool {
+	if marketRatio <= 0 {
+		return true
+	}
+	return ratio >= marketRatio*currentSettings().MarketRelativeFactor
+}
+
+func handleMarketRelativeCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.MarketRelative = true
+		})
+		return fmt.Sprintf("Market-relative alerts enabled (beta). A symbol must also reach %gx the average ratio of the scan.",
+			currentSettings().MarketRelativeFactor)
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.MarketRelative = false
+		})
+		return "Market-relative alerts disabled."
+	default:
+		return "Usage: /marketrelative on|off"
+	}
+}
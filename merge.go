@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minMergeWindow = 5 * time.Second
+	maxMergeWindow = 10 * time.Minute
+)
+
+var (
+	// pendingMerges holds the alerts waiting out a chat's merge window,
+	// keyed by chat and symbol.
+	pendingMerges   = make(map[alertKey][]Alert)
+	pendingMergesMu sync.Mutex
+)
+
+// dispatchAlert sends alert, or, when the chat merges alerts across
+// intervals, holds it for the merge window so alerts for the same symbol on
+// other intervals go out with it as one message.
+func dispatchAlert(alert Alert, cfg ChatConfig) {
+	window := time.Duration(cfg.MergeWindowSeconds) * time.Second
+	if window <= 0 || len(cfg.Intervals) == 0 {
+		sendAlert(alert)
+		return
+	}
+
+	key := alertKey{alert.ChatID, alert.Symbol}
+	pendingMergesMu.Lock()
+	pending, waiting := pendingMerges[key]
+	pendingMerges[key] = append(pending, alert)
+	pendingMergesMu.Unlock()
+	if waiting {
+		return
+	}
+
+	goWorker(func() {
+		// During shutdown the held alerts are sent right away.
+		sleepUnlessShutdown(window)
+
+		pendingMergesMu.Lock()
+		alerts := pendingMerges[key]
+		delete(pendingMerges, key)
+		pendingMergesMu.Unlock()
+
+		sendAlert(mergeAlerts(alerts))
+	})
+}
+
+// mergeAlerts folds alerts for one symbol into the strongest of them,
+// listing every interval that fired.
+func mergeAlerts(alerts []Alert) Alert {
+	if len(alerts) == 1 {
+		return alerts[0]
+	}
+
+	strongest := alerts[0]
+	for _, a := range alerts[1:] {
+		if a.Data.Ratio > strongest.Data.Ratio {
+			strongest = a
+		}
+		strongest.Escalated = strongest.Escalated || a.Escalated
+		strongest.Priority = strongest.Priority || a.Priority
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return intervalDuration(alerts[i].Interval) < intervalDuration(alerts[j].Interval)
+	})
+	strongest.Timeframes = make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		strongest.Timeframes = append(strongest.Timeframes, fmt.Sprintf("%s %s", a.Interval, formatRatio(a.Data.Ratio, defaultPrecision)))
+	}
+	return strongest
+}
+
+func handleMergeWindowCommand(chatID int64, args string) string {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	switch arg {
+	case "":
+		cfg := getChatConfig(chatID)
+		if cfg.MergeWindowSeconds > 0 {
+			return fmt.Sprintf("Alerts for the same symbol on different intervals within %s are merged. Usage: /mergewindow <duration>|off", time.Duration(cfg.MergeWindowSeconds)*time.Second)
+		}
+		return "Alerts on different intervals are sent separately. Usage: /mergewindow <duration>|off, e.g. /mergewindow 1m"
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.MergeWindowSeconds = 0
+		})
+		return "Alerts on different intervals will be sent separately."
+	}
+
+	d, err := time.ParseDuration(arg)
+	if err != nil || d < minMergeWindow || d > maxMergeWindow {
+		return fmt.Sprintf("Merge window must be a duration between %s and %s, e.g. 30s or 2m", minMergeWindow, maxMergeWindow)
+	}
+
+	seconds := int(d / time.Second)
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.MergeWindowSeconds = seconds
+	})
+	reply := fmt.Sprintf("Alerts for the same symbol within %s are merged into one message listing the intervals that fired. Each alert may wait up to that long.", time.Duration(seconds)*time.Second)
+	if len(cfg.Intervals) == 0 {
+		reply += " It takes effect once you scan extra intervals with /intervals."
+	}
+	return reply
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramMessageLimit is the longest text Telegram accepts in one message,
+// counted in UTF-16 code units.
+const telegramMessageLimit = 4096
+
+// textLength returns the length of s as Telegram counts it.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// splitMessage breaks text into chunks of at most limit units, splitting at
+// line boundaries. A single line longer than limit is split between runes.
+func splitMessage(text string, limit int) []string {
+	if textLength(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	var chunk strings.Builder
+	chunkLen := 0
+	// A chunk left holding only line breaks is dropped, since Telegram
+	// rejects empty messages.
+	flush := func() {
+		if text := strings.TrimRight(chunk.String(), "\n"); text != "" {
+			chunks = append(chunks, text)
+		}
+		chunk.Reset()
+		chunkLen = 0
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineLen := textLength(line)
+		if chunkLen+lineLen > limit {
+			flush()
+		}
+		for lineLen > limit {
+			head, rest := splitRunes(line, limit)
+			chunks = append(chunks, head)
+			line, lineLen = rest, textLength(rest)
+		}
+		chunk.WriteString(line)
+		chunkLen += lineLen
+	}
+	flush()
+	return chunks
+}
+
+// splitRunes splits s after the last rune that fits within limit units.
+func splitRunes(s string, limit int) (string, string) {
+	n := 0
+	for i, r := range s {
+		if n+utf16.RuneLen(r) > limit {
+			return s[:i], s[i:]
+		}
+		n += utf16.RuneLen(r)
+	}
+	return s, ""
+}
+
+// sendChatMessage sends msg with the chat's bot, into the chat's forum
+// topic if /thread set one, and stops monitoring a chat that blocked the
+// bot. Messages the bot posts on its own go through here rather than
+// straight to Send.
+func sendChatMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	sent, err := sendToThread(botFor(msg.ChatID), msg, getChatConfig(msg.ChatID).MessageThreadID)
+	if isBlockedError(err) {
+		dropSubscription(msg.ChatID, err)
+	}
+	return sent, err
+}
+
+// sendText sends text to the chat, split into as many messages as
+// Telegram's length limit requires.
+func sendText(chatID int64, text string, silent bool) error {
+	for _, chunk := range splitMessage(text, telegramMessageLimit) {
+		msg := tgbotapi.NewMessage(chatID, chunk)
+		msg.DisableNotification = silent
+		if _, err := sendChatMessage(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeTelegram is a Bot API server that records the requests it gets and
+// answers them with status, 200 meaning success. With failMethod set only
+// that method gets status and the others succeed, and the first succeedFirst
+// requests succeed regardless; description overrides the error text.
+type fakeTelegram struct {
+	mu           sync.Mutex
+	status       int
+	failMethod   string
+	succeedFirst int
+	description  string
+	requests     []url.Values
+	methods      []string
+}
+
+// useFakeTelegram points the default bot at a fakeTelegram for the test.
+func useFakeTelegram(t *testing.T, status int) *fakeTelegram {
+	t.Helper()
+	fake := &fakeTelegram{status: status}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, form)
+		fake.methods = append(fake.methods, method)
+		status, description := fake.status, fake.description
+		if (fake.failMethod != "" && method != fake.failMethod) || len(fake.requests) <= fake.succeedFirst {
+			status = http.StatusOK
+		}
+		fake.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status == http.StatusOK {
+			io.WriteString(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`)
+			return
+		}
+		if description == "" {
+			description = http.StatusText(status)
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"ok":false,"error_code":%d,"description":"%s"}`, status, description)
+	}))
+	t.Cleanup(srv.Close)
+
+	b := &tgbotapi.BotAPI{Token: "test", Client: srv.Client(), Buffer: 1}
+	b.SetAPIEndpoint(srv.URL + "/bot%s/%s")
+	previous := bot
+	bot = b
+	t.Cleanup(func() { bot = previous })
+	return fake
+}
+
+func (f *fakeTelegram) sent() []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.requests...)
+}
+
+// calls returns the Bot API methods requested so far, in order.
+func (f *fakeTelegram) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.methods...)
+}
+
+func TestSendTextUsesThread(t *testing.T) {
+	fake := useFakeTelegram(t, http.StatusOK)
+	const chatID int64 = 6001
+	updateChatConfig(chatID, func(cfg *ChatConfig) { cfg.MessageThreadID = 42 })
+	t.Cleanup(func() { updateChatConfig(chatID, func(cfg *ChatConfig) { cfg.MessageThreadID = 0 }) })
+
+	if err := sendText(chatID, strings.Repeat("line\n", telegramMessageLimit/4), true); err != nil {
+		t.Fatalf("sendText returned error: %v", err)
+	}
+	requests := fake.sent()
+	if len(requests) < 2 {
+		t.Fatalf("sendText made %d requests, want the text split into at least 2", len(requests))
+	}
+	for _, form := range requests {
+		if form.Get("message_thread_id") != "42" || form.Get("disable_notification") != "true" {
+			t.Errorf("sendText request %v, want message_thread_id 42 and disable_notification", form)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	long := strings.Repeat("BTCUSDT 12.34x\n", 1000)
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+	}{
+		{"fits", "short message", telegramMessageLimit},
+		{"oversized", long, telegramMessageLimit},
+		{"single long line", strings.Repeat("x", 10000), telegramMessageLimit},
+		{"emoji count double", strings.Repeat("🚀", 3000), telegramMessageLimit},
+		{"tiny limit", "ab\ncd\nef", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitMessage(tt.text, tt.limit)
+			for i, chunk := range chunks {
+				if n := textLength(chunk); n > tt.limit || n == 0 {
+					t.Errorf("chunk %d has length %d, limit %d", i, n, tt.limit)
+				}
+			}
+			joined := strings.ReplaceAll(strings.Join(chunks, ""), "\n", "")
+			if want := strings.ReplaceAll(tt.text, "\n", ""); joined != want {
+				t.Errorf("chunks lost or reordered text: got %d units, want %d", textLength(joined), textLength(want))
+			}
+		})
+	}
+}
+
+func TestSplitMessageAtLines(t *testing.T) {
+	line := strings.Repeat("a", 99) + "\n"
+	chunks := splitMessage(strings.Repeat(line, 100), 1000)
+	if len(chunks) != 10 {
+		t.Fatalf("split into %d chunks, want 10", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if strings.Count(chunk, "\n") != 9 || strings.HasSuffix(chunk, "\n") {
+			t.Errorf("chunk %d does not hold 10 whole lines: %q", i, chunk)
+		}
+	}
+}
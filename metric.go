@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	metricVolume   = "volume"
+	metricTrades   = "trades"
+	metricTakerBuy = "takerbuy"
+)
+
+// metricField returns the kline index a metric is read from: base-asset
+// volume (5), number of trades (8) or taker buy base-asset volume (9).
+func metricField(metric string) int {
+	switch metric {
+	case metricTrades:
+		return 8
+	case metricTakerBuy:
+		return 9
+	default:
+		return 5
+	}
+}
+
+// metricLabel is the human-readable name of a metric used in alerts.
+func metricLabel(metric string) string {
+	switch metric {
+	case metricTrades:
+		return "Trades"
+	case metricTakerBuy:
+		return "Taker Buy Volume"
+	default:
+		return "Volume"
+	}
+}
+
+func handleMetricCommand(chatID int64, args string) string {
+	metric := strings.ToLower(strings.TrimSpace(args))
+	switch metric {
+	case metricVolume, metricTrades, metricTakerBuy:
+	case "":
+		cfg := getChatConfig(chatID)
+		return fmt.Sprintf("Alerts compare %s. Usage: /metric volume|trades|takerbuy", strings.ToLower(metricLabel(cfg.Metric)))
+	default:
+		return "Usage: /metric volume|trades|takerbuy"
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Metric = metric
+		if metric == metricVolume {
+			cfg.Metric = ""
+		}
+	})
+	return fmt.Sprintf("Alerts will compare %s between candles.", strings.ToLower(metricLabel(metric)))
+}
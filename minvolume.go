@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// symbolQuoteAsset returns the quote asset of symbol from exchangeInfo. If
+// exchangeInfo is unavailable it falls back to the longest of quotes the
+// symbol ends with.
+func symbolQuoteAsset(symbol string, quotes []string) string {
+	if symbols, err := getExchangeInfo(); err == nil {
+		if info, ok := symbols[symbol]; ok {
+			return info.QuoteAsset
+		}
+	} else {
+		log.Printf("Error getting quote asset of %s: %v", symbol, err)
+	}
+
+	best := ""
+	for _, quote := range quotes {
+		if strings.HasSuffix(symbol, quote) && len(quote) > len(best) {
+			best = quote
+		}
+	}
+	return best
+}
+
+// meetsMinVolume reports whether the current candle's quote volume reaches
+// the chat's minimum for the symbol's quote asset. Each minimum is in units
+// of its own quote asset, so 100000 for USDT and 2 for BTC can coexist.
+// Symbols quoted in an asset without a minimum always pass.
+func meetsMinVolume(cfg ChatConfig, symbol string, data *VolumeData) bool {
+	if len(cfg.MinVolume) == 0 {
+		return true
+	}
+
+	quotes := make([]string, 0, len(cfg.MinVolume))
+	for quote := range cfg.MinVolume {
+		quotes = append(quotes, quote)
+	}
+	minimum, ok := cfg.MinVolume[symbolQuoteAsset(symbol, quotes)]
+	return !ok || data.QuoteVolume >= minimum
+}
+
+// formatMinVolume lists the minimums sorted by quote asset, e.g.
+// "100K USDT, 2.00 BTC".
+func formatMinVolume(minimums map[string]float64, precision int) string {
+	quotes := make([]string, 0, len(minimums))
+	for quote := range minimums {
+		quotes = append(quotes, quote)
+	}
+	sort.Strings(quotes)
+
+	parts := make([]string, 0, len(quotes))
+	for _, quote := range quotes {
+		parts = append(parts, formatVolume(minimums[quote], precision)+" "+quote)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func handleMinVolumeCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToUpper(args))
+	usage := "Usage: /minvolume <amount> [quote], /minvolume off [quote], e.g. /minvolume 100000 USDT or /minvolume 2 BTC"
+	if len(fields) == 0 {
+		cfg := getChatConfig(chatID)
+		if len(cfg.MinVolume) == 0 {
+			return "No minimum volume set. " + usage
+		}
+		return fmt.Sprintf("Minimum quote volume per candle: %s. Pairs in other quote assets aren't filtered.", formatMinVolume(cfg.MinVolume, cfg.Precision))
+	}
+	if len(fields) > 2 {
+		return usage
+	}
+
+	quote := getChatConfig(chatID).DefaultQuote
+	if quote == "" {
+		quote = defaultQuoteAsset
+	}
+	if len(fields) == 2 {
+		quote = fields[1]
+	}
+
+	if fields[0] == "OFF" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			if len(fields) == 2 {
+				delete(cfg.MinVolume, quote)
+			} else {
+				cfg.MinVolume = nil
+			}
+		})
+		if len(fields) == 2 {
+			return fmt.Sprintf("Minimum volume for %s pairs removed.", quote)
+		}
+		return "All minimum volumes removed."
+	}
+
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || amount <= 0 {
+		return "Amount must be a positive number"
+	}
+	if !validCurrencyCode(quote) {
+		return "Quote must be an asset such as USDT or BTC"
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		if cfg.MinVolume == nil {
+			cfg.MinVolume = make(map[string]float64)
+		}
+		cfg.MinVolume[quote] = amount
+	})
+	return fmt.Sprintf("Symbols quoted in %s now need at least %s %s of volume in the current candle to alert. Minimums: %s.",
+		quote, formatVolume(amount, cfg.Precision), quote, formatMinVolume(cfg.MinVolume, cfg.Precision))
+}
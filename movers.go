@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMoversCount = 5
+	maxMoversCount     = 20
+)
+
+// priceMover is a symbol's rolling 24h price change.
+type priceMover struct {
+	Symbol    string
+	ChangePct float64
+}
+
+// rankMovers returns the symbols' top gainers and top losers by 24h price
+// change, n of each at most. Symbols without a ticker are left out, and a
+// symbol is only listed as a loser if it fell.
+func rankMovers(symbols []string, tickers map[string]ticker24h, n int) (gainers, losers []priceMover) {
+	var movers []priceMover
+	for _, symbol := range symbols {
+		t, ok := tickers[symbol]
+		if !ok {
+			continue
+		}
+		change, err := strconv.ParseFloat(t.PriceChangePercent, 64)
+		if err != nil {
+			continue
+		}
+		movers = append(movers, priceMover{Symbol: symbol, ChangePct: change})
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool { return movers[i].ChangePct > movers[j].ChangePct })
+	for _, m := range movers {
+		if len(gainers) == n || m.ChangePct <= 0 {
+			break
+		}
+		gainers = append(gainers, m)
+	}
+	for i := len(movers) - 1; i >= 0 && len(losers) < n; i-- {
+		if movers[i].ChangePct >= 0 {
+			break
+		}
+		losers = append(losers, movers[i])
+	}
+	return gainers, losers
+}
+
+func writeMovers(sb *strings.Builder, title string, movers []priceMover) {
+	sb.WriteString(title + "\n")
+	if len(movers) == 0 {
+		sb.WriteString("none\n")
+		return
+	}
+	for i, m := range movers {
+		fmt.Fprintf(sb, "%2d. %-12s %+.2f%%\n", i+1, m.Symbol, m.ChangePct)
+	}
+}
+
+func handleMoversCommand(chatID int64, args string) string {
+	n := defaultMoversCount
+	if arg := strings.TrimSpace(args); arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 1 || n > maxMoversCount {
+			return fmt.Sprintf("Usage: /movers [count], with a count from 1 to %d", maxMoversCount)
+		}
+	}
+
+	symbols, err := getMonitoredSymbols(getChatConfig(chatID))
+	if err != nil {
+		return fmt.Sprintf("Could not get the monitored symbols: %v", err)
+	}
+	tickers, err := getBulkTickers()
+	if err != nil {
+		return fmt.Sprintf("Could not fetch 24h tickers: %v", err)
+	}
+
+	gainers, losers := rankMovers(symbols, tickers, n)
+	var sb strings.Builder
+	writeMovers(&sb, "📈 Top gainers (24h)", gainers)
+	sb.WriteString("\n")
+	writeMovers(&sb, "📉 Top losers (24h)", losers)
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Notifier delivers alerts to a destination other than the Telegram chat.
+type Notifier interface {
+	Name() string
+	Notify(alert Alert) error
+}
+
+// telegramNotifier posts alerts to the chat itself, in its topic if set.
+type telegramNotifier struct {
+	cfg ChatConfig
+}
+
+func newTelegramNotifier(cfg ChatConfig) *telegramNotifier {
+	return &telegramNotifier{cfg: cfg}
+}
+
+func (t *telegramNotifier) Name() string {
+	return "Telegram"
+}
+
+func (t *telegramNotifier) Notify(alert Alert) error {
+	msg := tgbotapi.NewMessage(alert.ChatID, formatAlert(alert, t.cfg.Precision))
+	msg.DisableNotification = alertIsSilent(t.cfg, alert.Data.Ratio) && !alert.Priority
+	if !alert.Test {
+		msg.ReplyMarkup = alertKeyboard(alert.Symbol)
+	}
+	_, err := sendToThread(botFor(alert.ChatID), msg, t.cfg.MessageThreadID)
+	return err
+}
+
+// chatNotifiers returns the additional notifiers configured for a chat.
+func chatNotifiers(cfg ChatConfig) []Notifier {
+	var notifiers []Notifier
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, newWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret))
+	}
+	if cfg.Email != "" && smtpConfigured() {
+		notifiers = append(notifiers, newEmailNotifier(cfg.Email, cfg.Precision))
+	}
+	return notifiers
+}
+
+// notifyAll fans an alert out to the chat's notifiers in the background so
+// slow or retrying endpoints don't hold up the scan.
+func notifyAll(cfg ChatConfig, alert Alert) {
+	for _, n := range chatNotifiers(cfg) {
+		goWorker(func() {
+			if err := n.Notify(alert); err != nil {
+				log.Printf("Error sending %s alert for chat %d: %v", n.Name(), alert.ChatID, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackOnboard = "onboard"
+
+	// onboardingTTL is how long an unfinished setup waits for the next
+	// button press before it is abandoned.
+	onboardingTTL = 30 * time.Minute
+
+	onboardCoverage  = "coverage"
+	onboardThreshold = "threshold"
+	onboardInterval  = "interval"
+	onboardSkip      = "skip"
+	onboardStart     = "start"
+
+	intervalMainOnly = "main"
+)
+
+// onboardingSteps are asked in order; each button's callback data names
+// the step it answers so a stale keyboard can't answer a later one.
+var onboardingSteps = []string{onboardCoverage, onboardThreshold, onboardInterval}
+
+// onboarding is a chat's unfinished /setup. Draft collects the answers and
+// is saved only once the last step is answered or the rest is skipped.
+type onboarding struct {
+	Step      int
+	Draft     ChatConfig
+	UpdatedAt time.Time
+}
+
+var (
+	onboardings   = make(map[int64]onboarding)
+	onboardingsMu sync.Mutex
+)
+
+// isFirstRun reports whether the chat has neither changed a setting nor
+// monitored before.
+func isFirstRun(chatID int64) bool {
+	chatConfigsMu.RLock()
+	_, configured := chatConfigs[chatID]
+	chatConfigsMu.RUnlock()
+	_, monitored := monitoringStatus.Load(chatID)
+	return !configured && !monitored
+}
+
+func onboardButton(label, step, value string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(label, callbackOnboard+":"+step+":"+value)
+}
+
+// onboardingPrompt returns the question and buttons of a step.
+func onboardingPrompt(step int) (string, tgbotapi.InlineKeyboardMarkup) {
+	skip := tgbotapi.NewInlineKeyboardRow(onboardButton("Skip, use defaults", onboardSkip, ""))
+	prefix := fmt.Sprintf("Setup %d/%d: ", step+1, len(onboardingSteps))
+
+	switch onboardingSteps[step] {
+	case onboardCoverage:
+		return prefix + "Which coins should be scanned?", tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				onboardButton("Top by market cap", onboardCoverage, rankByMarketCap),
+				onboardButton("Top by 24h volume", onboardCoverage, rankByVolume),
+			), skip)
+	case onboardThreshold:
+		return prefix + "How many times its previous candle's volume should a candle reach to alert?", tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				onboardButton("3x", onboardThreshold, "3"),
+				onboardButton("5x (default)", onboardThreshold, "5"),
+				onboardButton("10x", onboardThreshold, "10"),
+			), skip)
+	default:
+		return prefix + fmt.Sprintf("Alerts compare %s candles. Also scan a shorter interval?", klineInterval), tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				onboardButton(klineInterval+" only", onboardInterval, intervalMainOnly),
+				onboardButton("Also 15m", onboardInterval, "15m"),
+				onboardButton("Also 5m", onboardInterval, "5m"),
+			), skip)
+	}
+}
+
+// applyOnboardingAnswer records the answer to a step in draft.
+func applyOnboardingAnswer(draft *ChatConfig, step, value string) error {
+	switch step {
+	case onboardCoverage:
+		switch value {
+		case rankByMarketCap:
+			draft.RankBy = ""
+		case rankByVolume:
+			draft.RankBy = rankByVolume
+		default:
+			return fmt.Errorf("unknown coverage %q", value)
+		}
+	case onboardThreshold:
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 1 {
+			return fmt.Errorf("invalid threshold %q", value)
+		}
+		draft.Threshold = threshold
+	case onboardInterval:
+		if value == intervalMainOnly {
+			draft.Intervals = nil
+			return nil
+		}
+		scan, err := parseIntervalScan(value)
+		if err != nil {
+			return err
+		}
+		draft.Intervals = []IntervalScan{scan}
+	default:
+		return fmt.Errorf("unknown step %q", step)
+	}
+	return nil
+}
+
+// startOnboarding asks the first setup question, replacing any setup the
+// chat left unfinished.
+func startOnboarding(b *tgbotapi.BotAPI, chatID int64) {
+	onboardingsMu.Lock()
+	onboardings[chatID] = onboarding{Draft: getChatConfig(chatID), UpdatedAt: time.Now()}
+	onboardingsMu.Unlock()
+
+	text, keyboard := onboardingPrompt(0)
+	msg := tgbotapi.NewMessage(chatID, "Welcome to Binance Volume Monitor Bot! Three quick questions set up your alerts.\n\n"+text)
+	msg.ReplyMarkup = keyboard
+	if _, err := send(b, msg); err != nil {
+		log.Printf("Error sending onboarding to chat %d: %v", chatID, err)
+	}
+}
+
+// finishOnboarding saves the answered settings and returns the summary.
+func finishOnboarding(chatID int64, draft ChatConfig) string {
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.RankBy = draft.RankBy
+		cfg.Threshold = draft.Threshold
+		cfg.Intervals = draft.Intervals
+	})
+	if chatIsMonitoring(chatID) {
+		ensureIntervalScanners(chatID)
+	}
+
+	limit, _ := symbolLimit(cfg)
+	intervals := klineInterval
+	for _, scan := range cfg.Intervals {
+		intervals += ", " + scan.Interval
+	}
+	return fmt.Sprintf("✅ Setup saved.\nCoins: top %d by %s\nThreshold: %gx\nCandles: %s\n\nChange any of these later with /rankby, /threshold and /intervals, or run /setup again. /help lists every command.",
+		limit, rankLabel(cfg.RankBy), cfg.Threshold, intervals)
+}
+
+// handleOnboardingCallback handles a press of a setup button: it records
+// the answer and edits the message into the next question, or into the
+// summary once setup is done.
+func handleOnboardingCallback(b *tgbotapi.BotAPI, chatID int64, query *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(query.Data, ":", 3)
+	if len(parts) < 2 {
+		return
+	}
+	step, value := parts[1], ""
+	if len(parts) == 3 {
+		value = parts[2]
+	}
+
+	answer := func(text string) {
+		if _, err := b.Request(tgbotapi.NewCallback(query.ID, text)); err != nil {
+			log.Printf("Error answering callback: %v", err)
+		}
+	}
+	edit := func(text string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+		msg := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, text)
+		msg.ReplyMarkup = keyboard
+		if _, err := request(b, msg); err != nil {
+			log.Printf("Error updating onboarding message: %v", err)
+		}
+	}
+
+	if step == onboardStart {
+		answer("")
+		if chatIsMonitoring(chatID) {
+			send(b, tgbotapi.NewMessage(chatID, "Monitoring is already running!"))
+			return
+		}
+		edit(query.Message.Text, nil)
+		goWorker(func() { startMonitoring(chatID) })
+		return
+	}
+
+	onboardingsMu.Lock()
+	state, ok := onboardings[chatID]
+	if ok && time.Since(state.UpdatedAt) > onboardingTTL {
+		delete(onboardings, chatID)
+		ok = false
+	}
+	onboardingsMu.Unlock()
+	if !ok {
+		answer("This setup has expired.")
+		edit(query.Message.Text+"\n\nThis setup has expired. Send /setup to start again.", nil)
+		return
+	}
+
+	if step != onboardSkip {
+		if step != onboardingSteps[state.Step] {
+			answer("")
+			return
+		}
+		if err := applyOnboardingAnswer(&state.Draft, step, value); err != nil {
+			log.Printf("Invalid onboarding answer from chat %d: %v", chatID, err)
+			answer("")
+			return
+		}
+		state.Step++
+	}
+
+	if step == onboardSkip || state.Step == len(onboardingSteps) {
+		onboardingsMu.Lock()
+		delete(onboardings, chatID)
+		onboardingsMu.Unlock()
+
+		answer("Setup saved")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			onboardButton("▶️ Start monitoring", onboardStart, ""),
+		))
+		edit(finishOnboarding(chatID, state.Draft), &keyboard)
+		return
+	}
+
+	state.UpdatedAt = time.Now()
+	onboardingsMu.Lock()
+	onboardings[chatID] = state
+	onboardingsMu.Unlock()
+
+	answer("")
+	text, keyboard := onboardingPrompt(state.Step)
+	edit(text, &keyboard)
+}
+
+// pruneOnboardings drops setups abandoned for longer than onboardingTTL and
+// returns how many were dropped.
+func pruneOnboardings(now time.Time) int {
+	onboardingsMu.Lock()
+	defer onboardingsMu.Unlock()
+
+	removed := 0
+	for chatID, state := range onboardings {
+		if now.Sub(state.UpdatedAt) > onboardingTTL {
+			delete(onboardings, chatID)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	outboxFile = "alert_outbox.json"
+
+	outboxPollInterval  = 15 * time.Second
+	outboxRetryBackoff  = 30 * time.Second
+	outboxMaxRetryDelay = 10 * time.Minute
+)
+
+// outboxEntry is an alert waiting for Telegram to accept it. Entries are
+// persisted before the first attempt, so alerts computed just before a
+// crash or during a Telegram outage are still delivered after a restart.
+type outboxEntry struct {
+	ID    uint64 `json:"id"`
+	Alert Alert  `json:"alert"`
+	// Text, when set, is sent as is instead of formatting Alert, for
+	// alerts such as digests and breakouts that are not a single volume
+	// alert. Alert.ChatID still names the chat.
+	Text   string `json:"text,omitempty"`
+	Silent bool   `json:"silent,omitempty"`
+	// Sent counts the chunks of a long Text Telegram already accepted, so
+	// a retry resumes after them instead of repeating them.
+	Sent        int       `json:"sent,omitempty"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	Expires     time.Time `json:"expires"`
+}
+
+var (
+	outbox       = make(map[uint64]*outboxEntry)
+	outboxNextID uint64
+	outboxMu     sync.Mutex
+)
+
+// outboxRetryDelay returns how long to wait after the given number of
+// failed attempts, doubling from outboxRetryBackoff.
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBackoff
+	for i := 1; i < attempts && delay < outboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxRetryDelay)
+}
+
+// enqueueAlert persists alert and returns its entry, already claimed for
+// the caller's immediate delivery attempt.
+func enqueueAlert(alert Alert, now time.Time) outboxEntry {
+	return enqueue(outboxEntry{Alert: alert}, now)
+}
+
+// enqueueMessage is enqueueAlert for an alert already formatted as text.
+func enqueueMessage(chatID int64, text string, silent bool, now time.Time) outboxEntry {
+	return enqueue(outboxEntry{Alert: Alert{ChatID: chatID}, Text: text, Silent: silent}, now)
+}
+
+func enqueue(entry outboxEntry, now time.Time) outboxEntry {
+	outboxMu.Lock()
+	outboxNextID++
+	entry.ID = outboxNextID
+	entry.Attempts = 1
+	entry.NextAttempt = now.Add(outboxRetryDelay(1))
+	entry.Expires = now.Add(currentSettings().AlertQueueTTL)
+	outbox[entry.ID] = &entry
+	claimed := entry
+	outboxMu.Unlock()
+
+	saveOutbox()
+	return claimed
+}
+
+// queueMessage delivers a text alert through the outbox, so it is retried
+// like any other alert if Telegram doesn't take it right away.
+func queueMessage(chatID int64, text string, silent bool) error {
+	return deliverAlert(enqueueMessage(chatID, text, silent, time.Now()))
+}
+
+// claimDueAlerts drops expired entries and returns those due for another
+// attempt, pushing their next attempt back so they aren't claimed twice.
+func claimDueAlerts(now time.Time) []outboxEntry {
+	outboxMu.Lock()
+	var due []outboxEntry
+	changed := false
+	for id, entry := range outbox {
+		if now.After(entry.Expires) {
+			log.Printf("Dropping undelivered alert %d to chat %d after %d attempts", id, entry.Alert.ChatID, entry.Attempts)
+			delete(outbox, id)
+			changed = true
+			continue
+		}
+		if now.Before(entry.NextAttempt) {
+			continue
+		}
+		entry.Attempts++
+		entry.NextAttempt = now.Add(outboxRetryDelay(entry.Attempts))
+		due = append(due, *entry)
+		changed = true
+	}
+	outboxMu.Unlock()
+
+	if changed {
+		saveOutbox()
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	return due
+}
+
+func removeQueuedAlert(id uint64) {
+	outboxMu.Lock()
+	delete(outbox, id)
+	outboxMu.Unlock()
+	saveOutbox()
+}
+
+// markChunksSent records that the first sent chunks of a text entry were
+// delivered.
+func markChunksSent(id uint64, sent int) {
+	outboxMu.Lock()
+	entry, ok := outbox[id]
+	if ok {
+		entry.Sent = sent
+	}
+	outboxMu.Unlock()
+
+	if ok {
+		saveOutbox()
+	}
+}
+
+// delayQueuedAlert honours a Telegram flood-control wait.
+func delayQueuedAlert(id uint64, until time.Time) {
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	if entry, ok := outbox[id]; ok && until.After(entry.NextAttempt) {
+		entry.NextAttempt = until
+	}
+}
+
+// isPermanentSendError reports whether retrying a send cannot help: the
+// chat blocked the bot or Telegram rejected the request itself.
+func isPermanentSendError(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && (tgErr.Code == http.StatusForbidden || tgErr.Code == http.StatusBadRequest)
+}
+
+// deliverAlert attempts to send a queued alert to its chat, removing it
+// from the outbox once Telegram accepts it or fails in a way retrying won't
+// fix. It returns the send error, if any.
+func deliverAlert(entry outboxEntry) error {
+	alert := entry.Alert
+	var err error
+	if entry.Text != "" {
+		err = sendQueuedText(entry)
+	} else {
+		err = newTelegramNotifier(getChatConfig(alert.ChatID)).Notify(alert)
+	}
+	if err == nil {
+		removeQueuedAlert(entry.ID)
+		return nil
+	}
+
+	if isPermanentSendError(err) {
+		removeQueuedAlert(entry.ID)
+		if isBlockedError(err) {
+			dropSubscription(alert.ChatID, err)
+		} else {
+			log.Printf("Error sending alert, not retrying: %v", err)
+		}
+		return err
+	}
+
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		delayQueuedAlert(entry.ID, time.Now().Add(time.Duration(tgErr.RetryAfter)*time.Second))
+	}
+	log.Printf("Error sending alert (attempt %d), will retry: %v", entry.Attempts, err)
+	return err
+}
+
+// sendQueuedText sends the chunks of a text entry not yet delivered,
+// recording each one Telegram accepts.
+func sendQueuedText(entry outboxEntry) error {
+	chunks := splitMessage(entry.Text, telegramMessageLimit)
+	for i := entry.Sent; i < len(chunks); i++ {
+		msg := tgbotapi.NewMessage(entry.Alert.ChatID, chunks[i])
+		msg.DisableNotification = entry.Silent
+		if _, err := sendChatMessage(msg); err != nil {
+			return err
+		}
+		if i+1 < len(chunks) {
+			markChunksSent(entry.ID, i+1)
+		}
+	}
+	return nil
+}
+
+// startOutbox retries queued alerts until they are delivered or expire.
+func startOutbox() {
+	go func() {
+		for sleepUnlessShutdown(outboxPollInterval) {
+			for _, entry := range claimDueAlerts(time.Now()) {
+				deliverAlert(entry)
+			}
+		}
+	}()
+}
+
+func saveOutbox() {
+	outboxMu.Lock()
+	entries := make([]outboxEntry, 0, len(outbox))
+	for _, entry := range outbox {
+		entries = append(entries, *entry)
+	}
+	outboxMu.Unlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		log.Printf("Error marshaling alert outbox: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(outboxFile), data, 0644); err != nil {
+		log.Printf("Error saving alert outbox: %v", err)
+	}
+}
+
+// loadOutbox restores the queued alerts, making them due right away.
+func loadOutbox() {
+	data, err := os.ReadFile(dataPath(outboxFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading alert outbox file: %v", err)
+		}
+		return
+	}
+
+	var entries []outboxEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Printf("Error unmarshaling alert outbox: %v", err)
+		return
+	}
+
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	for i := range entries {
+		entry := entries[i]
+		entry.NextAttempt = time.Time{}
+		outbox[entry.ID] = &entry
+		outboxNextID = max(outboxNextID, entry.ID)
+	}
+	if len(entries) > 0 {
+		log.Printf("Restored %d undelivered alerts", len(entries))
+	}
+}
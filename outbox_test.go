@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// crashOutbox forgets the in-memory outbox as a crash would, leaving only
+// what was persisted.
+func crashOutbox() {
+	outboxMu.Lock()
+	outbox = make(map[uint64]*outboxEntry)
+	outboxNextID = 0
+	outboxMu.Unlock()
+}
+
+func resetOutbox(t *testing.T) {
+	t.Helper()
+	crashOutbox()
+	os.Remove(dataPath(outboxFile))
+	t.Cleanup(func() {
+		crashOutbox()
+		os.Remove(dataPath(outboxFile))
+	})
+}
+
+func TestOutboxSurvivesCrash(t *testing.T) {
+	resetOutbox(t)
+	now := time.Now()
+
+	first := enqueueAlert(Alert{ChatID: 1, Symbol: "BTCUSDT"}, now)
+	second := enqueueAlert(Alert{ChatID: 2, Symbol: "ETHUSDT"}, now)
+	if first.Attempts != 1 || !first.NextAttempt.After(now) {
+		t.Errorf("enqueued entry = %+v, want it claimed for one attempt", first)
+	}
+	if due := claimDueAlerts(now); len(due) != 0 {
+		t.Fatalf("claimDueAlerts returned %d entries already being delivered", len(due))
+	}
+
+	crashOutbox()
+	loadOutbox()
+
+	due := claimDueAlerts(now)
+	if len(due) != 2 {
+		t.Fatalf("claimDueAlerts after reload returned %d entries, want 2", len(due))
+	}
+	if due[0].ID != first.ID || due[0].Alert.Symbol != "BTCUSDT" || due[1].ID != second.ID || due[1].Alert.Symbol != "ETHUSDT" {
+		t.Errorf("reloaded entries = %+v, want the enqueued alerts in order", due)
+	}
+	if due[0].Attempts != 2 {
+		t.Errorf("reloaded entry has %d attempts, want 2", due[0].Attempts)
+	}
+	if again := claimDueAlerts(now); len(again) != 0 {
+		t.Errorf("claimDueAlerts claimed %d entries twice", len(again))
+	}
+
+	if next := enqueueAlert(Alert{ChatID: 3}, now); next.ID <= second.ID {
+		t.Errorf("ID after reload = %d, want above %d", next.ID, second.ID)
+	}
+
+	removeQueuedAlert(first.ID)
+	crashOutbox()
+	loadOutbox()
+	outboxMu.Lock()
+	_, kept := outbox[first.ID]
+	remaining := len(outbox)
+	outboxMu.Unlock()
+	if kept || remaining != 2 {
+		t.Errorf("after removing a delivered alert the reloaded outbox holds %d entries, delivered kept = %v", remaining, kept)
+	}
+}
+
+func TestOutboxDropsExpired(t *testing.T) {
+	resetOutbox(t)
+	now := time.Now()
+
+	enqueueAlert(Alert{ChatID: 1, Symbol: "BTCUSDT"}, now)
+	if due := claimDueAlerts(now.Add(currentSettings().AlertQueueTTL + time.Second)); len(due) != 0 {
+		t.Errorf("claimDueAlerts returned %d expired entries", len(due))
+	}
+	crashOutbox()
+	loadOutbox()
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	if len(outbox) != 0 {
+		t.Errorf("expired entry persisted: %d entries", len(outbox))
+	}
+}
+
+func TestOutboxKeepsTextAlerts(t *testing.T) {
+	resetOutbox(t)
+	now := time.Now()
+
+	enqueueMessage(7, "📈 Breakout: BTCUSDT", true, now)
+	crashOutbox()
+	loadOutbox()
+
+	due := claimDueAlerts(now)
+	if len(due) != 1 {
+		t.Fatalf("claimDueAlerts after reload returned %d entries, want 1", len(due))
+	}
+	if entry := due[0]; entry.Alert.ChatID != 7 || entry.Text != "📈 Breakout: BTCUSDT" || !entry.Silent {
+		t.Errorf("reloaded text alert = %+v", entry)
+	}
+}
+
+// testFarFuture is a time at which every entry is due but none expired.
+func testFarFuture() time.Time {
+	return time.Now().Add(outboxMaxRetryDelay)
+}
+
+func TestOutboxResumesSplitText(t *testing.T) {
+	resetOutbox(t)
+	fake := useFakeTelegram(t, http.StatusInternalServerError)
+	fake.succeedFirst = 1
+	const chatID int64 = 6601
+
+	line := strings.Repeat("x", 100) + "\n"
+	text := strings.Repeat(line, telegramMessageLimit/len(line)*3)
+	chunks := splitMessage(text, telegramMessageLimit)
+	if len(chunks) < 3 {
+		t.Fatalf("test text split into %d chunks, want at least 3", len(chunks))
+	}
+
+	if err := queueMessage(chatID, text, false); err == nil {
+		t.Fatal("queueMessage returned no error with Telegram failing")
+	}
+	crashOutbox()
+	loadOutbox()
+
+	fake.mu.Lock()
+	fake.status = http.StatusOK
+	fake.mu.Unlock()
+	due := claimDueAlerts(testFarFuture())
+	if len(due) != 1 || due[0].Sent != 1 {
+		t.Fatalf("reloaded outbox = %+v, want one entry with 1 chunk sent", due)
+	}
+	if err := deliverAlert(due[0]); err != nil {
+		t.Fatalf("deliverAlert returned error: %v", err)
+	}
+
+	var delivered []string
+	for i, form := range fake.sent() {
+		if i != 1 { // the failed attempt at the second chunk
+			delivered = append(delivered, form.Get("text"))
+		}
+	}
+	if !slices.Equal(delivered, chunks) {
+		t.Errorf("delivered %d chunks, want each of the %d exactly once", len(delivered), len(chunks))
+	}
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+	if len(outbox) != 0 {
+		t.Errorf("outbox holds %d entries after delivery, want 0", len(outbox))
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	alertPerSymbol = ""
+	alertPerBase   = "base"
+)
+
+// symbolBaseAsset returns the base asset of symbol, e.g. BTC for BTCUSDT,
+// falling back to the symbol itself when exchangeInfo does not know it.
+func symbolBaseAsset(symbol string) string {
+	symbols, err := getExchangeInfo()
+	if err != nil {
+		log.Printf("Error getting base asset of %s: %v", symbol, err)
+		return symbol
+	}
+	if info, ok := symbols[symbol]; ok {
+		return info.BaseAsset
+	}
+	return symbol
+}
+
+// orderByBaseLiquidity reorders results so that pairs sharing a base asset
+// are adjacent, most liquid first, with the groups kept in the order their
+// first pair appeared.
+func orderByBaseLiquidity(results []symbolVolume, base func(string) string, liquidity func(symbolVolume) float64) []symbolVolume {
+	group := make(map[string]int)
+	bases := make([]string, len(results))
+	for i, result := range results {
+		bases[i] = base(result.Symbol)
+		if _, ok := group[bases[i]]; !ok {
+			group[bases[i]] = len(group)
+		}
+	}
+
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ga, gb := group[bases[order[a]]], group[bases[order[b]]]
+		if ga != gb {
+			return ga < gb
+		}
+		return liquidity(results[order[a]]) > liquidity(results[order[b]])
+	})
+
+	ordered := make([]symbolVolume, len(results))
+	for i, j := range order {
+		ordered[i] = results[j]
+	}
+	return ordered
+}
+
+// orderForPerBaseAlerts orders a scan's results for a chat alerting per
+// base asset. Liquidity is the pair's 24h base-asset volume, which is
+// comparable across quotes of the same base; without tickers it falls back
+// to the baseline candle's metric.
+func orderForPerBaseAlerts(results []symbolVolume) []symbolVolume {
+	tickers, err := getBulkTickers()
+	if err != nil {
+		log.Printf("Error getting 24h tickers, ranking pairs by baseline instead: %v\n", err)
+	}
+	return orderByBaseLiquidity(results, symbolBaseAsset, func(result symbolVolume) float64 {
+		if tickers == nil {
+			return result.Data.PrevVolume
+		}
+		volume, _ := strconv.ParseFloat(tickers[result.Symbol].Volume, 64)
+		return volume
+	})
+}
+
+func handleAlertPerCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "symbol":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.AlertPer = alertPerSymbol
+		})
+		return "Every symbol alerts on its own."
+	case alertPerBase:
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.AlertPer = alertPerBase
+		})
+		return "Pairs of the same base asset, such as ETHUSDT and ETHBTC, alert once per scan, on the most liquid pair that crossed the threshold."
+	default:
+		return "Usage: /alertper symbol|base"
+	}
+}
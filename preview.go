@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const previewSampleSize = 10
+
+// scanCandidates returns the symbols of the universe a scan will fetch,
+// leaving out those in their not-found backoff.
+func scanCandidates(symbols []string, now time.Time) []string {
+	candidates := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if !shouldSkipSymbol(symbol, now) {
+			candidates = append(candidates, symbol)
+		}
+	}
+	return candidates
+}
+
+func handlePreviewCommand(chatID int64) string {
+	cfg := getChatConfig(chatID)
+	symbols, err := getMonitoredSymbols(cfg)
+	if err != nil {
+		return fmt.Sprintf("Could not fetch the symbol universe: %v", err)
+	}
+
+	candidates := scanCandidates(symbols, time.Now())
+	skipped := len(symbols) - len(candidates)
+
+	// Symbols Binance doesn't list or has halted would come back not found,
+	// so leave them out when exchange info is available.
+	notTrading := 0
+	if info, err := getExchangeInfo(); err == nil {
+		trading := candidates[:0]
+		for _, symbol := range candidates {
+			if s, ok := info[symbol]; ok && s.Status == "TRADING" {
+				trading = append(trading, symbol)
+			} else {
+				notTrading++
+			}
+		}
+		candidates = trading
+	}
+
+	var sb strings.Builder
+	universe := fmt.Sprintf("top %d by %s", len(symbols), rankLabel(cfg.RankBy))
+	if cfg.Category != "" {
+		universe = fmt.Sprintf("category %s (%d coins)", cfg.Category, len(symbols))
+	}
+	fmt.Fprintf(&sb, "Universe: %s\n", universe)
+	if skipped > 0 {
+		fmt.Fprintf(&sb, "Skipped after not-found responses: %d\n", skipped)
+	}
+	if notTrading > 0 {
+		fmt.Fprintf(&sb, "Not trading on Binance: %d\n", notTrading)
+	}
+	fmt.Fprintf(&sb, "Symbols scanned: %d\n", len(candidates))
+
+	if len(candidates) > 0 {
+		sample := candidates
+		if len(sample) > previewSampleSize {
+			sample = sample[:previewSampleSize]
+		}
+		fmt.Fprintf(&sb, "Sample: %s", strings.Join(sample, ", "))
+		if len(candidates) > len(sample) {
+			fmt.Fprintf(&sb, " and %d more", len(candidates)-len(sample))
+		}
+		sb.WriteString("\n")
+	}
+	if len(cfg.MinVolume) > 0 {
+		fmt.Fprintf(&sb, "At scan time, symbols below the minimum quote volume (%s) are also left out.\n", formatMinVolume(cfg.MinVolume, cfg.Precision))
+	}
+	fmt.Fprintf(&sb, "Candles younger than %g%% of the interval are also left out at scan time.", currentSettings().MinCandleProgress*100)
+	return sb.String()
+}
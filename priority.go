@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxPrioritySymbols bounds the priority set of a chat.
+const maxPrioritySymbols = 50
+
+// isPrioritySymbol reports whether the chat marked symbol as high priority.
+// Priority alerts bypass the cooldown, digests, silent mode and auto-pause.
+func isPrioritySymbol(cfg ChatConfig, symbol string) bool {
+	return containsString(cfg.Priority, symbol)
+}
+
+func handlePriorityCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+
+	if len(fields) == 0 {
+		cfg := getChatConfig(chatID)
+		if len(cfg.Priority) == 0 {
+			return "No priority symbols. Usage: /priority <symbol> [symbol...], or /priority off"
+		}
+		return "Priority symbols: " + strings.Join(cfg.Priority, ", ")
+	}
+
+	if len(fields) == 1 && strings.ToLower(fields[0]) == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Priority = nil
+		})
+		return "Priority symbols cleared."
+	}
+
+	if len(fields) > maxPrioritySymbols {
+		return fmt.Sprintf("At most %d priority symbols are supported", maxPrioritySymbols)
+	}
+
+	var symbols []string
+	for _, field := range fields {
+		symbol, err := resolveSymbol(chatID, field)
+		if err != nil {
+			return err.Error()
+		}
+		if !containsString(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Priority = symbols
+	})
+	return fmt.Sprintf("Priority symbols set: %s. Their alerts always notify and skip cooldowns, digests and auto-pause.", strings.Join(symbols, ", "))
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// httpClient is shared by every outgoing request: Binance, CoinGecko, Bybit
+// and Telegram. Its transport honours HTTP_PROXY/HTTPS_PROXY, or PROXY_URL
+// when set.
+var httpClient = &http.Client{Transport: newTransport(nil)}
+
+// newTransport clones the default transport, routing requests through proxy
+// or, if it is nil, through the proxy named by the environment.
+func newTransport(proxy *url.URL) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return transport
+}
+
+// configureProxy applies PROXY_URL to the shared client and the webhook
+// client.
+func configureProxy(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+
+	proxy, err := url.Parse(proxyURL)
+	if err != nil || proxy.Host == "" {
+		return fmt.Errorf("invalid PROXY_URL %q", proxyURL)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("PROXY_URL scheme must be http, https or socks5")
+	}
+
+	httpClient.Transport = newTransport(proxy)
+	webhookClient.Transport = newWebhookTransport(proxy)
+	log.Printf("Routing outgoing requests through proxy %s", proxy.Redacted())
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConfigureProxyRoutesRequests(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the target.
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.String())
+		mu.Unlock()
+		io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	httpTransport, webhookTransport := httpClient.Transport, webhookClient.Transport
+	t.Cleanup(func() {
+		httpClient.Transport = httpTransport
+		webhookClient.Transport = webhookTransport
+	})
+	if err := configureProxy(proxy.URL); err != nil {
+		t.Fatalf("configureProxy(%q) returned error: %v", proxy.URL, err)
+	}
+
+	resp, err := httpClient.Get("http://api.binance.invalid/api/v3/time")
+	if err != nil {
+		t.Fatalf("request through the proxy failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "via proxy" {
+		t.Errorf("response body = %q, want the proxy's", body)
+	}
+
+	resp, err = webhookClient.Post("http://hooks.invalid/alert", "application/json", nil)
+	if err != nil {
+		t.Fatalf("webhook through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"GET http://api.binance.invalid/api/v3/time", "POST http://hooks.invalid/alert"}
+	if len(seen) != len(want) || seen[0] != want[0] || seen[1] != want[1] {
+		t.Errorf("proxy saw %v, want %v", seen, want)
+	}
+}
+
+func TestConfigureProxyInvalid(t *testing.T) {
+	httpTransport := httpClient.Transport
+	t.Cleanup(func() { httpClient.Transport = httpTransport })
+
+	for _, proxyURL := range []string{"not a url", "http://", "ftp://proxy:21", "://missing-scheme"} {
+		if err := configureProxy(proxyURL); err == nil {
+			t.Errorf("configureProxy(%q) returned no error", proxyURL)
+		}
+	}
+	if httpClient.Transport != httpTransport {
+		t.Error("an invalid PROXY_URL replaced the transport")
+	}
+	if err := configureProxy(""); err != nil || httpClient.Transport != httpTransport {
+		t.Errorf("configureProxy(\"\") = %v, want no change", err)
+	}
+}
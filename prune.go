@@ -0,0 +1,162 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// startPruner periodically drops per-chat and per-symbol state that is no
+// longer needed so the in-memory maps don't grow without bound.
+func startPruner() {
+	interval := currentSettings().PruneInterval
+	if interval <= 0 {
+		log.Printf("PRUNE_INTERVAL is %s, pruning disabled", interval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			pruneState(now)
+		}
+	}()
+}
+
+func chatIsMonitoring(chatID int64) bool {
+	monitoring, _ := monitoringStatus.Load(chatID)
+	return monitoring != nil && monitoring.(bool)
+}
+
+// pruneState removes entries older than StateTTL and all transient state of
+// chats that are not monitoring.
+func pruneState(now time.Time) {
+	cutoff := now.Add(-currentSettings().StateTTL)
+	removed := 0
+
+	alertRecordsMu.Lock()
+	for key, record := range alertRecords {
+		if record.Time.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(alertRecords, key)
+			removed++
+		}
+	}
+	alertRecordsMu.Unlock()
+
+	breakoutSentMu.Lock()
+	for key, openTime := range breakoutSent {
+		if openTime.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(breakoutSent, key)
+			removed++
+		}
+	}
+	breakoutSentMu.Unlock()
+
+	volatilitiesMu.Lock()
+	for symbol, entry := range volatilities {
+		if now.Sub(entry.UpdatedAt) > adaptiveVolatilityTTL {
+			delete(volatilities, symbol)
+			removed++
+		}
+	}
+	volatilitiesMu.Unlock()
+
+	basketSentMu.Lock()
+	for key, openTime := range basketSent {
+		if openTime.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(basketSent, key)
+			removed++
+		}
+	}
+	basketSentMu.Unlock()
+
+	rangeSpikeSentMu.Lock()
+	for key, openTime := range rangeSpikeSent {
+		if openTime.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(rangeSpikeSent, key)
+			removed++
+		}
+	}
+	rangeSpikeSentMu.Unlock()
+
+	rvolSentMu.Lock()
+	for key, openTime := range rvolSent {
+		if openTime.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(rvolSent, key)
+			removed++
+		}
+	}
+	rvolSentMu.Unlock()
+
+	effectivenessMu.Lock()
+	kept := pendingChecks[:0]
+	for _, check := range pendingChecks {
+		if chatIsMonitoring(check.ChatID) {
+			kept = append(kept, check)
+		}
+	}
+	removed += len(pendingChecks) - len(kept)
+	pendingChecks = kept
+	effectivenessMu.Unlock()
+
+	lastRatiosMu.Lock()
+	for key, last := range lastRatios {
+		if last.ScannedAt.Before(cutoff) || !chatIsMonitoring(key.ChatID) {
+			delete(lastRatios, key)
+			removed++
+		}
+	}
+	for chatID, scannedAt := range lastScans {
+		if scannedAt.Before(cutoff) || !chatIsMonitoring(chatID) {
+			delete(lastScans, chatID)
+		}
+	}
+	lastRatiosMu.Unlock()
+
+	snoozedUntilMu.Lock()
+	for key, until := range snoozedUntil {
+		if now.After(until) {
+			delete(snoozedUntil, key)
+			removed++
+		}
+	}
+	snoozedUntilMu.Unlock()
+
+	alertTimesMu.Lock()
+	for chatID := range alertTimes {
+		if !chatIsMonitoring(chatID) {
+			delete(alertTimes, chatID)
+			removed++
+		}
+	}
+	alertTimesMu.Unlock()
+
+	for _, m := range []interface {
+		Range(func(key, value interface{}) bool)
+		Delete(key interface{})
+	}{&recentScans, &warmupScans} {
+		m.Range(func(key, value interface{}) bool {
+			if !chatIsMonitoring(key.(int64)) {
+				m.Delete(key)
+				removed++
+			}
+			return true
+		})
+	}
+
+	baselinesMu.Lock()
+	for key, b := range baselines {
+		if b.UpdatedAt.Before(cutoff) {
+			delete(baselines, key)
+			baselinesDirty = true
+			removed++
+		}
+	}
+	baselinesMu.Unlock()
+
+	removed += pruneAlertHistory(now.Add(-currentSettings().HistoryRetention))
+	removed += pruneOnboardings(now)
+
+	if removed > 0 {
+		log.Printf("Pruned %d stale state entries", removed)
+	}
+}
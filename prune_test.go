@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPruneStateAlertRecords(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-currentSettings().StateTTL)
+	const monitoring, stopped int64 = 8001, 8002
+	monitoringStatus.Store(monitoring, true)
+	t.Cleanup(func() { monitoringStatus.Delete(monitoring) })
+
+	fresh := alertKey{monitoring, "FRESHUSDT"}
+	stale := alertKey{monitoring, "STALEUSDT"}
+	atCutoff := alertKey{monitoring, "EDGEUSDT"}
+	idle := alertKey{stopped, "FRESHUSDT"}
+	alertRecordsMu.Lock()
+	alertRecords[fresh] = alertRecord{Time: now}
+	alertRecords[stale] = alertRecord{Time: cutoff.Add(-time.Second)}
+	alertRecords[atCutoff] = alertRecord{Time: cutoff}
+	alertRecords[idle] = alertRecord{Time: now}
+	alertRecordsMu.Unlock()
+	t.Cleanup(func() {
+		alertRecordsMu.Lock()
+		for _, key := range []alertKey{fresh, stale, atCutoff, idle} {
+			delete(alertRecords, key)
+		}
+		alertRecordsMu.Unlock()
+	})
+
+	pruneState(now)
+
+	alertRecordsMu.Lock()
+	defer alertRecordsMu.Unlock()
+	for key, want := range map[alertKey]bool{fresh: true, atCutoff: true, stale: false, idle: false} {
+		if _, kept := alertRecords[key]; kept != want {
+			t.Errorf("alertRecords[%v] kept = %v, want %v", key, kept, want)
+		}
+	}
+}
+
+func TestPruneAlertHistory(t *testing.T) {
+	alertHistoryMu.Lock()
+	saved := alertHistory
+	alertHistoryMu.Unlock()
+	t.Cleanup(func() {
+		alertHistoryMu.Lock()
+		alertHistory = saved
+		alertHistoryMu.Unlock()
+	})
+
+	cutoff := time.Now().Add(-time.Hour)
+	alertHistoryMu.Lock()
+	alertHistory = []historyEntry{
+		{ChatID: 1, Symbol: "OLDUSDT", Time: cutoff.Add(-time.Minute)},
+		{ChatID: 1, Symbol: "EDGEUSDT", Time: cutoff},
+		{ChatID: 2, Symbol: "NEWUSDT", Time: cutoff.Add(time.Minute)},
+	}
+	alertHistoryDirty = false
+	alertHistoryMu.Unlock()
+
+	if removed := pruneAlertHistory(cutoff); removed != 1 {
+		t.Errorf("pruneAlertHistory removed %d entries, want 1", removed)
+	}
+	alertHistoryMu.Lock()
+	defer alertHistoryMu.Unlock()
+	if len(alertHistory) != 2 || alertHistory[0].Symbol != "EDGEUSDT" || alertHistory[1].Symbol != "NEWUSDT" {
+		t.Errorf("history after pruning = %v", alertHistory)
+	}
+	if !alertHistoryDirty {
+		t.Error("pruning did not mark the history for saving")
+	}
+}
+
+func TestStartPrunerDisabled(t *testing.T) {
+	s := *currentSettings()
+	s.PruneInterval = 0
+	previous := settings.Swap(&s)
+	t.Cleanup(func() { settings.Store(previous) })
+
+	startPruner() // must not panic
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRangeSpikeLookback = 20
+	minRangeSpikeLookback     = 2
+	maxRangeSpikeLookback     = 500
+)
+
+// rangeSpikeSent remembers the candle each symbol last spiked on so a
+// range spike is reported once per candle.
+var (
+	rangeSpikeSent   = make(map[alertKey]time.Time)
+	rangeSpikeSentMu sync.Mutex
+)
+
+// candleRange returns a kline's high minus its low.
+func candleRange(kline BinanceKline) (float64, error) {
+	high, err := klineFloat(kline, 2)
+	if err != nil {
+		return 0, fmt.Errorf("invalid high: %v", err)
+	}
+	low, err := klineFloat(kline, 3)
+	if err != nil {
+		return 0, fmt.Errorf("invalid low: %v", err)
+	}
+	return high - low, nil
+}
+
+// rangeMultiple compares the range of the last kline with the average range
+// of the ones before it.
+func rangeMultiple(klines []BinanceKline) (multiple, current, average float64, err error) {
+	if len(klines) < 2 {
+		return 0, 0, 0, fmt.Errorf("need at least 2 klines, got %d", len(klines))
+	}
+	for _, kline := range klines[:len(klines)-1] {
+		r, err := candleRange(kline)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		average += r
+	}
+	average /= float64(len(klines) - 1)
+	if current, err = candleRange(klines[len(klines)-1]); err != nil {
+		return 0, 0, 0, err
+	}
+	if average == 0 {
+		return 0, current, 0, fmt.Errorf("average range is zero")
+	}
+	return current / average, current, average, nil
+}
+
+func rangeSpikeLookback(cfg ChatConfig) int {
+	if cfg.RangeSpikeLookback > 0 {
+		return cfg.RangeSpikeLookback
+	}
+	return defaultRangeSpikeLookback
+}
+
+// checkRangeSpikes alerts when a symbol's current candle spans more than
+// cfg.RangeSpike times the average range of the candles before it. Like
+// the volume ratio, the current candle is the last closed one unless the
+// chat uses live candles.
+func checkRangeSpikes(chatID int64, cfg ChatConfig, results []symbolVolume) {
+	if cfg.RangeSpike == 0 {
+		return
+	}
+	lookback := rangeSpikeLookback(cfg)
+	closed := comparesClosed(cfg)
+
+	for _, result := range results {
+		klines, err := getBinanceKlines(result.Symbol, candlesNeeded(lookback+1, closed))
+		if err != nil {
+			log.Printf("Error getting kline data for %s: %v\n", result.Symbol, err)
+			continue
+		}
+		klines = comparedKlines(klines, closed)
+		if len(klines) < lookback+1 {
+			continue
+		}
+
+		multiple, current, average, err := rangeMultiple(klines)
+		if err != nil || multiple <= cfg.RangeSpike {
+			continue
+		}
+
+		key := alertKey{chatID, result.Symbol}
+		openTime := time.UnixMilli(klineOpenTime(klines[len(klines)-1]))
+		rangeSpikeSentMu.Lock()
+		sent := rangeSpikeSent[key].Equal(openTime)
+		rangeSpikeSent[key] = openTime
+		rangeSpikeSentMu.Unlock()
+		if sent {
+			continue
+		}
+
+		message := fmt.Sprintf("📏 Range spike: %s candle range %s is %s its %d-candle average of %s (volume %s)",
+			result.Symbol, formatPrice(current), formatRatio(multiple, cfg.Precision), lookback, formatPrice(average),
+			formatRatio(result.Data.Ratio, cfg.Precision))
+		queueMessage(chatID, message, cfg.Silent)
+	}
+}
+
+func handleRangeSpikeCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+	usage := fmt.Sprintf("Usage: /rangespike <multiple> [candles], e.g. /rangespike 3 %d, or /rangespike off", defaultRangeSpikeLookback)
+
+	if len(fields) == 1 && fields[0] == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.RangeSpike = 0
+			cfg.RangeSpikeLookback = 0
+		})
+		return "Range spike alerts disabled."
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return usage
+	}
+
+	multiple, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "x"), 64)
+	if err != nil || multiple <= 1 {
+		return "Multiple must be a number greater than 1"
+	}
+	lookback := 0
+	if len(fields) == 2 {
+		lookback, err = strconv.Atoi(fields[1])
+		if err != nil || lookback < minRangeSpikeLookback || lookback > maxRangeSpikeLookback {
+			return fmt.Sprintf("Candles must be a number between %d and %d", minRangeSpikeLookback, maxRangeSpikeLookback)
+		}
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.RangeSpike = multiple
+		cfg.RangeSpikeLookback = lookback
+	})
+	return fmt.Sprintf("Alerting when a candle's range exceeds %gx the average of the previous %d candles.", multiple, rangeSpikeLookback(cfg))
+}
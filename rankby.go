@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	rankByMarketCap = "marketcap"
+	rankByVolume    = "volume"
+)
+
+// coinGeckoOrder returns the CoinGecko markets order parameter for a chat's
+// rank-by setting.
+func coinGeckoOrder(rankBy string) string {
+	if rankBy == rankByVolume {
+		return "volume_desc"
+	}
+	return "market_cap_desc"
+}
+
+// rankLabel describes how the universe is ordered, e.g. "24h volume".
+func rankLabel(rankBy string) string {
+	if rankBy == rankByVolume {
+		return "24h volume"
+	}
+	return "market cap"
+}
+
+func handleRankByCommand(chatID int64, args string) string {
+	var rankBy string
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case rankByVolume:
+		rankBy = rankByVolume
+	case rankByMarketCap:
+		rankBy = ""
+	default:
+		return "Usage: /rankby volume|marketcap"
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.RankBy = rankBy
+	})
+	return fmt.Sprintf("Monitoring the top coins by %s.", rankLabel(rankBy))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recentTrigger is a symbol that crossed the threshold during a scan.
+type recentTrigger struct {
+	Symbol string
+	Ratio  float64
+}
+
+// recentScan holds the triggers of the last completed scan of a chat.
+type recentScan struct {
+	CompletedAt time.Time
+	Triggers    []recentTrigger
+}
+
+var recentScans sync.Map
+
+func storeRecentScan(chatID int64, triggers []recentTrigger) {
+	recentScans.Store(chatID, recentScan{
+		CompletedAt: time.Now(),
+		Triggers:    triggers,
+	})
+}
+
+func handleRecentCommand(chatID int64) string {
+	value, ok := recentScans.Load(chatID)
+	if !ok {
+		return "No scan has completed yet for this chat."
+	}
+
+	result := value.(recentScan)
+	completed := result.CompletedAt.Format("2006-01-02 15:04:05")
+	if len(result.Triggers) == 0 {
+		return fmt.Sprintf("No symbols triggered in the last scan (completed %s).", completed)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Symbols triggered in the last scan (completed %s):\n", completed)
+	for _, trigger := range result.Triggers {
+		fmt.Fprintf(&sb, "%s: %.2fx\n", trigger.Symbol, trigger.Ratio)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
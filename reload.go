@@ -0,0 +1,128 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/joho/godotenv"
+)
+
+// restartOnlySettings are applied once at startup, so a reload keeps their
+// old values and only logs that a restart is needed. Bot tokens are read
+// outside Settings and likewise require a restart.
+var restartOnlySettings = map[string]bool{
+	"BinanceBaseURL":  true,
+	"BinanceEndpoint": true,
+	"ProxyURL":        true,
+	"PruneInterval":   true,
+	"APIAddr":         true,
+	"DataDir":         true,
+}
+
+// secretSettings are reloaded without logging their values.
+var secretSettings = map[string]bool{
+	"APIToken":     true,
+	"SMTPPassword": true,
+}
+
+var (
+	// externalEnv holds the variables set by the real environment, which
+	// take precedence over .env both at startup and on reload.
+	externalEnv map[string]bool
+	// dotenvKeys holds the variables last set from .env.
+	dotenvKeys  map[string]bool
+	reloadEnvMu sync.Mutex
+)
+
+func captureExternalEnv() {
+	externalEnv = make(map[string]bool)
+	for _, kv := range os.Environ() {
+		if key, _, ok := strings.Cut(kv, "="); ok {
+			externalEnv[key] = true
+		}
+	}
+}
+
+// loadEnvFile applies .env to the process environment without overriding
+// variables from the real environment. Variables an earlier load set but
+// that have since been removed from the file are unset.
+func loadEnvFile() error {
+	reloadEnvMu.Lock()
+	defer reloadEnvMu.Unlock()
+
+	values, err := godotenv.Read()
+	if err != nil {
+		return err
+	}
+
+	for key := range dotenvKeys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+		}
+	}
+	dotenvKeys = make(map[string]bool, len(values))
+	for key, value := range values {
+		if externalEnv[key] {
+			continue
+		}
+		os.Setenv(key, value)
+		dotenvKeys[key] = true
+	}
+	return nil
+}
+
+// reloadSettings re-reads .env and the environment and swaps in the new
+// settings, logging each change.
+func reloadSettings() {
+	if err := loadEnvFile(); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error reloading .env file: %v", err)
+		return
+	}
+
+	prev := currentSettings()
+	next := loadSettings()
+
+	prevValue := reflect.ValueOf(prev).Elem()
+	nextValue := reflect.ValueOf(&next).Elem()
+	changed := 0
+	for i := 0; i < nextValue.NumField(); i++ {
+		name := nextValue.Type().Field(i).Name
+		oldField, newField := prevValue.Field(i), nextValue.Field(i)
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+		if restartOnlySettings[name] {
+			log.Printf("Setting %s changed but requires a restart; keeping %v", name, oldField.Interface())
+			newField.Set(oldField)
+			continue
+		}
+		if secretSettings[name] {
+			log.Printf("Setting %s changed", name)
+		} else {
+			log.Printf("Setting %s changed from %v to %v", name, oldField.Interface(), newField.Interface())
+		}
+		changed++
+	}
+
+	settings.Store(&next)
+	if next.MaxConcurrentFetches != prev.MaxConcurrentFetches {
+		initFetchLimiter(next.MaxConcurrentFetches)
+	}
+	log.Printf("Settings reloaded, %d changed", changed)
+}
+
+// watchReloadSignal reloads the settings on every SIGHUP.
+func watchReloadSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			reloadSettings()
+		}
+	}()
+}
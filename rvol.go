@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRVOLDays = 20
+	minRVOLDays     = 1
+	// maxRVOLDays keeps the hourly klines needed within Binance's limit of
+	// 1000 per request.
+	maxRVOLDays = 40
+)
+
+// rvolSent remembers the candle each symbol's RVOL last alerted on so it is
+// reported once per candle.
+var (
+	rvolSent   = make(map[alertKey]time.Time)
+	rvolSentMu sync.Mutex
+)
+
+func rvolDays(cfg ChatConfig) int {
+	if cfg.RVOLDays > 0 {
+		return cfg.RVOLDays
+	}
+	return defaultRVOLDays
+}
+
+// sameTimeOfDayAverage averages the field of the klines that opened at the
+// same time of day as openTime on earlier days.
+func sameTimeOfDayAverage(klines []BinanceKline, field int, openTime time.Time) (average float64, days int) {
+	day := (24 * time.Hour).Milliseconds()
+	open := openTime.UnixMilli()
+	for _, kline := range klines {
+		t := klineOpenTime(kline)
+		if t >= open || (open-t)%day != 0 {
+			continue
+		}
+		v, err := klineFloat(kline, field)
+		if err != nil {
+			continue
+		}
+		average += v
+		days++
+	}
+	if days > 0 {
+		average /= float64(days)
+	}
+	return average, days
+}
+
+// rvolBaseline returns the symbol's average metric for the candle opening
+// at openTime over the past days, cached with the other baselines while
+// that candle is current.
+func rvolBaseline(symbol, metric string, openTime time.Time, days int) (float64, error) {
+	key := baselineKey(symbol, metric, "rvol", days)
+	if b, ok := lookupBaseline(key); ok && b.OpenTime == openTime.UnixMilli() {
+		return b.Value, nil
+	}
+
+	klines, err := getBinanceKlines(symbol, days*24+2)
+	if err != nil {
+		return 0, err
+	}
+	average, found := sameTimeOfDayAverage(klines, metricField(metric), openTime)
+	if found == 0 || average == 0 {
+		return 0, errors.New("no history for this time of day")
+	}
+
+	storeBaseline(key, volumeBaseline{Value: average, OpenTime: openTime.UnixMilli(), UpdatedAt: time.Now()})
+	return average, nil
+}
+
+// checkRVOL alerts when a symbol's relative volume, its current candle
+// against the average candle at the same time of day over the past
+// rvolDays days, exceeds cfg.RVOL. It does nothing with /daily, whose
+// rolling 24h values have no time of day.
+func checkRVOL(chatID int64, cfg ChatConfig, results []symbolVolume) {
+	if cfg.RVOL == 0 || (cfg.Daily && dailySupported(cfg.Metric)) {
+		return
+	}
+	days := rvolDays(cfg)
+
+	for _, result := range results {
+		baseline, err := rvolBaseline(result.Symbol, cfg.Metric, result.Data.OpenTime, days)
+		if err != nil {
+			log.Printf("Error getting RVOL baseline for %s: %v\n", result.Symbol, err)
+			continue
+		}
+		rvol := result.Data.CurrVolume / baseline
+		if rvol <= cfg.RVOL {
+			continue
+		}
+
+		key := alertKey{chatID, result.Symbol}
+		rvolSentMu.Lock()
+		sent := rvolSent[key].Equal(result.Data.OpenTime)
+		rvolSent[key] = result.Data.OpenTime
+		rvolSentMu.Unlock()
+		if sent {
+			continue
+		}
+
+		message := fmt.Sprintf("🕒 RVOL: %s %s is %s its %d-day average for the %s UTC candle (%s vs %s)",
+			result.Symbol, strings.ToLower(metricLabel(cfg.Metric)), formatRatio(rvol, cfg.Precision), days,
+			result.Data.OpenTime.UTC().Format("15:04"), formatVolume(result.Data.CurrVolume, cfg.Precision), formatVolume(baseline, cfg.Precision))
+		queueMessage(chatID, message, cfg.Silent)
+	}
+}
+
+func handleRVOLCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+	usage := fmt.Sprintf("Usage: /rvol <threshold> [days], e.g. /rvol 2.0 %d, or /rvol off", defaultRVOLDays)
+
+	if len(fields) == 1 && fields[0] == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.RVOL = 0
+			cfg.RVOLDays = 0
+		})
+		return "RVOL alerts disabled."
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return usage
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "x"), 64)
+	if err != nil || threshold <= 1 {
+		return "Threshold must be a number greater than 1"
+	}
+	days := 0
+	if len(fields) == 2 {
+		days, err = strconv.Atoi(strings.TrimSuffix(fields[1], "d"))
+		if err != nil || days < minRVOLDays || days > maxRVOLDays {
+			return fmt.Sprintf("Days must be a number between %d and %d", minRVOLDays, maxRVOLDays)
+		}
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.RVOL = threshold
+		cfg.RVOLDays = days
+	})
+	return fmt.Sprintf("Alerting when a candle's %s exceeds %gx the average at the same time of day over the past %d days.",
+		strings.ToLower(metricLabel(cfg.Metric)), threshold, rvolDays(cfg))
+}
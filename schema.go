@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// klineFieldCount is the number of elements in a Binance kline array:
+// open time, OHLC, volume, close time, quote volume, trades, taker buy base
+// and quote volume, and an unused field.
+const klineFieldCount = 12
+
+// schemaWarningWindow is the period over which schema mismatches are counted
+// before deciding whether to alert the admin.
+const schemaWarningWindow = time.Hour
+
+var errSchemaMismatch = errors.New("unexpected kline schema")
+
+var (
+	schemaWarnings      int
+	schemaWindowStart   time.Time
+	schemaAdminNotified bool
+	schemaWarningsMu    sync.Mutex
+)
+
+// validateKline checks that a kline has the shape the rest of the code
+// indexes into: the expected element count, numeric timestamps and trade
+// count, and parseable numeric strings for prices and volumes.
+func validateKline(kline BinanceKline) error {
+	if len(kline) != klineFieldCount {
+		return fmt.Errorf("%w: %d fields, want %d", errSchemaMismatch, len(kline), klineFieldCount)
+	}
+
+	for _, i := range []int{0, 6, 8} {
+		if _, ok := kline[i].(float64); !ok {
+			return fmt.Errorf("%w: field %d is %T, want number", errSchemaMismatch, i, kline[i])
+		}
+	}
+
+	for _, i := range []int{1, 2, 3, 4, 5, 7, 9, 10} {
+		s, ok := kline[i].(string)
+		if !ok {
+			return fmt.Errorf("%w: field %d is %T, want string", errSchemaMismatch, i, kline[i])
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Errorf("%w: field %d %q is not numeric", errSchemaMismatch, i, s)
+		}
+	}
+
+	return nil
+}
+
+func validateKlines(klines []BinanceKline) error {
+	for _, kline := range klines {
+		if err := validateKline(kline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportSchemaMismatch logs a mismatch and alerts the admin once per window
+// when mismatches become widespread, which usually means Binance changed
+// the response format.
+func reportSchemaMismatch(symbol string, err error) {
+	log.Printf("WARNING: Binance kline schema check failed for %s, skipping: %v", symbol, err)
+
+	schemaWarningsMu.Lock()
+	now := time.Now()
+	if now.Sub(schemaWindowStart) > schemaWarningWindow {
+		schemaWindowStart = now
+		schemaWarnings = 0
+		schemaAdminNotified = false
+	}
+	schemaWarnings++
+	notify := schemaWarnings >= currentSettings().SchemaAlertThreshold && !schemaAdminNotified
+	if notify {
+		schemaAdminNotified = true
+	}
+	count := schemaWarnings
+	schemaWarningsMu.Unlock()
+
+	if notify && currentSettings().AdminChatID != 0 {
+		msg := tgbotapi.NewMessage(currentSettings().AdminChatID, fmt.Sprintf(
+			"⚠️ %d Binance kline responses failed the schema check in the last %s. "+
+				"The API format may have changed. Latest: %s: %v",
+			count, schemaWarningWindow, symbol, err))
+		send(botFor(currentSettings().AdminChatID), msg)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Settings holds the operator-level configuration read from the environment.
+type Settings struct {
+	// AlertCooldown is how long a symbol stays quiet after alerting a chat.
+	AlertCooldown time.Duration
+	// EscalationFactor re-alerts during the cooldown once the ratio has grown
+	// by this multiple over the last alerted ratio. Values <= 1 disable it.
+	EscalationFactor float64
+	// BaselineMaxAge forces persisted volume baselines older than this to be
+	// recomputed even if they still match the latest candle.
+	BaselineMaxAge time.Duration
+	// MinCandleProgress skips symbols whose current candle has run for less
+	// than this fraction of its interval, when its volume is still too small
+	// to compare meaningfully. Configured as a percentage.
+	MinCandleProgress float64
+	// UpdatesConflictMode decides what happens when another instance polls
+	// with the same token: "retry" with backoff, or "exit".
+	UpdatesConflictMode string
+	// UpdatesWatchdogTimeout restarts update polling when no getUpdates
+	// call has succeeded for this long. Zero disables the watchdog.
+	UpdatesWatchdogTimeout time.Duration
+	// MaxConcurrentFetches caps in-flight Binance kline requests.
+	MaxConcurrentFetches int
+	// MaxSymbols caps how many symbols any chat scans, below its tier's
+	// universe. Zero leaves the tiers' limits alone.
+	MaxSymbols int
+	// AdminChatID is the chat allowed to run operator commands.
+	AdminChatID int64
+	// SchemaAlertThreshold is how many kline schema mismatches within an
+	// hour trigger an admin alert.
+	SchemaAlertThreshold int
+	// SilentOverrideMultiple makes alerts at this multiple of a chat's
+	// threshold notify even when the chat has silent alerts enabled.
+	SilentOverrideMultiple float64
+	// BinanceBaseURL overrides the Binance REST base URL.
+	BinanceBaseURL string
+	// BinanceEndpoint selects a predefined endpoint; "mirror" uses the
+	// public data mirror.
+	BinanceEndpoint string
+	// LogRatio logs symbols whose ratio exceeds it without reaching the
+	// alert threshold. Zero disables near-miss logging.
+	LogRatio float64
+	// MarketRelativeFactor is how many times the scan's average ratio a
+	// symbol must reach to alert in market-relative mode.
+	MarketRelativeFactor float64
+	// WarmupCycles is how many scans run without alerting after monitoring
+	// starts.
+	WarmupCycles int
+	// BybitBaseURL is the Bybit REST base URL used for exchange comparisons.
+	BybitBaseURL string
+	// AutoPauseWindow is the rolling window over which /autopause counts
+	// alerts.
+	AutoPauseWindow time.Duration
+	// PruneInterval is how often stale in-memory state is pruned. Zero or
+	// less disables pruning.
+	PruneInterval time.Duration
+	// StateTTL is how long per-symbol state such as cooldowns and
+	// baselines is kept without being refreshed.
+	StateTTL time.Duration
+	// HistoryRetention is how long delivered alerts are kept for summaries.
+	HistoryRetention time.Duration
+	// ProxyURL, if set, routes all outgoing requests through this proxy
+	// instead of the one named by HTTP_PROXY/HTTPS_PROXY.
+	ProxyURL string
+	// DataDir holds every persistent file; relative file names and a
+	// relative SnapshotDir resolve inside it.
+	DataDir string
+	// SnapshotDir is where /snapshot writes its files.
+	SnapshotDir string
+	// StartupDelay holds back all restored monitors after a restart.
+	StartupDelay time.Duration
+	// StartupStagger spreads the restored monitors evenly over this long.
+	StartupStagger time.Duration
+	// AlertQueueTTL is how long an alert Telegram hasn't accepted is
+	// retried before it is dropped.
+	AlertQueueTTL time.Duration
+	// APIAddr is the listen address of the HTTP API, disabled when empty.
+	APIAddr string
+	// APIToken is the bearer token the HTTP API requires.
+	APIToken string
+	// SMTPHost, SMTPPort and SMTPFrom select the server and sender of
+	// alert emails, disabled when the host or sender is empty. With
+	// SMTPUsername set, PLAIN auth is used with SMTPPassword.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// settings holds the active Settings. A reload replaces the whole value,
+// so readers never see a half-applied change.
+var settings atomic.Pointer[Settings]
+
+func currentSettings() *Settings {
+	return settings.Load()
+}
+
+func loadSettings() Settings {
+	return Settings{
+		AlertCooldown:          envDuration("ALERT_COOLDOWN", time.Hour),
+		EscalationFactor:       envFloat("ESCALATION_FACTOR", 3),
+		BaselineMaxAge:         envDuration("BASELINE_MAX_AGE", 6*time.Hour),
+		MinCandleProgress:      envFloat("MIN_CANDLE_PROGRESS_PCT", 10) / 100,
+		UpdatesConflictMode:    envString("UPDATES_CONFLICT_MODE", conflictModeRetry),
+		UpdatesWatchdogTimeout: envDuration("UPDATES_WATCHDOG_TIMEOUT", 5*time.Minute),
+		MaxConcurrentFetches:   envInt("MAX_CONCURRENT_FETCHES", 4),
+		MaxSymbols:             envInt("MAX_SYMBOLS", 0),
+		AdminChatID:            envInt64("ADMIN_CHAT_ID", 0),
+		SchemaAlertThreshold:   envInt("SCHEMA_ALERT_THRESHOLD", 20),
+		SilentOverrideMultiple: envFloat("SILENT_OVERRIDE_MULTIPLE", 4),
+		BinanceBaseURL:         envString("BINANCE_BASE_URL", ""),
+		BinanceEndpoint:        envString("BINANCE_ENDPOINT", ""),
+		LogRatio:               envFloat("LOG_RATIO", 0),
+		MarketRelativeFactor:   envFloat("MARKET_RELATIVE_FACTOR", 2),
+		WarmupCycles:           envInt("WARMUP_CYCLES", 1),
+		BybitBaseURL:           envString("BYBIT_BASE_URL", "https://api.bybit.com"),
+		AutoPauseWindow:        envDuration("AUTOPAUSE_WINDOW", time.Hour),
+		PruneInterval:          envDuration("PRUNE_INTERVAL", 10*time.Minute),
+		StateTTL:               envDuration("STATE_TTL", 24*time.Hour),
+		HistoryRetention:       envDuration("HISTORY_RETENTION", 30*24*time.Hour),
+		ProxyURL:               envString("PROXY_URL", ""),
+		DataDir:                envString("DATA_DIR", "."),
+		SnapshotDir:            envString("SNAPSHOT_DIR", "snapshots"),
+		StartupDelay:           envDuration("STARTUP_DELAY", 0),
+		StartupStagger:         envDuration("STARTUP_STAGGER", time.Minute),
+		AlertQueueTTL:          envDuration("ALERT_QUEUE_TTL", time.Hour),
+		APIAddr:                envString("API_ADDR", ""),
+		APIToken:               envString("API_TOKEN", ""),
+		SMTPHost:               envString("SMTP_HOST", ""),
+		SMTPPort:               envInt("SMTP_PORT", 587),
+		SMTPUsername:           envString("SMTP_USERNAME", ""),
+		SMTPPassword:           envString("SMTP_PASSWORD", ""),
+		SMTPFrom:               envString("SMTP_FROM", ""),
+	}
+}
+
+func envString(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
+
+func envInt(name string, def int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d", name, raw, def)
+		return def
+	}
+	return value
+}
+
+func envInt64(name string, def int64) int64 {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d", name, raw, def)
+		return def
+	}
+	return value
+}
+
+func envFloat(name string, def float64) float64 {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %g", name, raw, def)
+		return def
+	}
+	return value
+}
+
+func envDuration(name string, def time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %s", name, raw, def)
+		return def
+	}
+	return value
+}
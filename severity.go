@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSeverityBands     = 10
+	maxSeverityLabelRune = 16
+)
+
+// SeverityBand labels alerts whose ratio is at least Min.
+type SeverityBand struct {
+	Min   float64 `json:"min"`
+	Label string  `json:"label"`
+}
+
+func (b SeverityBand) String() string {
+	return fmt.Sprintf("%gx=%s", b.Min, b.Label)
+}
+
+// defaultSeverityBands apply to chats that never set /severity.
+var defaultSeverityBands = []SeverityBand{
+	{Min: 5, Label: "⚠️"},
+	{Min: 10, Label: "🔥"},
+	{Min: 20, Label: "🚀"},
+}
+
+func severityBands(cfg ChatConfig) []SeverityBand {
+	if len(cfg.Severity) > 0 {
+		return cfg.Severity
+	}
+	return defaultSeverityBands
+}
+
+// severityLabel returns the label of the highest band ratio reaches, or the
+// lowest band's label for ratios below every band. bands must be sorted by
+// Min.
+func severityLabel(bands []SeverityBand, ratio float64) string {
+	if len(bands) == 0 {
+		return ""
+	}
+	label := bands[0].Label
+	for _, band := range bands {
+		if ratio >= band.Min {
+			label = band.Label
+		}
+	}
+	return label
+}
+
+// parseSeverityBands parses "5=⚠️ 10x=🔥 20=🚀" into bands sorted by Min.
+func parseSeverityBands(fields []string) ([]SeverityBand, error) {
+	if len(fields) > maxSeverityBands {
+		return nil, fmt.Errorf("at most %d bands can be set", maxSeverityBands)
+	}
+
+	var bands []SeverityBand
+	for _, field := range fields {
+		ratio, label, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s: expected <ratio>=<label>", field)
+		}
+		band := SeverityBand{Label: label}
+		var err error
+		if band.Min, err = strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(ratio), "x"), 64); err != nil {
+			return nil, fmt.Errorf("%s: invalid ratio", field)
+		}
+		if err := band.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %v", field, err)
+		}
+		for _, existing := range bands {
+			if existing.Min == band.Min {
+				return nil, fmt.Errorf("%gx is listed twice", band.Min)
+			}
+		}
+		bands = append(bands, band)
+	}
+
+	sort.Slice(bands, func(i, j int) bool { return bands[i].Min < bands[j].Min })
+	return bands, nil
+}
+
+func (b SeverityBand) validate() error {
+	if b.Min <= 0 {
+		return fmt.Errorf("ratio must be positive")
+	}
+	if b.Label == "" || utf8.RuneCountInString(b.Label) > maxSeverityLabelRune {
+		return fmt.Errorf("label must be 1 to %d characters", maxSeverityLabelRune)
+	}
+	return nil
+}
+
+// applySeverity labels the alert with the chat's band for its ratio.
+func applySeverity(alert *Alert, cfg ChatConfig) {
+	alert.Severity = severityLabel(severityBands(cfg), alert.Data.Ratio)
+}
+
+func formatSeverityBands(bands []SeverityBand) string {
+	parts := make([]string, len(bands))
+	for i, band := range bands {
+		parts[i] = band.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+func handleSeverityCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	usage := "Usage: /severity <ratio>=<label> ..., e.g. /severity 5=⚠️ 10=🔥 20=🚀, or /severity reset"
+
+	switch {
+	case len(fields) == 0:
+		return fmt.Sprintf("Alert severity: %s\n%s", formatSeverityBands(severityBands(getChatConfig(chatID))), usage)
+	case len(fields) == 1 && strings.EqualFold(fields[0], "reset"):
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Severity = nil
+		})
+		return fmt.Sprintf("Alert severity reset to %s.", formatSeverityBands(defaultSeverityBands))
+	}
+
+	bands, err := parseSeverityBands(fields)
+	if err != nil {
+		return fmt.Sprintf("%v. %s", err, usage)
+	}
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Severity = bands
+	})
+	return fmt.Sprintf("Alerts will be labeled %s.", formatSeverityBands(bands))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// drainTimeout bounds how long shutdown waits for workers before persisting
+// anyway.
+const drainTimeout = 30 * time.Second
+
+var (
+	shutdownCh   = make(chan struct{})
+	shutdownOnce sync.Once
+	// workers tracks goroutines that must finish before state is
+	// persisted: monitoring loops and in-flight notifications.
+	workers sync.WaitGroup
+)
+
+func shuttingDown() bool {
+	select {
+	case <-shutdownCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleepUnlessShutdown waits for d and reports false if shutdown started
+// in the meantime.
+func sleepUnlessShutdown(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-shutdownCh:
+		return false
+	}
+}
+
+// goWorker runs fn in a goroutine that shutdown waits for.
+func goWorker(fn func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		fn()
+	}()
+}
+
+// shutdown stops new commands and scans, waits for running workers to
+// finish and then persists all state, so nothing is written while it is
+// still changing.
+func shutdown() {
+	shutdownOnce.Do(func() {
+		close(shutdownCh)
+		stopAPIServer()
+
+		drained := make(chan struct{})
+		go func() {
+			workers.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-time.After(drainTimeout):
+			log.Printf("Workers still running after %s, persisting state anyway", drainTimeout)
+		}
+
+		saveChatConfigs()
+		saveChatBots()
+		saveBaselines()
+		saveSkippedSymbols()
+		saveAlertHistory()
+		saveEffectiveness()
+		saveKnownSymbols()
+		saveOutbox()
+	})
+}
+
+// watchShutdownSignals shuts down cleanly on SIGINT or SIGTERM.
+func watchShutdownSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("Received %s, shutting down", sig)
+		shutdown()
+		log.Println("Shutdown complete")
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestShutdownWithConcurrentSaves runs scan-like workers that keep changing
+// and saving state while shutdown runs. Run with -race; it also checks that
+// the final save includes every change the workers made.
+func TestShutdownWithConcurrentSaves(t *testing.T) {
+	alertHistoryMu.Lock()
+	saved := alertHistory
+	alertHistory = nil
+	alertHistoryMu.Unlock()
+	t.Cleanup(func() {
+		shutdownCh = make(chan struct{})
+		shutdownOnce = sync.Once{}
+		alertHistoryMu.Lock()
+		alertHistory = saved
+		alertHistoryMu.Unlock()
+		os.Remove(dataPath(alertHistoryFile))
+	})
+
+	var appended atomic.Int32
+	for i := range 4 {
+		chatID := int64(10001 + i)
+		t.Cleanup(func() { updateChatConfig(chatID, func(cfg *ChatConfig) { *cfg = defaultChatConfig() }) })
+		goWorker(func() {
+			for {
+				appendAlertHistory(chatID, "BTCUSDT", 5, time.Now())
+				appended.Add(1)
+				updateChatConfig(chatID, func(cfg *ChatConfig) { cfg.Threshold++ })
+				saveAlertHistory()
+				saveChatConfigs()
+				if !sleepUnlessShutdown(time.Millisecond) {
+					return
+				}
+			}
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		shutdown()
+		close(done)
+	}()
+	// Saves from outside the workers, like a command handler, race the
+	// shutdown too.
+	for range 5 {
+		saveAlertHistory()
+		saveOutbox()
+	}
+	select {
+	case <-done:
+	case <-time.After(drainTimeout):
+		t.Fatal("shutdown did not finish")
+	}
+
+	data, err := os.ReadFile(dataPath(alertHistoryFile))
+	if err != nil {
+		t.Fatalf("alert history not saved: %v", err)
+	}
+	var entries []historyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("saved alert history is not valid JSON: %v", err)
+	}
+	if len(entries) != int(appended.Load()) {
+		t.Errorf("saved %d history entries, workers appended %d", len(entries), appended.Load())
+	}
+}
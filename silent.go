@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// alertIsSilent reports whether an alert at ratio should be delivered
+// without a notification sound. Silent chats are still pinged for extreme
+// spikes of at least SilentOverrideMultiple times their threshold.
+func alertIsSilent(cfg ChatConfig, ratio float64) bool {
+	if !cfg.Silent {
+		return false
+	}
+	return ratio < cfg.Threshold*currentSettings().SilentOverrideMultiple
+}
+
+func handleSilentCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Silent = true
+		})
+		return fmt.Sprintf("Alerts will arrive silently. Spikes of %gx or more will still notify you.",
+			cfg.Threshold*currentSettings().SilentOverrideMultiple)
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Silent = false
+		})
+		return "Alerts will notify you again."
+	default:
+		state := "off"
+		if getChatConfig(chatID).Silent {
+			state = "on"
+		}
+		return fmt.Sprintf("Silent alerts are %s. Usage: /silent on|off", state)
+	}
+}
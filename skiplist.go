@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	skippedSymbolsFile = "skipped_symbols.json"
+
+	// A symbol that returns not-found is skipped for skipBaseInterval,
+	// doubling with each further miss up to skipMaxInterval.
+	skipBaseInterval = time.Hour
+	skipMaxInterval  = 7 * 24 * time.Hour
+)
+
+// skippedSymbol tracks a symbol Binance doesn't recognise, usually because
+// it was delisted or never listed against USDT.
+type skippedSymbol struct {
+	Misses    int       `json:"misses"`
+	NextCheck time.Time `json:"next_check"`
+}
+
+var (
+	skippedSymbols      = make(map[string]skippedSymbol)
+	skippedSymbolsMu    sync.Mutex
+	skippedSymbolsDirty bool
+)
+
+// skipInterval returns how long to wait before re-checking a symbol that
+// has been missing misses times in a row.
+func skipInterval(misses int) time.Duration {
+	interval := skipBaseInterval
+	for i := 1; i < misses && interval < skipMaxInterval; i++ {
+		interval *= 2
+	}
+	if interval > skipMaxInterval {
+		interval = skipMaxInterval
+	}
+	return interval
+}
+
+// shouldSkipSymbol reports whether scans should leave symbol out until its
+// next re-check.
+func shouldSkipSymbol(symbol string, now time.Time) bool {
+	skippedSymbolsMu.Lock()
+	defer skippedSymbolsMu.Unlock()
+
+	s, ok := skippedSymbols[symbol]
+	return ok && now.Before(s.NextCheck)
+}
+
+func noteSymbolNotFound(symbol string, now time.Time) {
+	skippedSymbolsMu.Lock()
+	defer skippedSymbolsMu.Unlock()
+
+	s := skippedSymbols[symbol]
+	s.Misses++
+	s.NextCheck = now.Add(skipInterval(s.Misses))
+	skippedSymbols[symbol] = s
+	skippedSymbolsDirty = true
+}
+
+// noteSymbolFound removes symbol from the skip list once Binance returns
+// data for it again.
+func noteSymbolFound(symbol string) {
+	skippedSymbolsMu.Lock()
+	defer skippedSymbolsMu.Unlock()
+
+	if _, ok := skippedSymbols[symbol]; ok {
+		delete(skippedSymbols, symbol)
+		skippedSymbolsDirty = true
+	}
+}
+
+// saveSkippedSymbols writes the skip list to disk if it changed since the
+// last save.
+func saveSkippedSymbols() {
+	skippedSymbolsMu.Lock()
+	if !skippedSymbolsDirty {
+		skippedSymbolsMu.Unlock()
+		return
+	}
+	data, err := json.Marshal(skippedSymbols)
+	skippedSymbolsDirty = false
+	skippedSymbolsMu.Unlock()
+	if err != nil {
+		log.Printf("Error marshaling skipped symbols: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(dataPath(skippedSymbolsFile), data, 0644); err != nil {
+		log.Printf("Error saving skipped symbols: %v", err)
+	}
+}
+
+func loadSkippedSymbols() {
+	data, err := os.ReadFile(dataPath(skippedSymbolsFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading skipped symbols file: %v", err)
+		}
+		return
+	}
+
+	loaded := make(map[string]skippedSymbol)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("Error unmarshaling skipped symbols: %v", err)
+		return
+	}
+
+	skippedSymbolsMu.Lock()
+	skippedSymbols = loaded
+	skippedSymbolsMu.Unlock()
+}
+
+func handleSkippedCommand() string {
+	skippedSymbolsMu.Lock()
+	symbols := make([]string, 0, len(skippedSymbols))
+	for symbol := range skippedSymbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var sb strings.Builder
+	for _, symbol := range symbols {
+		s := skippedSymbols[symbol]
+		fmt.Fprintf(&sb, "\n%s - missing %d times, next check %s", symbol, s.Misses, s.NextCheck.Format("2006-01-02 15:04"))
+	}
+	skippedSymbolsMu.Unlock()
+
+	if len(symbols) == 0 {
+		return "No symbols are being skipped."
+	}
+	return fmt.Sprintf("Skipping %d symbols Binance doesn't list:%s", len(symbols), sb.String())
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// snapshotRunning prevents overlapping snapshots.
+var snapshotRunning atomic.Bool
+
+// snapshotEntry is one symbol's volume data as written to a snapshot file.
+type snapshotEntry struct {
+	Symbol         string    `json:"symbol"`
+	PrevVolume     float64   `json:"prev_volume"`
+	CurrVolume     float64   `json:"curr_volume"`
+	Ratio          float64   `json:"ratio"`
+	OpenTime       time.Time `json:"open_time"`
+	QuoteVolume    float64   `json:"quote_volume"`
+	PriceChangePct float64   `json:"price_change_pct"`
+}
+
+type snapshotFile struct {
+	TakenAt  time.Time       `json:"taken_at"`
+	Interval string          `json:"interval"`
+	Metric   string          `json:"metric"`
+	Baseline string          `json:"baseline"`
+	Symbols  []snapshotEntry `json:"symbols"`
+}
+
+// writeSnapshot scans the chat's universe and writes every symbol's volume
+// data to a timestamped file in SnapshotDir, returning its path.
+func writeSnapshot(chatID int64, now time.Time) (string, error) {
+	cfg := getChatConfig(chatID)
+	symbols, err := getMonitoredSymbols(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to get symbols: %v", err)
+	}
+
+	results, _ := scanVolumes(chatID, cfg, symbols, func() bool { return true })
+
+	snapshot := snapshotFile{
+		TakenAt:  now,
+		Interval: klineInterval,
+		Metric:   metricLabel(cfg.Metric),
+		Baseline: "previous candle",
+	}
+	if cfg.MAType != "" {
+		snapshot.Baseline = maLabel(cfg)
+	}
+	for _, result := range results {
+		d := result.Data
+		snapshot.Symbols = append(snapshot.Symbols, snapshotEntry{
+			Symbol:         result.Symbol,
+			PrevVolume:     d.PrevVolume,
+			CurrVolume:     d.CurrVolume,
+			Ratio:          d.Ratio,
+			OpenTime:       d.OpenTime,
+			QuoteVolume:    d.QuoteVolume,
+			PriceChangePct: d.PriceChangePct,
+		})
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot: %v", err)
+	}
+
+	dir := dataPath(currentSettings().SnapshotDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("snapshot-%s.json", now.UTC().Format("20060102-150405")))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	return path, nil
+}
+
+// handleSnapshotCommand starts a snapshot in the background and reports
+// the file once it is written. Live monitoring is unaffected apart from
+// sharing the fetch limiter.
+func handleSnapshotCommand(chatID int64) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+	if !snapshotRunning.CompareAndSwap(false, true) {
+		return "A snapshot is already running."
+	}
+
+	go func() {
+		defer snapshotRunning.Store(false)
+
+		var text string
+		path, err := writeSnapshot(chatID, time.Now())
+		if err != nil {
+			log.Printf("Error writing snapshot: %v", err)
+			text = fmt.Sprintf("Snapshot failed: %v", err)
+		} else {
+			text = fmt.Sprintf("Snapshot written to %s", path)
+		}
+		send(botFor(chatID), tgbotapi.NewMessage(chatID, text))
+	}()
+	return "Snapshot started. This takes a few minutes."
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const sparklineCandles = 12
+
+// sparklineBlocks are the eight lower block elements, lowest first. They
+// share one advance width even in proportional fonts, so a sparkline lines
+// up in a plain-text alert without monospace formatting, which would mean
+// switching the whole message to a parse mode and escaping it.
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as block characters scaled between their
+// minimum and maximum. A flat series renders at mid height.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := len(sparklineBlocks) / 2
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparklineBlocks)-1))
+		}
+		sb.WriteRune(sparklineBlocks[level])
+	}
+	return sb.String()
+}
+
+// applySparkline sets the alert's sparkline of the last sparklineCandles
+// candles, ending with the one that alerted, when the chat enabled it. On
+// a failed fetch the alert is sent without it.
+func applySparkline(alert *Alert, cfg ChatConfig) {
+	if !cfg.Sparkline || alert.Sparkline != "" {
+		return
+	}
+	interval := alert.Interval
+	if interval == "" {
+		interval = klineInterval
+	}
+
+	closed := comparesClosed(cfg)
+	klines, err := getBinanceKlinesInterval(alert.Symbol, interval, candlesNeeded(sparklineCandles, closed))
+	if err != nil {
+		log.Printf("Error getting sparkline klines for %s: %v", alert.Symbol, err)
+		return
+	}
+	klines = comparedKlines(klines, closed)
+	values := make([]float64, 0, len(klines))
+	for _, kline := range klines {
+		if v, err := klineFloat(kline, metricField(alert.Metric)); err == nil {
+			values = append(values, v)
+		}
+	}
+	alert.Sparkline = sparkline(values)
+}
+
+func handleSparklineCommand(chatID int64, args string) string {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Sparkline = true
+		})
+		return fmt.Sprintf("Alerts will include a sparkline of the last %d candles' %s.", sparklineCandles, strings.ToLower(metricLabel(getChatConfig(chatID).Metric)))
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Sparkline = false
+		})
+		return "Sparklines removed from alerts."
+	default:
+		return "Usage: /sparkline on|off"
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]float64{5, 5, 5}, "▅▅▅"},
+		{[]float64{10, 0, 10}, "█▁█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestApplySparklineEndsOnComparedCandle(t *testing.T) {
+	// Twelve closed candles of rising volume, then an open candle that has
+	// barely started.
+	var klines []BinanceKline
+	for hour := 1; hour <= sparklineCandles; hour++ {
+		klines = append(klines, testKline(hour, 10, float64(hour*10)))
+	}
+	klines = append(klines, testKline(sparklineCandles+1, 10, 1))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		json.NewEncoder(w).Encode(klines[len(klines)-limit:])
+	}))
+	defer srv.Close()
+	oldURL, oldCache := binanceBaseURL, klineResponses
+	binanceBaseURL = srv.URL
+	t.Cleanup(func() { binanceBaseURL, klineResponses = oldURL, oldCache })
+
+	tests := []struct {
+		mode     string
+		wantLast rune
+	}{
+		{candleModeClosed, '█'},
+		{candleModeLive, '▁'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			klineResponses = newKlineCache(4, time.Minute)
+			alert := Alert{Symbol: "SPARKUSDT", Interval: "1h"}
+			applySparkline(&alert, ChatConfig{Sparkline: true, CandleMode: tt.mode})
+
+			blocks := []rune(alert.Sparkline)
+			if len(blocks) != sparklineCandles {
+				t.Fatalf("sparkline %q has %d candles, want %d", alert.Sparkline, len(blocks), sparklineCandles)
+			}
+			if last := blocks[len(blocks)-1]; last != tt.wantLast {
+				t.Errorf("sparkline %q ends on %q, want %q", alert.Sparkline, last, tt.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// staggerOffsets returns when each of n restored monitors starts: after
+// delay, spread evenly over spread. A single monitor starts after delay.
+func staggerOffsets(n int, delay, spread time.Duration) []time.Duration {
+	offsets := make([]time.Duration, n)
+	for i := range offsets {
+		offsets[i] = delay
+		if n > 1 {
+			offsets[i] += spread * time.Duration(i) / time.Duration(n)
+		}
+	}
+	return offsets
+}
+
+// restoreMonitors restarts the persisted monitors, staggered by
+// STARTUP_DELAY and STARTUP_STAGGER so they don't all hit the APIs at once
+// after a restart.
+func restoreMonitors(chatIDs []int64) {
+	if len(chatIDs) == 0 {
+		return
+	}
+	sort.Slice(chatIDs, func(i, j int) bool { return chatIDs[i] < chatIDs[j] })
+
+	s := currentSettings()
+	offsets := staggerOffsets(len(chatIDs), s.StartupDelay, s.StartupStagger)
+	if len(chatIDs) > 1 {
+		log.Printf("Restoring %d monitors, starting after %s and spread over %s (one every %s)",
+			len(chatIDs), s.StartupDelay, s.StartupStagger, s.StartupStagger/time.Duration(len(chatIDs)))
+	} else if s.StartupDelay > 0 {
+		log.Printf("Restoring 1 monitor after %s", s.StartupDelay)
+	}
+
+	for i, chatID := range chatIDs {
+		offset := offsets[i]
+		goWorker(func() {
+			if !sleepUnlessShutdown(offset) || !chatIsMonitoring(chatID) {
+				return
+			}
+			startMonitoring(chatID)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// formatStatus describes the chat's monitoring state and every effective
+// setting, grouped into sections.
+func formatStatus(chatID int64) string {
+	cfg := getChatConfig(chatID)
+
+	state := "stopped"
+	if monitoring, _ := monitoringStatus.Load(chatID); monitoring != nil && monitoring.(bool) {
+		state = "running"
+	}
+
+	lastScan := "never"
+	if value, ok := recentScans.Load(chatID); ok {
+		lastScan = value.(recentScan).CompletedAt.Format("2006-01-02 15:04:05")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Monitoring is currently %s\n", state)
+	fmt.Fprintf(&sb, "Last scan: %s\n", lastScan)
+	if value, ok := warmupScans.Load(chatID); ok && value.(int) > 0 && state == "running" {
+		fmt.Fprintf(&sb, "Warmup scans left: %d\n", value.(int))
+	}
+
+	sb.WriteString("\nDetection\n")
+	fmt.Fprintf(&sb, "Threshold: %gx\n", cfg.Threshold)
+	if factor := boostFactor(cfg, time.Now()); factor != 1 {
+		fmt.Fprintf(&sb, "Boost: %gx until %s\n", factor, time.Unix(cfg.BoostUntil, 0).Format("2006-01-02 15:04"))
+	}
+	if len(cfg.SymbolThresholds) > 0 {
+		fmt.Fprintf(&sb, "Symbol thresholds: %s\n", formatSymbolThresholds(cfg.SymbolThresholds))
+	}
+	fmt.Fprintf(&sb, "Adaptive threshold: %s\n", onOff(cfg.Adaptive))
+	if cfg.Daily && dailySupported(cfg.Metric) {
+		sb.WriteString("Interval: rolling 24h vs previous day\n")
+	} else {
+		fmt.Fprintf(&sb, "Interval: %s\n", klineInterval)
+	}
+	if cfg.AlignScans {
+		sb.WriteString("Scans: after each candle close\n")
+	} else {
+		fmt.Fprintf(&sb, "Scans: every %s\n", chatTier(cfg).ScanInterval)
+	}
+	if cfg.Tier != tierStandard {
+		fmt.Fprintf(&sb, "Tier: %s\n", tierName(cfg.Tier))
+	}
+	if comparesClosed(cfg) {
+		sb.WriteString("Candle mode: closed\n")
+	} else {
+		sb.WriteString("Candle mode: live\n")
+	}
+	for _, scan := range cfg.Intervals {
+		fmt.Fprintf(&sb, "Extra interval: %s\n", scan)
+	}
+	if len(cfg.Intervals) > 0 && cfg.MergeWindowSeconds > 0 {
+		fmt.Fprintf(&sb, "Merge window: %s\n", time.Duration(cfg.MergeWindowSeconds)*time.Second)
+	}
+	fmt.Fprintf(&sb, "Metric: %s\n", strings.ToLower(metricLabel(cfg.Metric)))
+	if cfg.MAType != "" {
+		fmt.Fprintf(&sb, "Baseline: %s\n", maLabel(cfg))
+	} else {
+		sb.WriteString("Baseline: previous candle\n")
+	}
+	if cfg.Condition != "" {
+		fmt.Fprintf(&sb, "Condition: %s\n", cfg.Condition)
+	}
+	fmt.Fprintf(&sb, "Market-relative: %s\n", onOff(cfg.MarketRelative))
+	if cfg.CandleColor != candleColorAny {
+		fmt.Fprintf(&sb, "Candle color: %s\n", cfg.CandleColor)
+	} else {
+		sb.WriteString("Candle color: any\n")
+	}
+	if cfg.WashTrading != washTradingOff {
+		maxRangePct, maxTradeShare := washParams(cfg)
+		fmt.Fprintf(&sb, "Wash trading: %s (range <= %g%%, trade share <= %g)\n", cfg.WashTrading, maxRangePct, maxTradeShare)
+	}
+	if cfg.Acceleration > 0 {
+		fmt.Fprintf(&sb, "Acceleration: %gx per candle\n", cfg.Acceleration)
+	} else {
+		sb.WriteString("Acceleration: off\n")
+	}
+	if cfg.BreakoutLookback > 0 {
+		fmt.Fprintf(&sb, "Breakouts: %d-candle range", cfg.BreakoutLookback)
+		if cfg.BreakoutVolume {
+			sb.WriteString(" with volume confirmation")
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("Breakouts: off\n")
+	}
+
+	if cfg.RangeSpike > 0 {
+		fmt.Fprintf(&sb, "Range spikes: above %gx the %d-candle average\n", cfg.RangeSpike, rangeSpikeLookback(cfg))
+	} else {
+		sb.WriteString("Range spikes: off\n")
+	}
+
+	if cfg.RVOL > 0 {
+		fmt.Fprintf(&sb, "RVOL: above %gx the %d-day time-of-day average\n", cfg.RVOL, rvolDays(cfg))
+	} else {
+		sb.WriteString("RVOL: off\n")
+	}
+
+	sb.WriteString("\nSymbols\n")
+	limit, _ := symbolLimit(cfg)
+	if len(cfg.Watchlist) > 0 {
+		fmt.Fprintf(&sb, "Universe: watchlist of %d symbols", len(cfg.Watchlist))
+		if len(cfg.Watchlist) > limit {
+			fmt.Fprintf(&sb, ", first %d scanned", limit)
+		}
+		sb.WriteString("\n")
+	} else if cfg.Category != "" {
+		fmt.Fprintf(&sb, "Universe: category %s by %s, at most %d\n", cfg.Category, rankLabel(cfg.RankBy), limit)
+	} else {
+		fmt.Fprintf(&sb, "Universe: top %d by %s\n", limit, rankLabel(cfg.RankBy))
+	}
+	sb.WriteString("Quote asset: USDT\n")
+	fmt.Fprintf(&sb, "New listings: %s\n", onOff(cfg.NewListings))
+	if len(cfg.MinVolume) > 0 {
+		fmt.Fprintf(&sb, "Min quote volume: %s\n", formatMinVolume(cfg.MinVolume, cfg.Precision))
+	}
+	if cfg.MessageThreadID != 0 {
+		fmt.Fprintf(&sb, "Alert topic: %d\n", cfg.MessageThreadID)
+	}
+	if cfg.DisplayCurrency != "" {
+		fmt.Fprintf(&sb, "Display currency: %s (approximate)\n", cfg.DisplayCurrency)
+	}
+	fmt.Fprintf(&sb, "Cross alerts: %d\n", len(cfg.CrossAlerts))
+	if len(cfg.Baskets) > 0 {
+		monitored := 0
+		for _, b := range cfg.Baskets {
+			if b.Monitored {
+				monitored++
+			}
+		}
+		fmt.Fprintf(&sb, "Baskets: %d (%d monitored)\n", len(cfg.Baskets), monitored)
+	}
+	fmt.Fprintf(&sb, "Priority symbols: %d\n", len(cfg.Priority))
+
+	sb.WriteString("\nDelivery\n")
+	if cfg.Dedup == dedupCandle {
+		sb.WriteString("Repeats: once per candle\n")
+	} else {
+		fmt.Fprintf(&sb, "Cooldown: %s", baseCooldown(cfg))
+		if cfg.ScaledCooldown {
+			sb.WriteString(", scaled by ratio")
+		}
+		sb.WriteString("\n")
+	}
+	if cfg.AlertPer == alertPerBase {
+		sb.WriteString("Alerts: once per base asset\n")
+	} else {
+		sb.WriteString("Alerts: per symbol\n")
+	}
+	fmt.Fprintf(&sb, "Silent: %s\n", onOff(cfg.Silent))
+	fmt.Fprintf(&sb, "Sparkline: %s\n", onOff(cfg.Sparkline))
+	fmt.Fprintf(&sb, "Severity: %s\n", formatSeverityBands(severityBands(cfg)))
+	digest := cfg.Digest
+	if digest == digestOff {
+		digest = "off"
+	}
+	fmt.Fprintf(&sb, "Digest: %s\n", digest)
+	fmt.Fprintf(&sb, "Dashboard: %s\n", onOff(cfg.Dashboard))
+	fmt.Fprintf(&sb, "Precision: %d\n", cfg.Precision)
+	if cfg.AutoPause > 0 {
+		fmt.Fprintf(&sb, "Auto-pause: after %d alerts within %s\n", cfg.AutoPause, currentSettings().AutoPauseWindow)
+	} else {
+		sb.WriteString("Auto-pause: off\n")
+	}
+	if cfg.DailySummary != "" {
+		fmt.Fprintf(&sb, "Daily summary: %s %s\n", cfg.DailySummary, chatLocation(cfg))
+	} else {
+		sb.WriteString("Daily summary: off\n")
+	}
+	fmt.Fprintf(&sb, "Webhook: %s\n", onOff(cfg.WebhookURL != ""))
+	if cfg.Email != "" {
+		fmt.Fprintf(&sb, "Email: %s\n", cfg.Email)
+	}
+	fmt.Fprintf(&sb, "Effectiveness tracking: %s\n", onOff(cfg.TrackEffectiveness))
+
+	return strings.TrimRight(sb.String(), "\n")
+}
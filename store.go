@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"sync"
+)
+
+// Store persists which chats are monitoring. Implementations must be safe
+// for concurrent use; the business logic only goes through this interface,
+// so another backend can replace the JSON file without touching it.
+type Store interface {
+	// LoadAll returns every stored chat's monitoring status.
+	LoadAll() (map[int64]bool, error)
+	// SaveChat stores one chat's monitoring status.
+	SaveChat(chatID int64, monitoring bool) error
+	// DeleteChat forgets a chat.
+	DeleteChat(chatID int64) error
+}
+
+// jsonStore keeps every status in one JSON object keyed by chat ID,
+// rewriting the file on each change. The file lives in DataDir.
+type jsonStore struct {
+	name string
+
+	mu    sync.Mutex
+	chats map[int64]bool
+}
+
+func newJSONStore(name string) *jsonStore {
+	return &jsonStore{name: name}
+}
+
+// load reads the file into s.chats unless it already has. A missing file
+// is an empty store, and so is a corrupt one after its error is returned,
+// letting the next save replace it. The caller holds s.mu.
+func (s *jsonStore) load() error {
+	if s.chats != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(dataPath(s.name))
+	if os.IsNotExist(err) {
+		s.chats = make(map[int64]bool)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	chats := make(map[int64]bool)
+	if err := json.Unmarshal(data, &chats); err != nil {
+		s.chats = make(map[int64]bool)
+		return err
+	}
+	s.chats = chats
+	return nil
+}
+
+// write saves s.chats to the file. The caller holds s.mu.
+func (s *jsonStore) write() error {
+	data, err := json.Marshal(s.chats)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dataPath(s.name), data, 0644)
+}
+
+func (s *jsonStore) LoadAll() (map[int64]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	chats := make(map[int64]bool, len(s.chats))
+	for chatID, monitoring := range s.chats {
+		chats[chatID] = monitoring
+	}
+	return chats, nil
+}
+
+func (s *jsonStore) SaveChat(chatID int64, monitoring bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.chats[chatID] = monitoring
+	return s.write()
+}
+
+func (s *jsonStore) DeleteChat(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	delete(s.chats, chatID)
+	return s.write()
+}
+
+// memoryStore keeps the statuses in memory only, for tests and for runs
+// that should not persist anything.
+type memoryStore struct {
+	mu    sync.Mutex
+	chats map[int64]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{chats: make(map[int64]bool)}
+}
+
+func (s *memoryStore) LoadAll() (map[int64]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.chats), nil
+}
+
+func (s *memoryStore) SaveChat(chatID int64, monitoring bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chatID] = monitoring
+	return nil
+}
+
+func (s *memoryStore) DeleteChat(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chats, chatID)
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testStore exercises the Store contract shared by every implementation.
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+
+	if chats, err := s.LoadAll(); err != nil || len(chats) != 0 {
+		t.Fatalf("LoadAll on an empty store = %v, %v, want an empty map", chats, err)
+	}
+
+	for chatID, monitoring := range map[int64]bool{1: true, 2: false, -1003: true} {
+		if err := s.SaveChat(chatID, monitoring); err != nil {
+			t.Fatalf("SaveChat(%d) returned error: %v", chatID, err)
+		}
+	}
+	if err := s.SaveChat(2, true); err != nil {
+		t.Fatalf("SaveChat(2) returned error: %v", err)
+	}
+	if err := s.DeleteChat(1); err != nil {
+		t.Fatalf("DeleteChat(1) returned error: %v", err)
+	}
+	if err := s.DeleteChat(42); err != nil {
+		t.Fatalf("DeleteChat of an unknown chat returned error: %v", err)
+	}
+
+	want := map[int64]bool{2: true, -1003: true}
+	chats, err := s.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll returned error: %v", err)
+	}
+	if !maps.Equal(chats, want) {
+		t.Errorf("LoadAll = %v, want %v", chats, want)
+	}
+
+	// The returned map is a copy.
+	chats[99] = true
+	if again, _ := s.LoadAll(); again[99] {
+		t.Error("LoadAll returned the store's own map")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, newMemoryStore())
+}
+
+func TestJSONStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), statusFile)
+	testStore(t, newJSONStore(path))
+
+	// A fresh store reads back what the first one wrote.
+	chats, err := newJSONStore(path).LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll from the file returned error: %v", err)
+	}
+	if want := map[int64]bool{2: true, -1003: true}; !maps.Equal(chats, want) {
+		t.Errorf("LoadAll from the file = %v, want %v", chats, want)
+	}
+}
+
+func TestJSONStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), statusFile)
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newJSONStore(path)
+	if _, err := s.LoadAll(); err == nil {
+		t.Fatal("LoadAll of a corrupt file returned no error")
+	}
+	if err := s.SaveChat(7, true); err != nil {
+		t.Fatalf("SaveChat after a corrupt load returned error: %v", err)
+	}
+	if chats, err := newJSONStore(path).LoadAll(); err != nil || !maps.Equal(chats, map[int64]bool{7: true}) {
+		t.Errorf("LoadAll after replacing the corrupt file = %v, %v", chats, err)
+	}
+}
+
+// useMemoryStore swaps statusStore for an in-memory one for the test.
+func useMemoryStore(t *testing.T) *memoryStore {
+	t.Helper()
+	s := newMemoryStore()
+	previous := statusStore
+	statusStore = s
+	t.Cleanup(func() { statusStore = previous })
+	return s
+}
+
+func TestMonitoringStatusGoesThroughStore(t *testing.T) {
+	s := useMemoryStore(t)
+	const chatID int64 = 5001
+	t.Cleanup(func() { monitoringStatus.Delete(chatID) })
+
+	monitoringStatus.Store(chatID, true)
+	saveMonitoringStatus(chatID)
+	if chats, _ := s.LoadAll(); !chats[chatID] {
+		t.Fatalf("saveMonitoringStatus did not store chat %d: %v", chatID, chats)
+	}
+
+	dropSubscription(chatID, errors.New("forbidden"))
+	if chats, _ := s.LoadAll(); len(chats) != 0 {
+		t.Errorf("dropSubscription left %v in the store", chats)
+	}
+	if chatIsMonitoring(chatID) {
+		t.Error("chat still monitoring after dropSubscription")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxSymbolThresholds = 100
+
+// symbolThreshold returns the chat's threshold for symbol: its override if
+// it has one, otherwise the chat's threshold, scaled by any active /boost.
+func symbolThreshold(cfg ChatConfig, symbol string) float64 {
+	threshold, ok := cfg.SymbolThresholds[symbol]
+	if !ok {
+		threshold = cfg.Threshold
+	}
+	return boostedThreshold(cfg, threshold, time.Now())
+}
+
+// formatSymbolThresholds lists the overrides sorted by symbol, e.g.
+// "PEPEUSDT 10x, WIFUSDT 8x".
+func formatSymbolThresholds(thresholds map[string]float64) string {
+	symbols := make([]string, 0, len(thresholds))
+	for symbol := range thresholds {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	parts := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		parts = append(parts, fmt.Sprintf("%s %gx", symbol, thresholds[symbol]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func handleSetSymbolThresholdCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return "Usage: /setsymbolthreshold <symbol> <threshold>, e.g. /setsymbolthreshold PEPEUSDT 10"
+	}
+
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+	threshold, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || threshold <= 1 {
+		return "Threshold must be a number greater than 1"
+	}
+
+	cfg := getChatConfig(chatID)
+	if _, ok := cfg.SymbolThresholds[symbol]; !ok && len(cfg.SymbolThresholds) >= maxSymbolThresholds {
+		return fmt.Sprintf("At most %d symbols can have their own threshold", maxSymbolThresholds)
+	}
+
+	cfg = updateChatConfig(chatID, func(cfg *ChatConfig) {
+		if cfg.SymbolThresholds == nil {
+			cfg.SymbolThresholds = make(map[string]float64)
+		}
+		cfg.SymbolThresholds[symbol] = threshold
+	})
+	return fmt.Sprintf("%s now alerts above %gx instead of the chat's %gx.", symbol, threshold, cfg.Threshold)
+}
+
+func handleClearSymbolThresholdCommand(chatID int64, args string) string {
+	arg := strings.TrimSpace(args)
+	if arg == "" {
+		return "Usage: /clearsymbolthreshold <symbol>, or /clearsymbolthreshold all"
+	}
+
+	if strings.EqualFold(arg, "all") {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.SymbolThresholds = nil
+		})
+		return "All symbol thresholds cleared."
+	}
+
+	symbol, err := resolveSymbol(chatID, arg)
+	if err != nil {
+		return err.Error()
+	}
+	if _, ok := getChatConfig(chatID).SymbolThresholds[symbol]; !ok {
+		return fmt.Sprintf("%s has no threshold of its own.", symbol)
+	}
+
+	cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+		delete(cfg.SymbolThresholds, symbol)
+	})
+	return fmt.Sprintf("%s uses the chat's %gx threshold again.", symbol, cfg.Threshold)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// testAlert is a made-up alert just above the chat's threshold.
+func testAlert(chatID int64, cfg ChatConfig) Alert {
+	now := time.Now()
+	data := &VolumeData{
+		PrevVolume: 1000,
+		CurrVolume: 1000 * cfg.Threshold * 1.1,
+		Ratio:      cfg.Threshold * 1.1,
+		OpenTime:   now.Truncate(intervalDuration(klineInterval)),
+	}
+	alert := newAlert(chatID, cfg, "TESTUSDT", data, false, now)
+	alert.Test = true
+	return alert
+}
+
+func handleTestNotifiersCommand(chatID int64) string {
+	cfg := getChatConfig(chatID)
+	alert := testAlert(chatID, cfg)
+	notifiers := append([]Notifier{newTelegramNotifier(cfg)}, chatNotifiers(cfg)...)
+
+	errs := make([]error, len(notifiers))
+	var wg sync.WaitGroup
+	for i, n := range notifiers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = n.Notify(alert)
+		}()
+	}
+	wg.Wait()
+
+	var sb strings.Builder
+	sb.WriteString("Notifier test:\n")
+	for i, n := range notifiers {
+		if errs[i] != nil {
+			fmt.Fprintf(&sb, "❌ %s: %v\n", n.Name(), errs[i])
+		} else {
+			fmt.Fprintf(&sb, "✅ %s\n", n.Name())
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendToThread sends msg into a forum topic. The Telegram library predates
+// topics, so with a thread set the sendMessage request is built here.
+func sendToThread(b *tgbotapi.BotAPI, msg tgbotapi.MessageConfig, threadID int) (tgbotapi.Message, error) {
+	if threadID == 0 {
+		return send(b, msg)
+	}
+
+	params := make(tgbotapi.Params)
+	params.AddNonZero64("chat_id", telegramChatID(msg.ChatID))
+	params.AddNonZero("message_thread_id", threadID)
+	params["text"] = msg.Text
+	params.AddNonEmpty("parse_mode", msg.ParseMode)
+	params.AddBool("disable_web_page_preview", msg.DisableWebPagePreview)
+	params.AddBool("disable_notification", msg.DisableNotification)
+	if err := params.AddInterface("reply_markup", msg.ReplyMarkup); err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	resp, err := b.MakeRequest("sendMessage", params)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	var message tgbotapi.Message
+	err = json.Unmarshal(resp.Result, &message)
+	return message, err
+}
+
+func handleThreadCommand(chatID int64, args string) string {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	switch arg {
+	case "":
+		if threadID := getChatConfig(chatID).MessageThreadID; threadID != 0 {
+			return fmt.Sprintf("Alerts are posted to topic %d. Use /thread off to post them in the general chat.", threadID)
+		}
+		return "Alerts are posted in the general chat. Usage: /thread <topic_id>, or /thread off"
+	case "off":
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.MessageThreadID = 0
+		})
+		return "Alerts will be posted in the general chat."
+	}
+
+	threadID, err := strconv.Atoi(arg)
+	if err != nil || threadID <= 0 {
+		return "Topic ID must be a positive number"
+	}
+
+	// Telegram has no lookup for topics, so check the topic by posting to it.
+	msg := tgbotapi.NewMessage(chatID, "Volume alerts will be posted in this topic.")
+	if _, err := sendToThread(botFor(chatID), msg, threadID); err != nil {
+		return fmt.Sprintf("Could not post to topic %d: %v", threadID, err)
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.MessageThreadID = threadID
+	})
+	return fmt.Sprintf("Alerts will be posted to topic %d.", threadID)
+}
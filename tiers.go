@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tierStandard = ""
+	tierFree     = "free"
+	tierPremium  = "premium"
+
+	// maxTierSymbols is the largest universe of any tier, and so how many
+	// coins are requested from CoinGecko.
+	maxTierSymbols = 250
+)
+
+// tier is a subscription level: how often a chat is scanned and how many
+// of the top coins it covers.
+type tier struct {
+	ScanInterval time.Duration
+	MaxSymbols   int
+}
+
+// tiers are assigned per chat by the admin. Chats without a tier are
+// standard, which is how every chat is scanned when tiers are unused.
+var tiers = map[string]tier{
+	tierStandard: {ScanInterval: scanPeriod, MaxSymbols: 100},
+	tierFree:     {ScanInterval: scanPeriod, MaxSymbols: 50},
+	tierPremium:  {ScanInterval: time.Minute, MaxSymbols: maxTierSymbols},
+}
+
+func chatTier(cfg ChatConfig) tier {
+	if t, ok := tiers[cfg.Tier]; ok {
+		return t
+	}
+	return tiers[tierStandard]
+}
+
+func tierName(name string) string {
+	if name == tierStandard {
+		return "standard"
+	}
+	return name
+}
+
+// symbolLimit returns how many symbols a chat may scan: its tier's
+// universe, lowered to MAX_SYMBOLS when that is set and smaller. capped
+// reports whether MAX_SYMBOLS is what limits it.
+func symbolLimit(cfg ChatConfig) (limit int, capped bool) {
+	limit = chatTier(cfg).MaxSymbols
+	if maxSymbols := currentSettings().MaxSymbols; maxSymbols > 0 && maxSymbols < limit {
+		return maxSymbols, true
+	}
+	return limit, false
+}
+
+// limitSymbols returns the first n symbols.
+func limitSymbols(symbols []string, n int) []string {
+	if len(symbols) > n {
+		return symbols[:n]
+	}
+	return symbols
+}
+
+func handleTierCommand(chatID int64, args string) string {
+	if !isAdmin(chatID) {
+		return "This command is restricted to the bot admin."
+	}
+
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 || len(fields) > 2 {
+		return "Usage: /tier <chat_id> [free|standard|premium]"
+	}
+	target, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return "Chat ID must be a number"
+	}
+
+	if len(fields) == 1 {
+		cfg := getChatConfig(target)
+		t := chatTier(cfg)
+		return fmt.Sprintf("Chat %d is on the %s tier: scanned every %s, top %d coins.", target, tierName(cfg.Tier), t.ScanInterval, t.MaxSymbols)
+	}
+
+	name := fields[1]
+	if name == "standard" {
+		name = tierStandard
+	}
+	t, ok := tiers[name]
+	if !ok {
+		return "Tier must be free, standard or premium"
+	}
+
+	updateChatConfig(target, func(cfg *ChatConfig) {
+		cfg.Tier = name
+	})
+	return fmt.Sprintf("Chat %d is now on the %s tier: scanned every %s, top %d coins.", target, tierName(name), t.ScanInterval, t.MaxSymbols)
+}
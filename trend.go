@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// lastRatio is a symbol's ratio as of a chat's previous scan.
+type lastRatio struct {
+	Ratio     float64
+	ScannedAt time.Time
+}
+
+// lastScans holds when each chat's previous scan completed, which tells
+// ratios from that scan apart from older ones of symbols it skipped.
+var (
+	lastRatios   = make(map[alertKey]lastRatio)
+	lastScans    = make(map[int64]time.Time)
+	lastRatiosMu sync.Mutex
+)
+
+// previousRatio returns the symbol's ratio from the chat's previous scan,
+// reporting false if that scan didn't include the symbol.
+func previousRatio(chatID int64, symbol string) (float64, bool) {
+	lastRatiosMu.Lock()
+	defer lastRatiosMu.Unlock()
+
+	last, ok := lastRatios[alertKey{chatID, symbol}]
+	if !ok || !last.ScannedAt.Equal(lastScans[chatID]) {
+		return 0, false
+	}
+	return last.Ratio, true
+}
+
+// storeLastRatios remembers every ratio of a completed scan for the next
+// one to compare against.
+func storeLastRatios(chatID int64, results []symbolVolume, now time.Time) {
+	lastRatiosMu.Lock()
+	defer lastRatiosMu.Unlock()
+
+	for _, result := range results {
+		lastRatios[alertKey{chatID, result.Symbol}] = lastRatio{Ratio: result.Data.Ratio, ScannedAt: now}
+	}
+	lastScans[chatID] = now
+}
+
+// formatTrend describes how the ratio moved since the previous scan, e.g.
+// "↑ from 3.20x last cycle".
+func formatTrend(ratio, previous float64, precision int) string {
+	arrow := "→"
+	switch {
+	case ratio > previous:
+		arrow = "↑"
+	case ratio < previous:
+		arrow = "↓"
+	}
+	return fmt.Sprintf("%s from %s last cycle", arrow, formatRatio(previous, precision))
+}
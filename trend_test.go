@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviousRatioOnlyFromLastScan(t *testing.T) {
+	const chatID int64 = 6401
+	t.Cleanup(func() {
+		lastRatiosMu.Lock()
+		delete(lastRatios, alertKey{chatID, "AUSDT"})
+		delete(lastRatios, alertKey{chatID, "BUSDT"})
+		delete(lastScans, chatID)
+		lastRatiosMu.Unlock()
+	})
+	result := func(symbol string, ratio float64) symbolVolume {
+		return symbolVolume{Symbol: symbol, Data: &VolumeData{Ratio: ratio}}
+	}
+	first := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	storeLastRatios(chatID, []symbolVolume{result("AUSDT", 2), result("BUSDT", 3)}, first)
+	if ratio, ok := previousRatio(chatID, "BUSDT"); !ok || ratio != 3 {
+		t.Errorf("previousRatio(BUSDT) = %g, %v, want 3, true", ratio, ok)
+	}
+
+	// BUSDT drops out of the next scan, so its ratio is two scans old.
+	storeLastRatios(chatID, []symbolVolume{result("AUSDT", 4)}, first.Add(time.Hour))
+	if ratio, ok := previousRatio(chatID, "AUSDT"); !ok || ratio != 4 {
+		t.Errorf("previousRatio(AUSDT) = %g, %v, want 4, true", ratio, ok)
+	}
+	if ratio, ok := previousRatio(chatID, "BUSDT"); ok {
+		t.Errorf("previousRatio(BUSDT) = %g, true, want false for a symbol the last scan skipped", ratio)
+	}
+	if _, ok := previousRatio(chatID, "CUSDT"); ok {
+		t.Error("previousRatio of a never-scanned symbol = true")
+	}
+}
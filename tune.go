@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tuneCandles is one day of hourly candles plus the one before it, giving
+// 24 candle-over-candle ratios.
+const tuneCandles = 25
+
+var tuneThresholds = []float64{2, 3, 5, 10}
+
+// countThresholdHits replays candle-over-candle volume ratios and counts how
+// often each threshold would have been exceeded.
+func countThresholdHits(volumes []float64, thresholds []float64) []int {
+	hits := make([]int, len(thresholds))
+	for i := 1; i < len(volumes); i++ {
+		if volumes[i-1] == 0 {
+			continue
+		}
+		ratio := volumes[i] / volumes[i-1]
+		for j, threshold := range thresholds {
+			if ratio > threshold {
+				hits[j]++
+			}
+		}
+	}
+	return hits
+}
+
+func handleTuneCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) != 1 {
+		return "Usage: /tune <symbol>"
+	}
+	symbol, err := resolveSymbol(chatID, fields[0])
+	if err != nil {
+		return err.Error()
+	}
+
+	klines, err := getBinanceKlines(symbol, tuneCandles)
+	if errors.Is(err, ErrSymbolNotFound) {
+		return fmt.Sprintf("Symbol %s was not found on Binance", symbol)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not fetch %s: %v", symbol, err)
+	}
+
+	hits := countThresholdHits(klineVolumes(klines), tuneThresholds)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s over the last %d candles (%s):\n", symbol, len(klines)-1, klineInterval)
+	sb.WriteString("Threshold | Alerts\n")
+	for i, threshold := range tuneThresholds {
+		fmt.Fprintf(&sb, "%8gx | %d\n", threshold, hits[i])
+	}
+	fmt.Fprintf(&sb, "\nYour threshold is %gx. Change it with /threshold <x>.", getChatConfig(chatID).Threshold)
+	return sb.String()
+}
+
+func handleThresholdCommand(chatID int64, args string) string {
+	arg := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(args)), "x")
+	if arg == "" {
+		return fmt.Sprintf("Alert threshold is %gx. Usage: /threshold <x>", getChatConfig(chatID).Threshold)
+	}
+
+	threshold, err := strconv.ParseFloat(arg, 64)
+	if err != nil || threshold <= 1 {
+		return "Threshold must be a number greater than 1"
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Threshold = threshold
+	})
+	return fmt.Sprintf("Alert threshold set to %gx.", threshold)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCountThresholdHits(t *testing.T) {
+	tests := []struct {
+		name    string
+		volumes []float64
+		want    []int
+	}{
+		// ratios 3, 0.5, 10.5, 1
+		{"mixed", []float64{10, 30, 15, 157.5, 157.5}, []int{2, 1, 1, 1}},
+		// ratios 2, 3, 5: one equal to a threshold does not exceed it
+		{"boundary", []float64{1, 2, 6, 30}, []int{2, 1, 0, 0}},
+		{"zero previous skipped", []float64{0, 100, 0, 500}, []int{0, 0, 0, 0}},
+		{"single candle", []float64{100}, []int{0, 0, 0, 0}},
+		{"none", nil, []int{0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countThresholdHits(tt.volumes, tuneThresholds); !slices.Equal(got, tt.want) {
+				t.Errorf("countThresholdHits(%v) = %v, want %v", tt.volumes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKlineVolumesFeedTune(t *testing.T) {
+	klines := []BinanceKline{testKline(1, 10, 100), testKline(2, 10, 600), testKline(3, 10, 600)}
+	if got, want := countThresholdHits(klineVolumes(klines), tuneThresholds), []int{1, 1, 1, 0}; !slices.Equal(got, want) {
+		t.Errorf("hits over klines = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	conflictModeRetry = "retry"
+	conflictModeExit  = "exit"
+
+	// conflictExitCode is used when exiting because another instance is
+	// polling with the same token, so supervisors can tell it apart.
+	conflictExitCode = 3
+
+	conflictInitialBackoff = 5 * time.Second
+	conflictMaxBackoff     = 5 * time.Minute
+
+	updatesWatchdogTick = 30 * time.Second
+)
+
+// updatesPoller is the state shared by the getUpdates loop and its
+// watchdog. Each restart bumps gen so a stuck loop that eventually returns
+// stops without delivering updates the new loop already fetched.
+type updatesPoller struct {
+	mu        sync.Mutex
+	offset    int
+	gen       int
+	lastPoll  time.Time
+	restarted bool
+}
+
+// current reports whether gen is still the active loop.
+func (p *updatesPoller) current(gen int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.gen == gen
+}
+
+// getUpdatesChan polls getUpdates like tgbotapi's GetUpdatesChan, but
+// recognises 409 Conflict responses instead of retrying them silently, and
+// restarts polling if getUpdates stops returning.
+func getUpdatesChan(b *tgbotapi.BotAPI, config tgbotapi.UpdateConfig) <-chan tgbotapi.Update {
+	ch := make(chan tgbotapi.Update, b.Buffer)
+	p := &updatesPoller{offset: config.Offset, lastPoll: time.Now()}
+
+	go pollUpdates(b, config, p, 0, ch)
+	go watchUpdates(b, config, p, ch)
+
+	return ch
+}
+
+func pollUpdates(b *tgbotapi.BotAPI, config tgbotapi.UpdateConfig, p *updatesPoller, gen int, ch chan<- tgbotapi.Update) {
+	backoff := conflictInitialBackoff
+	for !shuttingDown() && p.current(gen) {
+		p.mu.Lock()
+		config.Offset = p.offset
+		p.mu.Unlock()
+
+		updates, err := b.GetUpdates(config)
+		if err != nil {
+			if isConflictError(err) {
+				log.Printf("Telegram returned 409 Conflict: another instance is polling updates for @%s. "+
+					"Stop the duplicate instance (e.g. a previous deploy that is still running).", b.Self.UserName)
+				if currentSettings().UpdatesConflictMode == conflictModeExit {
+					log.Printf("Exiting because UPDATES_CONFLICT_MODE=%s", conflictModeExit)
+					os.Exit(conflictExitCode)
+				}
+				log.Printf("Retrying getUpdates in %s", backoff)
+				time.Sleep(backoff)
+				backoff *= 2
+				if backoff > conflictMaxBackoff {
+					backoff = conflictMaxBackoff
+				}
+				continue
+			}
+
+			log.Println(err)
+			log.Println("Failed to get updates, retrying in 3 seconds...")
+			time.Sleep(3 * time.Second)
+			continue
+		}
+
+		p.mu.Lock()
+		if p.gen != gen {
+			p.mu.Unlock()
+			return
+		}
+		p.lastPoll = time.Now()
+		if p.restarted {
+			log.Printf("Updates polling for @%s recovered", b.Self.UserName)
+			p.restarted = false
+		}
+		var fresh []tgbotapi.Update
+		for _, update := range updates {
+			if update.UpdateID >= p.offset {
+				p.offset = update.UpdateID + 1
+				fresh = append(fresh, update)
+			}
+		}
+		p.mu.Unlock()
+
+		backoff = conflictInitialBackoff
+		for _, update := range fresh {
+			ch <- update
+		}
+	}
+}
+
+// watchUpdates starts a new polling loop whenever no getUpdates call has
+// succeeded within UPDATES_WATCHDOG_TIMEOUT.
+func watchUpdates(b *tgbotapi.BotAPI, config tgbotapi.UpdateConfig, p *updatesPoller, ch chan<- tgbotapi.Update) {
+	for sleepUnlessShutdown(updatesWatchdogTick) {
+		timeout := currentSettings().UpdatesWatchdogTimeout
+		if timeout <= 0 {
+			continue
+		}
+
+		p.mu.Lock()
+		silent := time.Since(p.lastPoll)
+		if silent < timeout {
+			p.mu.Unlock()
+			continue
+		}
+		p.gen++
+		p.lastPoll = time.Now()
+		p.restarted = true
+		gen := p.gen
+		p.mu.Unlock()
+
+		log.Printf("No successful getUpdates for @%s in %s, restarting updates polling", b.Self.UserName, silent.Round(time.Second))
+		go pollUpdates(b, config, p, gen, ch)
+	}
+}
+
+func isConflictError(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.Code == http.StatusConflict
+}
@@ -0,0 +1,25 @@
+package main
+
+import "sync"
+
+// warmupScans holds the number of warmup scans each chat has left.
+var warmupScans sync.Map
+
+// inWarmup reports whether the chat's current scan is a warmup scan and, if
+// so, counts it down.
+func inWarmup(chatID int64) bool {
+	value, ok := warmupScans.Load(chatID)
+	if !ok || value.(int) <= 0 {
+		return false
+	}
+	warmupScans.Store(chatID, value.(int)-1)
+	return true
+}
+
+// warmingUp reports whether the chat still has warmup scans left without
+// counting one down. Only the main loop spends warmup scans; the interval
+// scanners wait for it to finish.
+func warmingUp(chatID int64) bool {
+	value, ok := warmupScans.Load(chatID)
+	return ok && value.(int) > 0
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestWarmingUpDoesNotSpendScans(t *testing.T) {
+	const chatID int64 = 6101
+	warmupScans.Store(chatID, 2)
+	t.Cleanup(func() { warmupScans.Delete(chatID) })
+
+	for i := 0; i < 3; i++ {
+		if !warmingUp(chatID) {
+			t.Fatalf("warmingUp = false on peek %d, want true", i)
+		}
+	}
+	if !inWarmup(chatID) || !inWarmup(chatID) {
+		t.Fatal("inWarmup = false during the two warmup scans")
+	}
+	if inWarmup(chatID) || warmingUp(chatID) {
+		t.Error("chat still warming up after its warmup scans were spent")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	washTradingOff      = ""
+	washTradingFlag     = "flag"
+	washTradingSuppress = "suppress"
+
+	defaultWashMaxRangePct   = 0.5
+	defaultWashMaxTradeShare = 0.25
+)
+
+// washStats describes the latest candle against the one before it.
+type washStats struct {
+	VolumeRatio float64
+	TradeRatio  float64
+	RangePct    float64
+}
+
+// candleWashStats compares curr with prev: how much volume and trade count
+// grew, and curr's high-low range as a percentage of its low.
+func candleWashStats(prev, curr BinanceKline) (washStats, error) {
+	var values [6]float64
+	for i, f := range []struct {
+		kline BinanceKline
+		field int
+	}{{prev, 5}, {curr, 5}, {prev, 8}, {curr, 8}, {curr, 2}, {curr, 3}} {
+		v, err := klineFloat(f.kline, f.field)
+		if err != nil {
+			return washStats{}, err
+		}
+		values[i] = v
+	}
+	prevVolume, currVolume, prevTrades, currTrades, high, low := values[0], values[1], values[2], values[3], values[4], values[5]
+	if prevVolume == 0 || prevTrades == 0 || low == 0 {
+		return washStats{}, fmt.Errorf("empty previous candle")
+	}
+
+	return washStats{
+		VolumeRatio: currVolume / prevVolume,
+		TradeRatio:  currTrades / prevTrades,
+		RangePct:    (high - low) / low * 100,
+	}, nil
+}
+
+// looksLikeWashTrading is the wash-trading heuristic: the candle's volume
+// grew but its price barely moved (range at most maxRangePct percent) and
+// its trade count grew by at most maxTradeShare of the volume growth. Real
+// interest brings in more traders and moves price; volume traded back and
+// forth between a few accounts does neither.
+func looksLikeWashTrading(s washStats, maxRangePct, maxTradeShare float64) bool {
+	return s.VolumeRatio > 1 && s.RangePct <= maxRangePct && s.TradeRatio <= s.VolumeRatio*maxTradeShare
+}
+
+// washParams returns the chat's heuristic parameters, falling back to the
+// defaults for unset ones.
+func washParams(cfg ChatConfig) (maxRangePct, maxTradeShare float64) {
+	maxRangePct, maxTradeShare = cfg.WashMaxRangePct, cfg.WashMaxTradeShare
+	if maxRangePct == 0 {
+		maxRangePct = defaultWashMaxRangePct
+	}
+	if maxTradeShare == 0 {
+		maxTradeShare = defaultWashMaxTradeShare
+	}
+	return maxRangePct, maxTradeShare
+}
+
+// suspectWashTrading applies the heuristic to the two candles the chat
+// compares. Fetch errors are logged and treated as not suspect.
+func suspectWashTrading(cfg ChatConfig, symbol string) bool {
+	klines, err := getBinanceKlines(symbol, candlesNeeded(2, comparesClosed(cfg)))
+	if err == nil && len(klines) < candlesNeeded(2, comparesClosed(cfg)) {
+		err = fmt.Errorf("insufficient kline data")
+	}
+	var stats washStats
+	if err == nil {
+		stats, err = candleWashStats(klines[0], klines[1])
+	}
+	if err != nil {
+		log.Printf("Error checking %s for wash trading: %v", symbol, err)
+		return false
+	}
+
+	maxRangePct, maxTradeShare := washParams(cfg)
+	return looksLikeWashTrading(stats, maxRangePct, maxTradeShare)
+}
+
+func handleWashTradingCommand(chatID int64, args string) string {
+	fields := strings.Fields(strings.ToLower(args))
+	usage := "Usage: /washtrading flag|suppress [max_range_pct] [max_trade_share], or /washtrading off"
+	if len(fields) == 0 || len(fields) > 3 {
+		return usage
+	}
+
+	mode := fields[0]
+	switch mode {
+	case "off":
+		if len(fields) > 1 {
+			return usage
+		}
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.WashTrading = washTradingOff
+		})
+		return "Wash-trading detection disabled."
+	case washTradingFlag, washTradingSuppress:
+	default:
+		return usage
+	}
+
+	maxRangePct, maxTradeShare := defaultWashMaxRangePct, defaultWashMaxTradeShare
+	if len(fields) > 1 {
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || v <= 0 {
+			return "max_range_pct must be a positive percentage, e.g. 0.5"
+		}
+		maxRangePct = v
+	}
+	if len(fields) > 2 {
+		v, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || v <= 0 || v > 1 {
+			return "max_trade_share must be between 0 and 1, e.g. 0.25"
+		}
+		maxTradeShare = v
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.WashTrading = mode
+		cfg.WashMaxRangePct = maxRangePct
+		cfg.WashMaxTradeShare = maxTradeShare
+	})
+	action := "flagged"
+	if mode == washTradingSuppress {
+		action = "suppressed"
+	}
+	return fmt.Sprintf("Alerts will be %s as possible wash trading when the candle's range is at most %g%% and its trade count grew by at most %g of its volume growth.",
+		action, maxRangePct, maxTradeShare)
+}
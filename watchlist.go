@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	maxWatchlistSymbols  = maxTierSymbols
+	maxWatchlistFileSize = 64 * 1024
+	maxRejectedShown     = 10
+)
+
+// parseSymbolList splits a text or CSV symbol list into upper-case tokens
+// in order of first appearance. Any run of characters other than letters
+// and digits separates tokens, and lines starting with # are comments.
+func parseSymbolList(text string) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		tokens := strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, token := range tokens {
+			token = strings.ToUpper(token)
+			if !seen[token] {
+				seen[token] = true
+				symbols = append(symbols, token)
+			}
+		}
+	}
+	return symbols
+}
+
+// validateWatchlist resolves each token to a trading Binance symbol,
+// expanding bare base assets with quote. Tokens that do not resolve, and
+// those past maxWatchlistSymbols, are rejected.
+func validateWatchlist(tokens []string, symbols map[string]SymbolInfo, quote string) (accepted, rejected []string) {
+	seen := make(map[string]bool)
+	for _, token := range tokens {
+		symbol := token
+		if info, ok := symbols[symbol]; !ok || info.Status != "TRADING" {
+			symbol = token + quote
+		}
+		info, ok := symbols[symbol]
+		if !ok || info.Status != "TRADING" || len(accepted) >= maxWatchlistSymbols {
+			rejected = append(rejected, token)
+			continue
+		}
+		if !seen[symbol] {
+			seen[symbol] = true
+			accepted = append(accepted, symbol)
+		}
+	}
+	return accepted, rejected
+}
+
+// setWatchlist validates a symbol list and, if any symbol is accepted,
+// makes it the chat's universe. It reports what was accepted and rejected.
+func setWatchlist(chatID int64, text string) string {
+	tokens := parseSymbolList(text)
+	if len(tokens) == 0 {
+		return "No symbols found. List one symbol per line or separate them with commas."
+	}
+
+	symbols, err := getExchangeInfo()
+	if err != nil {
+		return fmt.Sprintf("Could not validate symbols: %v", err)
+	}
+	quote := getChatConfig(chatID).DefaultQuote
+	if quote == "" {
+		quote = defaultQuoteAsset
+	}
+
+	accepted, rejected := validateWatchlist(tokens, symbols, quote)
+	if len(accepted) == 0 {
+		return fmt.Sprintf("Watchlist not changed: none of the %d symbols trade on Binance.", len(tokens))
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.Watchlist = accepted
+	})
+
+	result := fmt.Sprintf("Watchlist set: %d accepted, %d rejected.", len(accepted), len(rejected))
+	if len(rejected) > 0 {
+		shown := rejected[:min(len(rejected), maxRejectedShown)]
+		result += "\nRejected: " + strings.Join(shown, ", ")
+		if len(rejected) > len(shown) {
+			result += fmt.Sprintf(" and %d more", len(rejected)-len(shown))
+		}
+	}
+	if limit, capped := symbolLimit(getChatConfig(chatID)); len(accepted) > limit {
+		reason := "on this chat's tier"
+		if capped {
+			reason = "by this bot's symbol limit"
+		}
+		result += fmt.Sprintf("\nOnly the first %d are scanned, as %d is the most allowed %s.", limit, limit, reason)
+	}
+	return result
+}
+
+// isSymbolListDocument reports whether a document looks like a text or CSV
+// symbol list.
+func isSymbolListDocument(doc *tgbotapi.Document) bool {
+	if doc == nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(doc.FileName)) {
+	case ".txt", ".csv":
+		return true
+	}
+	return strings.HasPrefix(doc.MimeType, "text/")
+}
+
+// isWatchlistCaption reports whether a symbol file was sent with the
+// /watchlist command as its caption. Other files posted in the chat are
+// left alone, so a log that happens to mention BTC doesn't replace the
+// watchlist.
+func isWatchlistCaption(message *tgbotapi.Message) bool {
+	if !isSymbolListDocument(message.Document) {
+		return false
+	}
+	command := strings.Fields(message.Caption)
+	return len(command) > 0 && strings.Split(command[0], "@")[0] == "/watchlist"
+}
+
+// watchlistReplyDocument returns the symbol file a /watchlist command
+// replies to, or nil.
+func watchlistReplyDocument(message *tgbotapi.Message) *tgbotapi.Document {
+	if message.ReplyToMessage == nil || !isSymbolListDocument(message.ReplyToMessage.Document) {
+		return nil
+	}
+	return message.ReplyToMessage.Document
+}
+
+// handleWatchlistDocument sets the watchlist from an uploaded symbol file.
+func handleWatchlistDocument(chatID int64, doc *tgbotapi.Document) string {
+	data, err := downloadDocument(chatID, doc, maxWatchlistFileSize)
+	if errors.Is(err, errFileTooLarge) {
+		return fmt.Sprintf("Symbol file is too large, the limit is %d KB.", maxWatchlistFileSize/1024)
+	}
+	if err != nil {
+		return fmt.Sprintf("Could not download the file: %v", err)
+	}
+	return setWatchlist(chatID, string(data))
+}
+
+func handleWatchlistCommand(chatID int64, args string) string {
+	arg := strings.TrimSpace(args)
+	switch strings.ToLower(arg) {
+	case "":
+		watchlist := getChatConfig(chatID).Watchlist
+		if len(watchlist) == 0 {
+			return "No watchlist set. Send a .txt or .csv file of symbols with the caption /watchlist, or use /watchlist <symbols>."
+		}
+		return fmt.Sprintf("Watchlist (%d): %s", len(watchlist), strings.Join(watchlist, ", "))
+	case "clear", "off":
+		cfg := updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.Watchlist = nil
+		})
+		return fmt.Sprintf("Watchlist cleared. Monitoring the top coins by %s.", rankLabel(cfg.RankBy))
+	}
+	return setWatchlist(chatID, arg)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestIsWatchlistCaption(t *testing.T) {
+	symbols := &tgbotapi.Document{FileName: "coins.txt"}
+	tests := []struct {
+		name    string
+		message tgbotapi.Message
+		want    bool
+	}{
+		{"captioned", tgbotapi.Message{Document: symbols, Caption: "/watchlist"}, true},
+		{"captioned with bot name", tgbotapi.Message{Document: symbols, Caption: "/watchlist@volume_bot"}, true},
+		{"csv by MIME type", tgbotapi.Message{Document: &tgbotapi.Document{FileName: "export", MimeType: "text/csv"}, Caption: "/watchlist"}, true},
+		{"no caption", tgbotapi.Message{Document: symbols}, false},
+		{"other caption", tgbotapi.Message{Document: symbols, Caption: "today's log, BTC looks busy"}, false},
+		{"other command", tgbotapi.Message{Document: symbols, Caption: "/importconfig"}, false},
+		{"not a symbol file", tgbotapi.Message{Document: &tgbotapi.Document{FileName: "chart.png", MimeType: "image/png"}, Caption: "/watchlist"}, false},
+		{"no document", tgbotapi.Message{Caption: "/watchlist"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWatchlistCaption(&tt.message); got != tt.want {
+				t.Errorf("isWatchlistCaption = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchlistReplyDocument(t *testing.T) {
+	symbols := &tgbotapi.Document{FileName: "coins.csv"}
+	if got := watchlistReplyDocument(&tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{Document: symbols}}); got != symbols {
+		t.Errorf("reply to a symbol file = %v, want the file", got)
+	}
+	if got := watchlistReplyDocument(&tgbotapi.Message{ReplyToMessage: &tgbotapi.Message{Text: "BTC"}}); got != nil {
+		t.Errorf("reply to a text message = %v, want nil", got)
+	}
+	if got := watchlistReplyDocument(&tgbotapi.Message{}); got != nil {
+		t.Errorf("no reply = %v, want nil", got)
+	}
+}
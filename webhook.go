@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	webhookAttempts       = 3
+	webhookInitialBackoff = time.Second
+	webhookLookupTimeout  = 5 * time.Second
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second, Transport: newWebhookTransport(nil)}
+
+var errPrivateAddress = errors.New("not a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, private in all but
+// name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddress reports whether ip is a globally routable unicast address,
+// as opposed to a loopback, private, link-local or otherwise internal one.
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// refusePrivateAddress is a dialer Control that stops connections to
+// non-public addresses. It runs after name resolution, so a host that
+// resolves to a public address when /webhook checks it and to an internal
+// one later is still refused.
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// newWebhookTransport is newTransport with a dialer that refuses
+// non-public addresses, since webhook URLs come from chat users and would
+// otherwise let them probe the host's network through /testnotifiers. The
+// proxy, if one is configured, is the operator's and may be local.
+func newWebhookTransport(proxy *url.URL) *http.Transport {
+	transport := newTransport(proxy)
+
+	trusted := make(map[string]bool)
+	for _, scheme := range []string{"http", "https"} {
+		p, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: "webhook.invalid"}})
+		if err == nil && p != nil {
+			trusted[proxyAddress(p)] = true
+		}
+	}
+
+	direct := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: refusePrivateAddress}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if trusted[address] {
+			return direct.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+	return transport
+}
+
+// proxyAddress is the host:port a transport dials to reach proxy.
+func proxyAddress(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443", "socks5": "1080"}[proxy.Scheme]
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// checkWebhookHost resolves host and fails unless every address it has is
+// public.
+func checkWebhookHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s", errPrivateAddress, addr)
+		}
+	}
+	return nil
+}
+
+// webhookPayload is the JSON body POSTed to webhook endpoints.
+type webhookPayload struct {
+	ChatID     int64   `json:"chat_id"`
+	Symbol     string  `json:"symbol"`
+	Direction  string  `json:"direction"`
+	Ratio      float64 `json:"ratio"`
+	Threshold  float64 `json:"threshold"`
+	PrevVolume float64 `json:"prev_volume"`
+	CurrVolume float64 `json:"curr_volume"`
+	// Price is the current candle's typical price, (high + low + close) / 3,
+	// and PriceChangePct its open-to-close change in percent.
+	Price          float64   `json:"price"`
+	PriceChangePct float64   `json:"price_change_pct"`
+	Timestamp      time.Time `json:"timestamp"`
+	Test           bool      `json:"test,omitempty"`
+}
+
+// webhookNotifier POSTs alerts as JSON. When a secret is set the body is
+// signed with HMAC-SHA256 and the hex digest sent in X-Signature-256.
+type webhookNotifier struct {
+	url    string
+	secret string
+}
+
+func newWebhookNotifier(url, secret string) *webhookNotifier {
+	return &webhookNotifier{url: url, secret: secret}
+}
+
+func (w *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (w *webhookNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(webhookPayload{
+		ChatID:         telegramChatID(alert.ChatID),
+		Symbol:         alert.Symbol,
+		Direction:      alert.Direction,
+		Ratio:          alert.Data.Ratio,
+		Threshold:      alert.Threshold,
+		PrevVolume:     alert.Data.PrevVolume,
+		CurrVolume:     alert.Data.CurrVolume,
+		Price:          alert.Data.TypicalPrice,
+		PriceChangePct: alert.Data.PriceChangePct,
+		Timestamp:      alert.Time.UTC(),
+		Test:           alert.Test,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %v", err)
+	}
+
+	backoff := webhookInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *webhookNotifier) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func handleWebhookCommand(chatID int64, args string) string {
+	fields := strings.Fields(args)
+
+	if len(fields) == 0 {
+		if cfg := getChatConfig(chatID); cfg.WebhookURL != "" {
+			return fmt.Sprintf("Alerts are also sent to %s. Use /webhook off to disable.", cfg.WebhookURL)
+		}
+		return "Usage: /webhook <url> [hmac_secret], or /webhook off"
+	}
+
+	if len(fields) == 1 && strings.ToLower(fields[0]) == "off" {
+		updateChatConfig(chatID, func(cfg *ChatConfig) {
+			cfg.WebhookURL = ""
+			cfg.WebhookSecret = ""
+		})
+		return "Webhook delivery disabled."
+	}
+
+	if len(fields) > 2 {
+		return "Usage: /webhook <url> [hmac_secret], or /webhook off"
+	}
+
+	u, err := url.Parse(fields[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "Webhook URL must be an absolute http(s) URL"
+	}
+	if err := checkWebhookHost(u.Hostname()); errors.Is(err, errPrivateAddress) {
+		return "Webhook URL must point to a public address"
+	} else if err != nil {
+		return fmt.Sprintf("Could not resolve %s: %v", u.Hostname(), err)
+	}
+
+	secret := ""
+	if len(fields) == 2 {
+		secret = fields[1]
+	}
+
+	updateChatConfig(chatID, func(cfg *ChatConfig) {
+		cfg.WebhookURL = u.String()
+		cfg.WebhookSecret = secret
+	})
+	if secret != "" {
+		return fmt.Sprintf("Alerts will be POSTed to %s, signed with your secret.", u.String())
+	}
+	return fmt.Sprintf("Alerts will be POSTed to %s.", u.String())
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestWebhookPayload(t *testing.T) {
+	var body []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Signature-256")
+	}))
+	defer srv.Close()
+	// The test server listens on loopback, which the webhook client refuses.
+	previous := webhookClient
+	webhookClient = srv.Client()
+	t.Cleanup(func() { webhookClient = previous })
+
+	alert := Alert{
+		ChatID:    1,
+		Symbol:    "BTCUSDT",
+		Threshold: 5,
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data: &VolumeData{
+			PrevVolume:     100,
+			CurrVolume:     800,
+			Ratio:          8,
+			TypicalPrice:   65000.5,
+			PriceChangePct: -1.25,
+		},
+	}
+	if err := newWebhookNotifier(srv.URL, "secret").Notify(alert); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"symbol":           "BTCUSDT",
+		"ratio":            8.0,
+		"price":            65000.5,
+		"price_change_pct": -1.25,
+		"timestamp":        "2026-01-02T03:04:05Z",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("payload %s = %v, want %v", key, got[key], value)
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("X-Signature-256 = %q, want %q", signature, want)
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer srv.Close()
+
+	client := &http.Client{Transport: newWebhookTransport(nil)}
+	_, err := client.Post(srv.URL, "application/json", nil)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("POST to %s error = %v, want %v", srv.URL, err, errPrivateAddress)
+	}
+	if hits != 0 {
+		t.Errorf("loopback server got %d requests, want 0", hits)
+	}
+}
+
+func TestWebhookCommandRefusesPrivateURLs(t *testing.T) {
+	const chatID int64 = 6501
+	t.Cleanup(func() {
+		chatConfigsMu.Lock()
+		delete(chatConfigs, chatID)
+		chatConfigsMu.Unlock()
+	})
+
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"https://10.0.0.5/hook",
+		"http://localhost/hook",
+	} {
+		reply := handleWebhookCommand(chatID, target)
+		if got := getChatConfig(chatID).WebhookURL; got != "" {
+			t.Errorf("/webhook %s saved %q (reply %q), want it refused", target, got, reply)
+		}
+	}
+}